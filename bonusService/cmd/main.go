@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"flight_booking_system/bonusService/cmd/server"
 	privilegeDel "flight_booking_system/bonusService/internal/privilege/delivery"
 	pgPrivilege "flight_booking_system/bonusService/internal/privilege/repository/postgres"
 	privilegeUseCase "flight_booking_system/bonusService/internal/privilege/usecase"
+	"flight_booking_system/bonusService/pkg/config"
+	"flight_booking_system/bonusService/pkg/health"
 	"flight_booking_system/bonusService/pkg/middleware"
-	"fmt"
+	"flight_booking_system/bonusService/pkg/session"
 	"log"
 	"net/http"
+	"os"
 
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
@@ -16,13 +20,16 @@ import (
 	"gorm.io/gorm"
 )
 
-var prodCfgPg = postgres.Config{DSN: "host=postgres user=program password=test dbname=privileges port=5432"}
-
 func main() {
 	zapLogger := zap.Must(zap.NewDevelopment())
 	logger := zapLogger.Sugar()
 
-	db, err := gorm.Open(postgres.New(prodCfgPg), &gorm.Config{})
+	cfg, err := config.Load(os.Getenv("CONFIG_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: cfg.Postgres.DSN}), &gorm.Config{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -41,21 +48,41 @@ func main() {
 	r.Handle("PATCH /api/v1/privileges", http.HandlerFunc(privilegeHandler.Update))
 	r.Handle("DELETE /api/v1/privileges/{privilegeId}", http.HandlerFunc(privilegeHandler.Delete))
 	r.Handle("POST /api/v1/privileges/history", http.HandlerFunc(privilegeHandler.CreateHistory))
+	r.Handle("POST /api/v1/privileges/operations", http.HandlerFunc(privilegeHandler.ApplyOperation))
+	r.Handle("POST /api/v1/privileges/history/{ticketUid}/revert", http.HandlerFunc(privilegeHandler.RevertHistory))
 
-	r.Handle("GET /manage/health", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	}))
-
-	router := middleware.AccessLog(logger, r)
+	router := middleware.Identity(logger, session.New(cfg.Session.TokenKey), cfg.Session.RequireIdentity, r)
+	router = middleware.AccessLog(logger, router)
 	router = middleware.Panic(logger, router)
 
-	s := server.NewServer(router)
+	s := server.NewServer(cfg.Server, router)
+
+	checker := health.New(health.DefaultTimeout).
+		Add("server", s.Check).
+		Add("postgres", func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		})
+
+	r.Handle("GET /manage/health", http.HandlerFunc(s.Health))
+	r.Handle("GET /manage/health/live", http.HandlerFunc(checker.Live))
+	r.Handle("GET /manage/health/ready", http.HandlerFunc(checker.Ready))
+
+	s.OnShutdown("logger sync", func(ctx context.Context) error {
+		return zapLogger.Sync()
+	})
+	s.OnShutdown("postgres", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+
 	if err := s.Start(); err != nil {
 		logger.Fatal(err)
 	}
-
-	err = zapLogger.Sync()
-	if err != nil {
-		fmt.Println(err)
-	}
 }
@@ -1,28 +1,140 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+
+	"flight_booking_system/bonusService/pkg/config"
 )
 
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
 type Server struct {
 	http.Server
+	ShutdownTimeout time.Duration
+	ShutdownDelay   time.Duration
+
+	ready atomic.Bool
+	mu    sync.Mutex
+	hooks []hook
 }
 
-func NewServer(myHandler http.Handler) *Server {
+func NewServer(cfg config.ServerConfig, myHandler http.Handler) *Server {
 	return &Server{
-		http.Server{
-			Addr:              ":8050",
+		Server: http.Server{
+			Addr:              cfg.Addr,
 			Handler:           myHandler,
-			ReadTimeout:       10 * time.Second,
-			ReadHeaderTimeout: 10 * time.Second,
-			WriteTimeout:      10 * time.Second,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
 		},
+		ShutdownTimeout: cfg.ShutdownTimeout,
+		ShutdownDelay:   cfg.ShutdownDelay,
 	}
 }
 
+// OnShutdown регистрирует действие, которое выполнится после остановки
+// сервера. Хуки вызываются в порядке, обратном регистрации.
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hooks = append(s.hooks, hook{name: name, fn: fn})
+}
+
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+// Check — проверка для readiness: во время остановки сервер не готов.
+func (s *Server) Check(ctx context.Context) error {
+	if !s.Ready() {
+		return errors.New("server is shutting down")
+	}
+
+	return nil
+}
+
+// Health отвечает 200, пока сервер принимает запросы, и 503 во время остановки.
+func (s *Server) Health(w http.ResponseWriter, r *http.Request) {
+	if !s.Ready() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Start запускает сервер и блокируется до SIGINT/SIGTERM. После сигнала
+// сервер перестаёт быть ready, ждёт ShutdownDelay, дожидается завершения
+// текущих запросов (не дольше ShutdownTimeout) и вызывает хуки.
 func (s *Server) Start() error {
-	log.Println("Start server on :8050")
-	return s.ListenAndServe()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Println("Start server on " + s.Addr)
+		s.ready.Store(true)
+		errCh <- s.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+		s.ready.Store(false)
+	case <-ctx.Done():
+		// Повторный сигнал завершит процесс сразу
+		stop()
+		err = s.shutdown()
+	}
+
+	s.runHooks()
+
+	return err
+}
+
+func (s *Server) shutdown() error {
+	log.Println("Shutting down server on " + s.Addr)
+	s.ready.Store(false)
+
+	time.Sleep(s.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+
+	err := s.Shutdown(ctx)
+	if err != nil {
+		log.Println("server shutdown error: " + err.Error())
+		return err
+	}
+
+	log.Println("Server stopped")
+	return nil
+}
+
+func (s *Server) runHooks() {
+	s.mu.Lock()
+	hooks := s.hooks
+	s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		err := hooks[i].fn(ctx)
+		if err != nil {
+			log.Println("shutdown hook " + hooks[i].name + " failed: " + err.Error())
+		}
+	}
 }
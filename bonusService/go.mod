@@ -9,7 +9,9 @@ require (
 	github.com/lib/pq v1.10.9
 	github.com/ozontech/allure-go/pkg/framework v0.6.32
 	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.8.1
 	go.uber.org/zap v1.27.0
+	gopkg.in/yaml.v3 v3.0.1
 	gorm.io/driver/postgres v1.5.9
 	gorm.io/gorm v1.25.12
 )
@@ -25,10 +27,9 @@ require (
 	github.com/jinzhu/now v1.1.5 // indirect
 	github.com/ozontech/allure-go/pkg/allure v0.6.13 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.8.1 // indirect
+	github.com/stretchr/objx v0.5.0 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
 	golang.org/x/crypto v0.17.0 // indirect
 	golang.org/x/sync v0.1.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
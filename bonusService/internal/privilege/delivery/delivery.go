@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	privilegeUseCase "flight_booking_system/bonusService/internal/privilege/usecase"
 	"fmt"
+	"github.com/pkg/errors"
 	"io"
 	"net/http"
 	"strconv"
@@ -371,3 +372,121 @@ func (ph *PrivilegeHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (ph *PrivilegeHandler) RevertHistory(w http.ResponseWriter, r *http.Request) {
+	ticketUID := r.PathValue("ticketUid")
+	if ticketUID == "" {
+		ph.Logger.Errorw("no ticketUid var")
+		http.Error(w, "unknown error", http.StatusBadRequest)
+		return
+	}
+
+	userName := r.Header.Get("X-User-Name")
+	if userName == "" {
+		ph.Logger.Errorw("no X-User-Name header found")
+		http.Error(w, "unknown error", http.StatusBadRequest)
+		return
+	}
+
+	privilege, err := ph.PrivilegeUseCase.RevertHistory(userName, ticketUID)
+	if err != nil {
+		ph.Logger.Infow("can`t revert privilegeHistory",
+			"err:", err.Error())
+		http.Error(w, "can`t revert privilegeHistory", http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(models.PrivilegeToDTO(*privilege))
+	if err != nil {
+		ph.Logger.Errorw("can`t marshal privilege",
+			"err:", err.Error())
+		http.Error(w, "can`t make privilege", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ph.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		http.Error(w, "can`t write response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (ph *PrivilegeHandler) ApplyOperation(w http.ResponseWriter, r *http.Request) {
+	operation := models.PrivilegeOperation{}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		ph.Logger.Errorw("can`t read body of request",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	err = r.Body.Close()
+	if err != nil {
+		ph.Logger.Errorw("can`t close body of request", "err:", err.Error())
+		http.Error(w, "close error", http.StatusInternalServerError)
+		return
+	}
+
+	err = json.Unmarshal(body, &operation)
+	if err != nil {
+		ph.Logger.Infow("can`t unmarshal form",
+			"err:", err.Error())
+		http.Error(w, "bad  data", http.StatusBadRequest)
+		return
+	}
+
+	// Пользователь берётся только из identity-токена, а не из тела запроса
+	operation.Username = r.Header.Get("X-User-Name")
+	if operation.Username == "" {
+		ph.Logger.Infow("no X-User-Name header found")
+		http.Error(w, "no identity", http.StatusUnauthorized)
+		return
+	}
+
+	// Зачисление без оплаты проводит только сам gateway
+	if operation.OperationType == models.OperationRefund && r.Header.Get("X-User-Role") != models.RoleService {
+		ph.Logger.Infow("refund operation from user identity", "username", operation.Username)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	privilege, privilegeHistory, err := ph.PrivilegeUseCase.ApplyOperation(&operation)
+	if err != nil {
+		ph.Logger.Infow("can`t apply privilege operation",
+			"err:", err.Error())
+
+		if errors.Is(err, privilegeUseCase.ErrInvalidOperation) {
+			http.Error(w, "bad privilege operation", http.StatusBadRequest)
+			return
+		}
+
+		http.Error(w, "can`t apply privilege operation", http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(models.PrivilegeOperationToDTO(*privilege, *privilegeHistory))
+	if err != nil {
+		ph.Logger.Errorw("can`t marshal privilege operation",
+			"err:", err.Error())
+		http.Error(w, "can`t make privilege operation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ph.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		http.Error(w, "can`t write response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"flight_booking_system/bonusService/pkg/logger"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type pgPrivilegeRepo struct {
@@ -110,3 +111,55 @@ func (pr *pgPrivilegeRepo) GetHistory(username string) ([]*models.PrivilegeHisto
 
 	return privilegeHistory, nil
 }
+
+// UpdateBalance блокирует строку привилегии пользователя до конца транзакции,
+// передаёт её в apply вместе с историей по билету, прочитанной в той же
+// транзакции, и сохраняет новый баланс вместе с записью истории.
+// Если apply вернул nil-запись, баланс не меняется. Счёт пользователя без
+// привилегии заводится при первой операции.
+func (pr *pgPrivilegeRepo) UpdateBalance(username string, ticketUID string, apply func(p *models.Privilege, history []*models.PrivilegeHistory) (*models.PrivilegeHistory, error)) (*models.Privilege, *models.PrivilegeHistory, error) {
+	var p models.Privilege
+	var h *models.PrivilegeHistory
+
+	err := pr.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("username = ?", username).Take(&p)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			// Параллельная первая операция могла уже завести счёт
+			res = tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "username"}}, DoNothing: true}).
+				Create(&models.Privilege{Username: username, Status: models.PrivilegeBronze})
+			if res.Error != nil {
+				return res.Error
+			}
+
+			res = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("username = ?", username).Take(&p)
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+
+		var history []*models.PrivilegeHistory
+		res = tx.Where("privilege_id = ? AND ticket_uid = ?", p.ID, ticketUID).Order("id").Find(&history)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		var err error
+		h, err = apply(&p, history)
+		if err != nil || h == nil {
+			return err
+		}
+
+		res = tx.Model(&models.Privilege{}).Where("id = ?", p.ID).Update("balance", p.Balance)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		return tx.Create(h).Error
+	})
+
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "pgPrivilegeRepo.UpdateBalance error")
+	}
+
+	return &p, h, nil
+}
@@ -11,4 +11,5 @@ type PrivilegeRepositoryI interface {
 	GetAll() ([]*models.Privilege, error)
 	CreateHistory(p *models.PrivilegeHistory) error
 	GetHistory(username string) ([]*models.PrivilegeHistory, error)
+	UpdateBalance(username string, ticketUID string, apply func(p *models.Privilege, history []*models.PrivilegeHistory) (*models.PrivilegeHistory, error)) (*models.Privilege, *models.PrivilegeHistory, error)
 }
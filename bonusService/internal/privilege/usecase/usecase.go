@@ -1,11 +1,18 @@
 package usecase
 
 import (
+	"math"
+	"time"
+
 	privilegeRep "flight_booking_system/bonusService/internal/privilege/repository"
 	"flight_booking_system/bonusService/models"
 	"github.com/pkg/errors"
 )
 
+const bonusPercent = 10
+
+var ErrInvalidOperation = errors.New("invalid privilege operation")
+
 type PrivilegeUseCaseI interface {
 	Create(p *models.Privilege) error
 	Get(id int) (*models.Privilege, error)
@@ -15,6 +22,8 @@ type PrivilegeUseCaseI interface {
 	GetAll() ([]*models.Privilege, error)
 	GetHistory(username string) ([]*models.PrivilegeHistory, error)
 	CreateHistory(p *models.PrivilegeHistory) error
+	RevertHistory(username string, ticketUID string) (*models.Privilege, error)
+	ApplyOperation(op *models.PrivilegeOperation) (*models.Privilege, *models.PrivilegeHistory, error)
 }
 
 type privilegeUseCase struct {
@@ -109,3 +118,98 @@ func (pUC *privilegeUseCase) GetHistory(username string) ([]*models.PrivilegeHis
 	}
 	return privilegeHists, nil
 }
+
+// ApplyOperation атомарно применяет операцию по бонусному счёту:
+//   - DEBIT_THE_ACCOUNT списывает с баланса столько бонусов, сколько есть, но не больше Amount;
+//   - FILL_IN_BALANCE начисляет bonusPercent процентов от Amount;
+//   - REFUND зачисляет на баланс весь Amount, например разницу тарифов при обмене.
+func (pUC *privilegeUseCase) ApplyOperation(op *models.PrivilegeOperation) (*models.Privilege, *models.PrivilegeHistory, error) {
+	if op.Username == "" || op.TicketUID == "" || op.Amount <= 0 {
+		return nil, nil, errors.Wrap(ErrInvalidOperation, "privilegeUseCase.ApplyOperation error: bad operation params")
+	}
+
+	switch op.OperationType {
+	case models.OperationFillInBalance, models.OperationDebitTheAccount, models.OperationRefund:
+	default:
+		return nil, nil, errors.Wrapf(ErrInvalidOperation, "privilegeUseCase.ApplyOperation error: unknown operation type %q", op.OperationType)
+	}
+
+	privilege, history, err := pUC.privilegeRepository.UpdateBalance(op.Username, op.TicketUID, func(p *models.Privilege, _ []*models.PrivilegeHistory) (*models.PrivilegeHistory, error) {
+		h := &models.PrivilegeHistory{
+			PrivilegeID:   p.ID,
+			TicketUID:     op.TicketUID,
+			DateTime:      time.Now(),
+			OperationType: op.OperationType,
+		}
+
+		switch op.OperationType {
+		case models.OperationDebitTheAccount:
+			h.BalanceDiff = min(op.Amount, p.Balance)
+			p.Balance -= h.BalanceDiff
+		case models.OperationRefund:
+			h.OperationType = models.OperationFillInBalance
+			h.BalanceDiff = op.Amount
+			p.Balance += h.BalanceDiff
+		default:
+			h.BalanceDiff = int(math.Round(float64(op.Amount) * bonusPercent / 100))
+			p.Balance += h.BalanceDiff
+		}
+
+		return h, nil
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "privilegeUseCase.ApplyOperation error")
+	}
+
+	return privilege, history, nil
+}
+
+// RevertHistory отменяет движение бонусов по билету: списанные бонусы
+// возвращаются на счёт, начисленные списываются. Баланс не может стать меньше 0,
+// поэтому если начисленные бонусы уже потрачены, списывается только остаток.
+// Билет откатывается один раз: повторный вызов ничего не меняет, даже если
+// при первом часть бонусов была списана в убыток.
+func (pUC *privilegeUseCase) RevertHistory(username string, ticketUID string) (*models.Privilege, error) {
+	privilege, _, err := pUC.privilegeRepository.UpdateBalance(username, ticketUID, func(p *models.Privilege, history []*models.PrivilegeHistory) (*models.PrivilegeHistory, error) {
+		net := 0
+		for _, h := range history {
+			if h.Reversal {
+				return nil, nil
+			}
+
+			switch h.OperationType {
+			case models.OperationFillInBalance:
+				net += h.BalanceDiff
+			case models.OperationDebitTheAccount:
+				net -= h.BalanceDiff
+			}
+		}
+
+		reversal := &models.PrivilegeHistory{
+			PrivilegeID: p.ID,
+			TicketUID:   ticketUID,
+			DateTime:    time.Now(),
+			Reversal:    true,
+		}
+
+		switch {
+		case net > 0:
+			reversal.OperationType = models.OperationDebitTheAccount
+			reversal.BalanceDiff = min(net, p.Balance)
+			p.Balance -= reversal.BalanceDiff
+		case net < 0:
+			reversal.OperationType = models.OperationFillInBalance
+			reversal.BalanceDiff = -net
+			p.Balance += reversal.BalanceDiff
+		default:
+			return nil, nil
+		}
+
+		return reversal, nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "privilegeUseCase.RevertHistory error")
+	}
+
+	return privilege, nil
+}
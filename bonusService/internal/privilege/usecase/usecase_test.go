@@ -1,174 +1,337 @@
 package usecase
 
 import (
-	"flight_booking_system/ticketService/internal/testBuilders"
-	ticketMocks "flight_booking_system/ticketService/internal/ticket/repository/mocks"
-	"flight_booking_system/ticketService/models"
+	privilegeMocks "flight_booking_system/bonusService/internal/privilege/repository/mocks"
+	"flight_booking_system/bonusService/models"
 	"github.com/bxcodec/faker"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/ozontech/allure-go/pkg/framework/suite"
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
 	"testing"
 )
 
-type TicketTestSuite struct {
+const testTicketUID = "049161bb-badd-4fa8-9d90-87c9a82b0668"
+
+type PrivilegeTestSuite struct {
 	suite.Suite
-	uc             TicketUseCaseI
-	ticketRepoMock *ticketMocks.TicketRepositoryI
-	ticketBuilder  *testBuilders.TicketBuilder
+	uc                PrivilegeUseCaseI
+	privilegeRepoMock *privilegeMocks.PrivilegeRepositoryI
+	ledger            *ledger
 }
 
-func TestTicketTestSuite(t *testing.T) {
-	suite.RunSuite(t, new(TicketTestSuite))
+func TestPrivilegeTestSuite(t *testing.T) {
+	suite.RunSuite(t, new(PrivilegeTestSuite))
 }
 
-func (s *TicketTestSuite) BeforeEach(t provider.T) {
-	s.ticketRepoMock = ticketMocks.NewTicketRepositoryI(t)
-	s.uc = New(s.ticketRepoMock)
-	s.ticketBuilder = testBuilders.NewTicketBuilder()
+func (s *PrivilegeTestSuite) BeforeEach(t provider.T) {
+	s.privilegeRepoMock = privilegeMocks.NewPrivilegeRepositoryI(t)
+	s.uc = New(s.privilegeRepoMock)
+	s.ledger = &ledger{privilege: models.Privilege{ID: 1, Username: "username", Status: "BRONZE"}}
 }
 
-func (s *TicketTestSuite) TestCreateTicket(t provider.T) {
-	ticket := s.ticketBuilder.WithID(1).
-		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
-		Build()
+// ledger заменяет UpdateBalance репозитория: отдаёт в apply счёт и историю по
+// билету и сохраняет запись истории, если apply её вернул.
+type ledger struct {
+	privilege models.Privilege
+	history   []*models.PrivilegeHistory
+}
 
-	s.ticketRepoMock.On("Create", &ticket).Return(nil)
-	err := s.uc.Create(&ticket)
+func (l *ledger) updateBalance(username string, ticketUID string, apply func(*models.Privilege, []*models.PrivilegeHistory) (*models.PrivilegeHistory, error)) (*models.Privilege, *models.PrivilegeHistory, error) {
+	var history []*models.PrivilegeHistory
+	for _, h := range l.history {
+		if h.TicketUID == ticketUID {
+			history = append(history, h)
+		}
+	}
+
+	p := l.privilege
+	h, err := apply(&p, history)
+	if err != nil || h == nil {
+		return &l.privilege, nil, err
+	}
+
+	l.privilege = p
+	l.history = append(l.history, h)
+
+	return &p, h, nil
+}
+
+func (s *PrivilegeTestSuite) expectUpdateBalance() {
+	s.privilegeRepoMock.On("UpdateBalance", "username", mock.Anything, mock.Anything).Return(s.ledger.updateBalance)
+}
+
+func (s *PrivilegeTestSuite) apply(operationType string, ticketUID string, amount int) (*models.PrivilegeHistory, error) {
+	_, h, err := s.uc.ApplyOperation(&models.PrivilegeOperation{
+		Username:      "username",
+		TicketUID:     ticketUID,
+		OperationType: operationType,
+		Amount:        amount,
+	})
+
+	return h, err
+}
+
+func (s *PrivilegeTestSuite) TestCreatePrivilege(t provider.T) {
+	privilege := models.Privilege{ID: 1, Username: "username", Status: "BRONZE"}
+
+	s.privilegeRepoMock.On("Create", &privilege).Return(nil)
+	err := s.uc.Create(&privilege)
 
 	t.Assert().NoError(err)
-	t.Assert().Equal(ticket.ID, 1)
+	t.Assert().Equal(privilege.ID, 1)
 }
 
-func (s *TicketTestSuite) TestUpdateTicket(t provider.T) {
-	var err error
-	ticket := s.ticketBuilder.WithID(1).
-		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
-		Build()
+func (s *PrivilegeTestSuite) TestGetPrivilege(t provider.T) {
+	privilege := models.Privilege{ID: 1, Username: "username", Status: "BRONZE"}
 
-	notFoundTicket := s.ticketBuilder.WithID(0).Build()
+	s.privilegeRepoMock.On("Get", privilege.ID).Return(&privilege, nil)
+	result, err := s.uc.Get(privilege.ID)
 
-	s.ticketRepoMock.On("Get", ticket.ID).Return(&ticket, nil)
-	s.ticketRepoMock.On("Update", &ticket).Return(nil)
-	s.ticketRepoMock.On("Get", notFoundTicket.ID).Return(&notFoundTicket, errors.Wrap(err, "Ticket not found"))
-	s.ticketRepoMock.On("Update", &notFoundTicket).Return(errors.Wrap(err, "Ticket not found"))
+	t.Assert().NoError(err)
+	t.Assert().Equal(&privilege, result)
+}
+
+func (s *PrivilegeTestSuite) TestDeletePrivilege(t provider.T) {
+	errNotFound := errors.New("privilege not found")
+	privilege := models.Privilege{ID: 1, Username: "username", Status: "BRONZE"}
+
+	s.privilegeRepoMock.On("Get", privilege.ID).Return(&privilege, nil)
+	s.privilegeRepoMock.On("Delete", privilege.ID).Return(nil)
+	s.privilegeRepoMock.On("Get", 0).Return(nil, errNotFound)
 
 	cases := map[string]struct {
-		ArgData *models.Ticket
-		Error   error
+		PrivilegeID int
+		Error       error
 	}{
 		"success": {
-			ArgData: &ticket,
-			Error:   nil,
+			PrivilegeID: privilege.ID,
+			Error:       nil,
 		},
-		"Ticket not found": {
-			ArgData: &notFoundTicket,
-			Error:   errors.Wrap(err, "Ticket not found"),
+		"Privilege not found": {
+			PrivilegeID: 0,
+			Error:       errNotFound,
 		},
 	}
 
 	for name, test := range cases {
 		t.Run(name, func(t provider.T) {
-			err := s.uc.Update(test.ArgData)
+			err := s.uc.Delete(test.PrivilegeID)
 			t.Assert().ErrorIs(err, test.Error)
 		})
 	}
 }
 
-func (s *TicketTestSuite) TestGetTicket(t provider.T) {
-	ticket := s.ticketBuilder.WithID(1).
-		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
-		Build()
-
-	s.ticketRepoMock.On("Get", ticket.ID).Return(&ticket, nil)
-	result, err := s.uc.Get(ticket.ID)
-
+func (s *PrivilegeTestSuite) TestGetAll(t provider.T) {
+	privileges := make([]models.Privilege, 0, 10)
+	err := faker.FakeData(&privileges)
 	t.Assert().NoError(err)
-	t.Assert().Equal(&ticket, result)
-}
 
-func (s *TicketTestSuite) TestDeleteTicket(t provider.T) {
-	var err error
-	ticket := s.ticketBuilder.WithID(1).
-		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
-		Build()
+	privilegesPtr := make([]*models.Privilege, len(privileges))
+	for i := range privileges {
+		privilegesPtr[i] = &privileges[i]
+	}
 
-	notFoundTicket := s.ticketBuilder.WithID(0).Build()
+	s.privilegeRepoMock.On("GetAll").Return(privilegesPtr, nil)
 
-	s.ticketRepoMock.On("Get", ticket.ID).Return(&ticket, nil)
-	s.ticketRepoMock.On("Delete", ticket.ID).Return(nil)
-	s.ticketRepoMock.On("Get", notFoundTicket.ID).Return(&notFoundTicket, errors.Wrap(err, "Ticket not found"))
-	s.ticketRepoMock.On("Delete", notFoundTicket.ID).Return(errors.Wrap(err, "Ticket not found"))
+	resPrivileges, err := s.uc.GetAll()
+	t.Assert().NoError(err)
+	t.Assert().Equal(privilegesPtr, resPrivileges)
+}
 
+func (s *PrivilegeTestSuite) TestApplyOperation(t provider.T) {
 	cases := map[string]struct {
-		TicketID int
-		Error    error
+		Balance       int
+		OperationType string
+		Amount        int
+		BalanceDiff   int
+		HistoryType   string
+		ResBalance    int
 	}{
-		"success": {
-			TicketID: ticket.ID,
-			Error:    nil,
+		"debit": {
+			Balance:       500,
+			OperationType: models.OperationDebitTheAccount,
+			Amount:        300,
+			BalanceDiff:   300,
+			HistoryType:   models.OperationDebitTheAccount,
+			ResBalance:    200,
+		},
+		"debit clamped to balance": {
+			Balance:       100,
+			OperationType: models.OperationDebitTheAccount,
+			Amount:        300,
+			BalanceDiff:   100,
+			HistoryType:   models.OperationDebitTheAccount,
+			ResBalance:    0,
 		},
-		"Ticket not found": {
-			TicketID: notFoundTicket.ID,
-			Error:    errors.Wrap(err, "Ticket not found"),
+		"accrual": {
+			Balance:       100,
+			OperationType: models.OperationFillInBalance,
+			Amount:        1505,
+			BalanceDiff:   151,
+			HistoryType:   models.OperationFillInBalance,
+			ResBalance:    251,
+		},
+		"refund stored as fill in": {
+			Balance:       100,
+			OperationType: models.OperationRefund,
+			Amount:        300,
+			BalanceDiff:   300,
+			HistoryType:   models.OperationFillInBalance,
+			ResBalance:    400,
 		},
 	}
 
 	for name, test := range cases {
 		t.Run(name, func(t provider.T) {
-			err := s.uc.Delete(test.TicketID)
-			t.Assert().ErrorIs(err, test.Error)
+			s.BeforeEach(t)
+			s.ledger.privilege.Balance = test.Balance
+			s.expectUpdateBalance()
+
+			h, err := s.apply(test.OperationType, testTicketUID, test.Amount)
+
+			t.Require().NoError(err)
+			t.Assert().Equal(test.HistoryType, h.OperationType)
+			t.Assert().Equal(test.BalanceDiff, h.BalanceDiff)
+			t.Assert().Equal(testTicketUID, h.TicketUID)
+			t.Assert().False(h.Reversal)
+			t.Assert().Equal(test.ResBalance, s.ledger.privilege.Balance)
 		})
 	}
 }
 
-func (s *TicketTestSuite) TestGetAll(t provider.T) {
-	tickets := make([]models.Ticket, 0, 10)
-	err := faker.FakeData(&tickets)
-	t.Assert().NoError(err)
+func (s *PrivilegeTestSuite) TestApplyOperationInvalid(t provider.T) {
+	cases := map[string]struct {
+		OperationType string
+		TicketUID     string
+		Amount        int
+	}{
+		"unknown type":    {OperationType: "TRANSFER", TicketUID: testTicketUID, Amount: 100},
+		"no ticket":       {OperationType: models.OperationRefund, Amount: 100},
+		"zero amount":     {OperationType: models.OperationRefund, TicketUID: testTicketUID},
+		"negative amount": {OperationType: models.OperationRefund, TicketUID: testTicketUID, Amount: -100},
+	}
 
-	ticketsPtr := make([]*models.Ticket, len(tickets))
-	for i, ticket := range tickets {
-		ticketsPtr[i] = &ticket
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			_, err := s.apply(test.OperationType, test.TicketUID, test.Amount)
+			t.Assert().ErrorIs(err, ErrInvalidOperation)
+		})
 	}
+}
+
+func (s *PrivilegeTestSuite) TestRevertDebit(t provider.T) {
+	s.ledger.privilege.Balance = 500
+	s.expectUpdateBalance()
+
+	_, err := s.apply(models.OperationDebitTheAccount, testTicketUID, 300)
+	t.Require().NoError(err)
+
+	privilege, err := s.uc.RevertHistory("username", testTicketUID)
+
+	t.Require().NoError(err)
+	t.Assert().Equal(500, privilege.Balance)
+	t.Require().Len(s.ledger.history, 2)
+	t.Assert().Equal(models.OperationFillInBalance, s.ledger.history[1].OperationType)
+	t.Assert().Equal(300, s.ledger.history[1].BalanceDiff)
+	t.Assert().True(s.ledger.history[1].Reversal)
+}
+
+func (s *PrivilegeTestSuite) TestRevertAccrual(t provider.T) {
+	s.ledger.privilege.Balance = 100
+	s.expectUpdateBalance()
+
+	_, err := s.apply(models.OperationFillInBalance, testTicketUID, 1500)
+	t.Require().NoError(err)
+
+	privilege, err := s.uc.RevertHistory("username", testTicketUID)
+
+	t.Require().NoError(err)
+	t.Assert().Equal(100, privilege.Balance)
+	t.Require().Len(s.ledger.history, 2)
+	t.Assert().Equal(models.OperationDebitTheAccount, s.ledger.history[1].OperationType)
+	t.Assert().Equal(150, s.ledger.history[1].BalanceDiff)
+}
+
+func (s *PrivilegeTestSuite) TestRevertRefund(t provider.T) {
+	s.ledger.privilege.Balance = 100
+	s.expectUpdateBalance()
 
-	s.ticketRepoMock.On("GetAll").Return(ticketsPtr, nil)
+	_, err := s.apply(models.OperationRefund, testTicketUID, 300)
+	t.Require().NoError(err)
+
+	privilege, err := s.uc.RevertHistory("username", testTicketUID)
+
+	t.Require().NoError(err)
+	t.Assert().Equal(100, privilege.Balance)
+	t.Require().Len(s.ledger.history, 2)
+	t.Assert().Equal(models.OperationDebitTheAccount, s.ledger.history[1].OperationType)
+	t.Assert().Equal(300, s.ledger.history[1].BalanceDiff)
+}
+
+func (s *PrivilegeTestSuite) TestRevertSpentAccrual(t provider.T) {
+	const otherTicketUID = "a2f3c4d5-e6f7-4a8b-9c0d-1e2f3a4b5c6d"
+
+	s.ledger.privilege.Balance = 0
+	s.expectUpdateBalance()
+
+	// Начислено 150, из них 120 потрачено на другой билет
+	_, err := s.apply(models.OperationFillInBalance, testTicketUID, 1500)
+	t.Require().NoError(err)
+	_, err = s.apply(models.OperationDebitTheAccount, otherTicketUID, 120)
+	t.Require().NoError(err)
+
+	privilege, err := s.uc.RevertHistory("username", testTicketUID)
+
+	t.Require().NoError(err)
+	t.Assert().Equal(0, privilege.Balance)
+	t.Require().Len(s.ledger.history, 3)
+	t.Assert().Equal(models.OperationDebitTheAccount, s.ledger.history[2].OperationType)
+	t.Assert().Equal(30, s.ledger.history[2].BalanceDiff)
+}
+
+func (s *PrivilegeTestSuite) TestRevertTwice(t provider.T) {
+	const otherTicketUID = "a2f3c4d5-e6f7-4a8b-9c0d-1e2f3a4b5c6d"
 
 	cases := map[string]struct {
-		Tickets []models.Ticket
-		Error   error
+		Setup      func()
+		ResBalance int
 	}{
-		"success": {
-			Tickets: tickets,
-			Error:   nil,
+		"debit": {
+			Setup: func() {
+				s.ledger.privilege.Balance = 500
+				_, _ = s.apply(models.OperationDebitTheAccount, testTicketUID, 300)
+			},
+			ResBalance: 500,
+		},
+		"spent accrual": {
+			// После отката часть начисления списана в убыток; новые бонусы
+			// повторный откат забирать не должен
+			Setup: func() {
+				_, _ = s.apply(models.OperationFillInBalance, testTicketUID, 1500)
+				_, _ = s.apply(models.OperationDebitTheAccount, otherTicketUID, 150)
+				_, _ = s.uc.RevertHistory("username", testTicketUID)
+				_, _ = s.apply(models.OperationRefund, otherTicketUID, 200)
+			},
+			ResBalance: 200,
 		},
 	}
 
 	for name, test := range cases {
 		t.Run(name, func(t provider.T) {
-			resTickets, err := s.uc.GetAll()
-			t.Assert().ErrorIs(err, test.Error)
-			t.Assert().Equal(ticketsPtr, resTickets)
+			s.BeforeEach(t)
+			s.expectUpdateBalance()
+			test.Setup()
+
+			_, err := s.uc.RevertHistory("username", testTicketUID)
+			t.Require().NoError(err)
+			historyLen := len(s.ledger.history)
+
+			privilege, err := s.uc.RevertHistory("username", testTicketUID)
+
+			t.Require().NoError(err)
+			t.Assert().Equal(test.ResBalance, privilege.Balance)
+			t.Assert().Len(s.ledger.history, historyLen)
 		})
 	}
 }
@@ -2,6 +2,21 @@ package models
 
 import "time"
 
+const (
+	OperationFillInBalance   = "FILL_IN_BALANCE"
+	OperationDebitTheAccount = "DEBIT_THE_ACCOUNT"
+	// OperationRefund — возврат денег на бонусный счёт один к одному. В истории
+	// записывается как FILL_IN_BALANCE. Принимается только от gateway.
+	OperationRefund = "REFUND"
+)
+
+// PrivilegeBronze — статус, с которым заводится новый бонусный счёт.
+const PrivilegeBronze = "BRONZE"
+
+// RoleService — роль identity-токена, с которым gateway действует от своего
+// имени, а не по запросу пользователя.
+const RoleService = "SERVICE"
+
 type Tabler interface {
 	TableName() string
 }
@@ -42,6 +57,8 @@ type PrivilegeHistory struct {
 	DateTime      time.Time `json:"date" gorm:"column:datetime"`
 	BalanceDiff   int       `json:"balanceDiff" db:"balance_diff"`
 	OperationType string    `json:"operationType" db:"operation_type"`
+	// Reversal — запись отката операций по билету
+	Reversal bool `json:"-" db:"reversal"`
 }
 
 type PrivilegeHistoryDTO struct {
@@ -67,3 +84,28 @@ func PrivilegeHistoryToDTOs(privilegeHists []*PrivilegeHistory) []PrivilegeHisto
 	}
 	return res
 }
+
+type PrivilegeOperation struct {
+	Username      string `json:"username"`
+	TicketUID     string `json:"ticketUid"`
+	OperationType string `json:"operationType"`
+	Amount        int    `json:"amount"`
+}
+
+type PrivilegeOperationDTO struct {
+	ID            int    `json:"id"`
+	Balance       int    `json:"balance"`
+	Status        string `json:"status"`
+	BalanceDiff   int    `json:"balanceDiff"`
+	OperationType string `json:"operationType"`
+}
+
+func PrivilegeOperationToDTO(privilege Privilege, privilegeHist PrivilegeHistory) PrivilegeOperationDTO {
+	return PrivilegeOperationDTO{
+		ID:            privilege.ID,
+		Balance:       privilege.Balance,
+		Status:        privilege.Status,
+		BalanceDiff:   privilegeHist.BalanceDiff,
+		OperationType: privilegeHist.OperationType,
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"flight_booking_system/bonusService/pkg/logger"
+)
+
+const (
+	identityHeader = "X-User-Identity"
+	userNameHeader = "X-User-Name"
+	userRoleHeader = "X-User-Role"
+)
+
+type IdentitySessionsManager interface {
+	GetIdentity(string) (string, string, error)
+}
+
+// Identity подставляет в X-User-Name и X-User-Role имя и роль пользователя из
+// identity-токена, подписанного gateway. Роль берётся только из токена. Если
+// required, запросы к /api/ без токена отклоняются.
+func Identity(logger logger.Logger, sessions IdentitySessionsManager, required bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(userRoleHeader)
+
+		token := r.Header.Get(identityHeader)
+		if token == "" {
+			if required && strings.HasPrefix(r.URL.Path, "/api/") {
+				logger.Infow("identity",
+					"url", r.URL.Path,
+					"method", r.Method,
+					"remote_addr", r.RemoteAddr,
+					"result", "identity header not found")
+				http.Error(w, "no identity", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userName, userRole, err := sessions.GetIdentity(token)
+		if err != nil {
+			logger.Infow("identity",
+				"url", r.URL.Path,
+				"method", r.Method,
+				"remote_addr", r.RemoteAddr,
+				"result", "invalid identity token",
+				"err:", err.Error())
+			http.Error(w, "invalid identity", http.StatusUnauthorized)
+			return
+		}
+
+		r.Header.Set(userNameHeader, userName)
+		r.Header.Set(userRoleHeader, userRole)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -7,41 +7,78 @@ import (
 	"github.com/pkg/errors"
 )
 
-var tokenKey = []byte("fvoNImvpdms023sv0s9vs")
+// TokenTypeIdentity — токен, которым gateway подтверждает пользователя.
+const TokenTypeIdentity = "identity"
 
 type UserClaims struct {
-	ID   int    `json:"id"`
-	Role string `json:"role"`
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
 }
 
 type Claims struct {
-	User UserClaims `json:"user"`
+	User      UserClaims `json:"user"`
+	TokenType string     `json:"tokenType"`
 	jwt.RegisteredClaims
 }
 
-type JWTSessionsManager struct{}
+type JWTSessionsManager struct {
+	TokenKey []byte
+}
 
-func (jsm JWTSessionsManager) GetUser(inToken string) (int, string, error) {
-	token, err := jwt.ParseWithClaims(inToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return tokenKey, nil
-	})
+func New(tokenKey string) JWTSessionsManager {
+	return JWTSessionsManager{TokenKey: []byte(tokenKey)}
+}
 
-	claims, ok := token.Claims.(*Claims)
+// GetUser проверяет identity-токен gateway и возвращает id и роль пользователя.
+// Access- и refresh-токены gateway не принимаются.
+func (jsm JWTSessionsManager) GetUser(inToken string) (int, string, error) {
+	claims, err := jsm.parse(inToken)
+	if err != nil {
+		return -1, "", err
+	}
 
-	if !ok || !token.Valid {
-		return -1, "", errors.Wrapf(err, "can`t parse or validate session token \"%s\"", inToken)
+	if claims.TokenType != TokenTypeIdentity {
+		return -1, "", errors.Errorf("token is not an identity token")
 	}
 
 	return claims.User.ID, claims.User.Role, nil
 }
 
+func (jsm JWTSessionsManager) parse(inToken string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(inToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return jsm.TokenKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, errors.Wrap(err, "can`t parse or validate session token")
+	}
+
+	return claims, nil
+}
+
+// GetIdentity проверяет identity-токен gateway и возвращает имя и роль
+// пользователя.
+func (jsm JWTSessionsManager) GetIdentity(inToken string) (string, string, error) {
+	claims, err := jsm.parse(inToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	if claims.TokenType != TokenTypeIdentity || claims.User.Username == "" {
+		return "", "", errors.Errorf("token is not an identity token")
+	}
+
+	return claims.User.Username, claims.User.Role, nil
+}
+
 func (jsm JWTSessionsManager) CreateSession(id int, role string) (string, error) {
 	claims := Claims{
-		UserClaims{
+		User: UserClaims{
 			ID:   id,
 			Role: role,
 		},
-		jwt.RegisteredClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().AddDate(0, 0, 7)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -49,7 +86,7 @@ func (jsm JWTSessionsManager) CreateSession(id int, role string) (string, error)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	tokenString, err := token.SignedString(tokenKey)
+	tokenString, err := token.SignedString(jsm.TokenKey)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to convert token to string")
 	}
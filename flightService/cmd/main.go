@@ -1,14 +1,27 @@
 package main
 
 import (
+	"context"
 	"flight_booking_system/flightService/cmd/server"
+	airportDel "flight_booking_system/flightService/internal/airport/delivery"
+	airportCache "flight_booking_system/flightService/internal/airport/repository/cache"
+	pgAirport "flight_booking_system/flightService/internal/airport/repository/postgres"
+	airportUseCase "flight_booking_system/flightService/internal/airport/usecase"
 	flightDel "flight_booking_system/flightService/internal/flight/delivery"
 	pgFlight "flight_booking_system/flightService/internal/flight/repository/postgres"
 	flightUseCase "flight_booking_system/flightService/internal/flight/usecase"
+	seatDel "flight_booking_system/flightService/internal/seat/delivery"
+	pgSeat "flight_booking_system/flightService/internal/seat/repository/postgres"
+	seatUseCase "flight_booking_system/flightService/internal/seat/usecase"
+	"flight_booking_system/flightService/models"
+	"flight_booking_system/flightService/pkg/config"
+	appContext "flight_booking_system/flightService/pkg/context"
+	"flight_booking_system/flightService/pkg/health"
 	"flight_booking_system/flightService/pkg/middleware"
-	"fmt"
+	"flight_booking_system/flightService/pkg/session"
 	"log"
 	"net/http"
+	"os"
 
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
@@ -16,45 +29,100 @@ import (
 	"gorm.io/gorm"
 )
 
-var prodCfgPg = postgres.Config{DSN: "host=postgres user=program password=test dbname=flights port=5432"}
-
 func main() {
 	zapLogger := zap.Must(zap.NewDevelopment())
 	logger := zapLogger.Sugar()
 
-	db, err := gorm.Open(postgres.New(prodCfgPg), &gorm.Config{})
+	cfg, err := config.Load(os.Getenv("CONFIG_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: cfg.Postgres.DSN}), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	airportRepo := airportCache.New(pgAirport.New(logger, db))
+	seatRepo := pgSeat.New(logger, db)
+
 	flightHandler := flightDel.FlightHandler{
-		FlightUseCase: flightUseCase.New(pgFlight.New(logger, db)),
+		FlightUseCase: flightUseCase.New(pgFlight.New(logger, db, airportRepo), airportRepo, seatRepo),
 		Logger:        logger,
 	}
 
+	airportHandler := airportDel.AirportHandler{
+		AirportUseCase: airportUseCase.New(airportRepo),
+		Logger:         logger,
+	}
+
+	seatHandler := seatDel.SeatHandler{
+		SeatUseCase: seatUseCase.New(seatRepo),
+		Logger:      logger,
+	}
+
+	authManager := &middleware.AuthManager{
+		SessionManager: session.New(cfg.Session.TokenKey),
+		Logger:         logger,
+		ContextManager: appContext.Manager{},
+	}
+
 	r := http.NewServeMux()
 
 	r.Handle("GET /api/v1/flights/{flightId}", http.HandlerFunc(flightHandler.Get))
 	r.Handle("GET /api/v1/flights", http.HandlerFunc(flightHandler.GetAll))
-	r.Handle("POST /api/v1/flights", http.HandlerFunc(flightHandler.Create))
-	r.Handle("PATCH /api/v1/flights/{flightId}", http.HandlerFunc(flightHandler.Update))
-	r.Handle("DELETE /api/v1/flights/{flightId}", http.HandlerFunc(flightHandler.Delete))
+	r.Handle("POST /api/v1/flights", authManager.Auth(http.HandlerFunc(flightHandler.Create), models.RoleAdmin))
+	r.Handle("PATCH /api/v1/flights/{flightId}", authManager.Auth(http.HandlerFunc(flightHandler.Update), models.RoleAdmin))
+	r.Handle("PUT /api/v1/flights/{flightId}/status", authManager.Auth(http.HandlerFunc(flightHandler.UpdateStatus), models.RoleAdmin))
+	r.Handle("DELETE /api/v1/flights/{flightId}", authManager.Auth(http.HandlerFunc(flightHandler.Delete), models.RoleAdmin))
 	r.Handle("GET /api/v1/flightsPaginate", http.HandlerFunc(flightHandler.GetAllPaginate))
+	r.Handle("GET /api/v1/flightsBatch", http.HandlerFunc(flightHandler.GetBatch))
+	r.Handle("GET /api/v1/flightsSearch", http.HandlerFunc(flightHandler.Search))
+	r.Handle("GET /api/v1/routes", http.HandlerFunc(flightHandler.FindRoutes))
+	r.Handle("POST /api/v1/flights/{flightId}/reservations", authManager.Auth(http.HandlerFunc(flightHandler.ReserveSeat)))
+	r.Handle("DELETE /api/v1/reservations/{ticketUid}", authManager.Auth(http.HandlerFunc(flightHandler.ReleaseSeat)))
+	r.Handle("GET /api/v1/flights/{flightId}/fares", http.HandlerFunc(flightHandler.GetFares))
+	r.Handle("PUT /api/v1/flights/{flightId}/fares/{fareClass}", authManager.Auth(http.HandlerFunc(flightHandler.SaveFare), models.RoleAdmin))
+	r.Handle("GET /api/v1/flights/{flightId}/seats", http.HandlerFunc(seatHandler.GetSeatMap))
+	r.Handle("PUT /api/v1/reservations/{ticketUid}/seat", authManager.Auth(http.HandlerFunc(seatHandler.AssignSeat)))
 
-	r.Handle("GET /manage/health", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	}))
+	r.Handle("GET /api/v1/airports", http.HandlerFunc(airportHandler.GetAll))
+	r.Handle("GET /api/v1/airports/{airportId}", http.HandlerFunc(airportHandler.Get))
+	r.Handle("POST /api/v1/airports", authManager.Auth(http.HandlerFunc(airportHandler.Create), models.RoleAdmin))
+	r.Handle("PATCH /api/v1/airports/{airportId}", authManager.Auth(http.HandlerFunc(airportHandler.Update), models.RoleAdmin))
+	r.Handle("DELETE /api/v1/airports/{airportId}", authManager.Auth(http.HandlerFunc(airportHandler.Delete), models.RoleAdmin))
 
 	router := middleware.AccessLog(logger, r)
 	router = middleware.Panic(logger, router)
 
-	s := server.NewServer(router)
+	s := server.NewServer(cfg.Server, router)
+
+	checker := health.New(health.DefaultTimeout).
+		Add("server", s.Check).
+		Add("postgres", func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		})
+
+	r.Handle("GET /manage/health", http.HandlerFunc(s.Health))
+	r.Handle("GET /manage/health/live", http.HandlerFunc(checker.Live))
+	r.Handle("GET /manage/health/ready", http.HandlerFunc(checker.Ready))
+
+	s.OnShutdown("logger sync", func(ctx context.Context) error {
+		return zapLogger.Sync()
+	})
+	s.OnShutdown("postgres", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+
 	if err := s.Start(); err != nil {
 		logger.Fatal(err)
 	}
-
-	err = zapLogger.Sync()
-	if err != nil {
-		fmt.Println(err)
-	}
 }
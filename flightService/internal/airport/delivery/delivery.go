@@ -0,0 +1,187 @@
+package delivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	airportRep "flight_booking_system/flightService/internal/airport/repository"
+	airportUseCase "flight_booking_system/flightService/internal/airport/usecase"
+	"flight_booking_system/flightService/models"
+	"flight_booking_system/flightService/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+type AirportHandler struct {
+	AirportUseCase airportUseCase.AirportUseCaseI
+	Logger         logger.Logger
+}
+
+func (ah *AirportHandler) airportID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	airportId, err := strconv.Atoi(r.PathValue("airportId"))
+	if err != nil {
+		ah.Logger.Infow("fail to convert id to int",
+			"err:", err.Error())
+		http.Error(w, "bad airport id", http.StatusBadRequest)
+		return 0, false
+	}
+
+	return airportId, true
+}
+
+func (ah *AirportHandler) readAirport(w http.ResponseWriter, r *http.Request) (*models.Airport, bool) {
+	airport := &models.Airport{}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		ah.Logger.Errorw("can`t read body of request",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return nil, false
+	}
+
+	err = r.Body.Close()
+	if err != nil {
+		ah.Logger.Errorw("can`t close body of request", "err:", err.Error())
+		http.Error(w, "close error", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	err = json.Unmarshal(body, airport)
+	if err != nil {
+		ah.Logger.Infow("can`t unmarshal form",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return airport, true
+}
+
+func (ah *AirportHandler) writeJSON(w http.ResponseWriter, res any) {
+	resp, err := json.Marshal(res)
+	if err != nil {
+		ah.Logger.Errorw("can`t marshal airport",
+			"err:", err.Error())
+		http.Error(w, "can`t make airport", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ah.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		return
+	}
+}
+
+func (ah *AirportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	airport, ok := ah.readAirport(w, r)
+	if !ok {
+		return
+	}
+	airport.ID = 0
+
+	err := ah.AirportUseCase.Create(airport)
+	if errors.Is(err, airportUseCase.ErrInvalidAirport) {
+		ah.Logger.Infow("can`t create airport",
+			"err:", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		ah.Logger.Errorw("can`t create airport",
+			"err:", err.Error())
+		http.Error(w, "can`t create airport", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/airports/%d", airport.ID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (ah *AirportHandler) Get(w http.ResponseWriter, r *http.Request) {
+	airportId, ok := ah.airportID(w, r)
+	if !ok {
+		return
+	}
+
+	airport, err := ah.AirportUseCase.Get(airportId)
+	if err != nil {
+		ah.Logger.Infow("can`t get airport",
+			"err:", err.Error())
+		http.Error(w, "can`t get airport", http.StatusNotFound)
+		return
+	}
+
+	ah.writeJSON(w, airport)
+}
+
+func (ah *AirportHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	airports, err := ah.AirportUseCase.GetAll()
+	if err != nil {
+		ah.Logger.Errorw("can`t get all airports",
+			"err:", err.Error())
+		http.Error(w, "can`t get all airports", http.StatusInternalServerError)
+		return
+	}
+
+	ah.writeJSON(w, airports)
+}
+
+func (ah *AirportHandler) Update(w http.ResponseWriter, r *http.Request) {
+	airportId, ok := ah.airportID(w, r)
+	if !ok {
+		return
+	}
+
+	airport, ok := ah.readAirport(w, r)
+	if !ok {
+		return
+	}
+
+	airport.ID = airportId
+	err := ah.AirportUseCase.Update(airport)
+	if errors.Is(err, airportUseCase.ErrInvalidAirport) {
+		ah.Logger.Infow("can`t update airport",
+			"err:", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		ah.Logger.Infow("can`t update airport",
+			"err:", err.Error())
+		http.Error(w, "can`t update airport", http.StatusNotFound)
+		return
+	}
+
+	ah.writeJSON(w, airport)
+}
+
+func (ah *AirportHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	airportId, ok := ah.airportID(w, r)
+	if !ok {
+		return
+	}
+
+	err := ah.AirportUseCase.Delete(airportId)
+	if errors.Is(err, airportRep.ErrAirportInUse) {
+		ah.Logger.Infow("can`t delete airport",
+			"err:", err.Error())
+		http.Error(w, "airport is used by flights", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		ah.Logger.Infow("can`t delete airport",
+			"err:", err.Error())
+		http.Error(w, "can`t delete airport", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"sync"
+
+	"flight_booking_system/flightService/internal/airport/repository"
+	"flight_booking_system/flightService/models"
+	"github.com/pkg/errors"
+)
+
+// airportCache — кэш аэропортов в памяти процесса поверх репозитория.
+// Аэропорты меняются редко, поэтому записи живут до изменения через этот же
+// репозиторий: Create, Update и Delete сбрасывают соответствующую запись.
+type airportCache struct {
+	repo repository.AirportRepositoryI
+
+	mu       sync.RWMutex
+	airports map[int]models.Airport
+	// version растёт при каждом изменении, чтобы загрузка, начатая до него,
+	// не положила в кэш устаревшие данные
+	version uint64
+}
+
+func New(repo repository.AirportRepositoryI) repository.AirportRepositoryI {
+	return &airportCache{
+		repo:     repo,
+		airports: make(map[int]models.Airport),
+	}
+}
+
+func (c *airportCache) currentVersion() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.version
+}
+
+func (c *airportCache) store(version uint64, airports ...*models.Airport) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.version {
+		return
+	}
+
+	for _, airport := range airports {
+		c.airports[airport.ID] = *airport
+	}
+}
+
+func (c *airportCache) invalidate(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	delete(c.airports, id)
+}
+
+func (c *airportCache) Create(p *models.Airport) error {
+	err := c.repo.Create(p)
+	if err != nil {
+		return errors.Wrap(err, "airportCache.Create error")
+	}
+
+	c.invalidate(p.ID)
+
+	return nil
+}
+
+func (c *airportCache) Get(id int) (*models.Airport, error) {
+	c.mu.RLock()
+	airport, ok := c.airports[id]
+	version := c.version
+	c.mu.RUnlock()
+
+	if ok {
+		return &airport, nil
+	}
+
+	resAirport, err := c.repo.Get(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "airportCache.Get error")
+	}
+
+	c.store(version, resAirport)
+
+	return resAirport, nil
+}
+
+func (c *airportCache) Update(p *models.Airport) error {
+	err := c.repo.Update(p)
+
+	c.invalidate(p.ID)
+
+	if err != nil {
+		return errors.Wrap(err, "airportCache.Update error")
+	}
+
+	return nil
+}
+
+func (c *airportCache) Delete(id int) error {
+	err := c.repo.Delete(id)
+
+	c.invalidate(id)
+
+	if err != nil {
+		return errors.Wrap(err, "airportCache.Delete error")
+	}
+
+	return nil
+}
+
+func (c *airportCache) GetAll() ([]*models.Airport, error) {
+	version := c.currentVersion()
+
+	airports, err := c.repo.GetAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "airportCache.GetAll error")
+	}
+
+	c.store(version, airports...)
+
+	return airports, nil
+}
+
+// GetByIDs отдаёт аэропорты из кэша и догружает недостающие одним запросом.
+func (c *airportCache) GetByIDs(ids []int) ([]*models.Airport, error) {
+	airports := make([]*models.Airport, 0, len(ids))
+	var missing []int
+
+	c.mu.RLock()
+	version := c.version
+	for _, id := range ids {
+		airport, ok := c.airports[id]
+		if ok {
+			airports = append(airports, &airport)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	c.mu.RUnlock()
+
+	if len(missing) == 0 {
+		return airports, nil
+	}
+
+	loaded, err := c.repo.GetByIDs(missing)
+	if err != nil {
+		return nil, errors.Wrap(err, "airportCache.GetByIDs error")
+	}
+
+	c.store(version, loaded...)
+
+	return append(airports, loaded...), nil
+}
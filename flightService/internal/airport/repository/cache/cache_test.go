@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"testing"
+
+	"flight_booking_system/flightService/internal/airport/repository"
+	airportMocks "flight_booking_system/flightService/internal/airport/repository/mocks"
+	"flight_booking_system/flightService/models"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+)
+
+type AirportCacheTestSuite struct {
+	suite.Suite
+	cache    repository.AirportRepositoryI
+	repoMock *airportMocks.AirportRepositoryI
+}
+
+func TestAirportCacheSuite(t *testing.T) {
+	suite.RunSuite(t, new(AirportCacheTestSuite))
+}
+
+func (s *AirportCacheTestSuite) BeforeEach(t provider.T) {
+	s.repoMock = airportMocks.NewAirportRepositoryI(t)
+	s.cache = New(s.repoMock)
+}
+
+func airport(id int, name string) *models.Airport {
+	return &models.Airport{ID: id, Name: name, City: "Москва", Country: "Россия"}
+}
+
+func (s *AirportCacheTestSuite) TestGetCached(t provider.T) {
+	s.repoMock.On("Get", 1).Return(airport(1, "Шереметьево"), nil).Once()
+
+	for i := 0; i < 2; i++ {
+		res, err := s.cache.Get(1)
+		t.Require().NoError(err)
+		t.Assert().Equal("Шереметьево", res.Name)
+	}
+}
+
+func (s *AirportCacheTestSuite) TestGetError(t provider.T) {
+	errRepo := errors.New("repo error")
+	s.repoMock.On("Get", 1).Return(nil, errRepo).Once()
+	s.repoMock.On("Get", 1).Return(airport(1, "Шереметьево"), nil).Once()
+
+	_, err := s.cache.Get(1)
+	t.Assert().ErrorIs(err, errRepo)
+
+	res, err := s.cache.Get(1)
+	t.Require().NoError(err)
+	t.Assert().Equal("Шереметьево", res.Name)
+}
+
+func (s *AirportCacheTestSuite) TestWriteInvalidates(t provider.T) {
+	cases := map[string]struct {
+		Write func() error
+	}{
+		"update": {
+			Write: func() error {
+				s.repoMock.On("Update", mock.Anything).Return(nil)
+				return s.cache.Update(airport(1, "Внуково"))
+			},
+		},
+		"failed update": {
+			// Запись в базе могла измениться и при ошибке
+			Write: func() error {
+				s.repoMock.On("Update", mock.Anything).Return(errors.New("repo error"))
+				_ = s.cache.Update(airport(1, "Внуково"))
+				return nil
+			},
+		},
+		"delete": {
+			Write: func() error {
+				s.repoMock.On("Delete", 1).Return(nil)
+				return s.cache.Delete(1)
+			},
+		},
+		"create": {
+			Write: func() error {
+				s.repoMock.On("Create", mock.Anything).Return(nil)
+				return s.cache.Create(airport(1, "Внуково"))
+			},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			s.BeforeEach(t)
+			s.repoMock.On("Get", 1).Return(airport(1, "Шереметьево"), nil).Once()
+			s.repoMock.On("Get", 1).Return(airport(1, "Внуково"), nil).Once()
+
+			res, err := s.cache.Get(1)
+			t.Require().NoError(err)
+			t.Require().Equal("Шереметьево", res.Name)
+
+			t.Require().NoError(test.Write())
+
+			res, err = s.cache.Get(1)
+			t.Require().NoError(err)
+			t.Assert().Equal("Внуково", res.Name)
+		})
+	}
+}
+
+func (s *AirportCacheTestSuite) TestStaleGetNotStored(t provider.T) {
+	s.repoMock.On("Update", mock.Anything).Return(nil)
+	// Пока загрузка идёт, аэропорт меняют: прочитанная версия уже устарела
+	s.repoMock.On("Get", 1).Return(airport(1, "Шереметьево"), nil).Once().
+		Run(func(args mock.Arguments) {
+			_ = s.cache.Update(airport(1, "Внуково"))
+		})
+	s.repoMock.On("Get", 1).Return(airport(1, "Внуково"), nil).Once()
+
+	res, err := s.cache.Get(1)
+	t.Require().NoError(err)
+	t.Assert().Equal("Шереметьево", res.Name)
+
+	res, err = s.cache.Get(1)
+	t.Require().NoError(err)
+	t.Assert().Equal("Внуково", res.Name)
+}
+
+func (s *AirportCacheTestSuite) TestStaleGetAllNotStored(t provider.T) {
+	s.repoMock.On("Delete", 2).Return(nil)
+	s.repoMock.On("GetAll").Return([]*models.Airport{airport(1, "Шереметьево"), airport(2, "Пулково")}, nil).Once().
+		Run(func(args mock.Arguments) {
+			_ = s.cache.Delete(2)
+		})
+	s.repoMock.On("GetByIDs", []int{1, 2}).Return([]*models.Airport{airport(1, "Шереметьево")}, nil).Once()
+
+	_, err := s.cache.GetAll()
+	t.Require().NoError(err)
+
+	res, err := s.cache.GetByIDs([]int{1, 2})
+	t.Require().NoError(err)
+	t.Assert().Len(res, 1)
+}
+
+func (s *AirportCacheTestSuite) TestGetByIDsLoadsMissing(t provider.T) {
+	s.repoMock.On("GetAll").Return([]*models.Airport{airport(1, "Шереметьево")}, nil).Once()
+	s.repoMock.On("GetByIDs", []int{2}).Return([]*models.Airport{airport(2, "Пулково")}, nil).Once()
+
+	_, err := s.cache.GetAll()
+	t.Require().NoError(err)
+
+	res, err := s.cache.GetByIDs([]int{1, 2})
+	t.Require().NoError(err)
+	t.Require().Len(res, 2)
+	t.Assert().Equal("Шереметьево", res[0].Name)
+	t.Assert().Equal("Пулково", res[1].Name)
+
+	// Теперь оба аэропорта в кэше
+	res, err = s.cache.GetByIDs([]int{2, 1})
+	t.Require().NoError(err)
+	t.Assert().Len(res, 2)
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"flight_booking_system/flightService/internal/airport/repository"
+	"flight_booking_system/flightService/models"
+	"flight_booking_system/flightService/pkg/logger"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type pgAirportRepo struct {
+	Logger logger.Logger
+	DB     *gorm.DB
+}
+
+func New(logger logger.Logger, db *gorm.DB) repository.AirportRepositoryI {
+	return &pgAirportRepo{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (pr *pgAirportRepo) Create(p *models.Airport) error {
+	tx := pr.DB.Create(p)
+
+	if tx.Error != nil {
+		return errors.Wrap(tx.Error, "pgAirportRepo.Create error while inserting in repo")
+	}
+
+	return nil
+}
+
+func (pr *pgAirportRepo) Get(id int) (*models.Airport, error) {
+	var p models.Airport
+	tx := pr.DB.Where("id = ?", id).Take(&p)
+
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgAirportRepo.Get error")
+	}
+
+	return &p, nil
+}
+
+func (pr *pgAirportRepo) Update(p *models.Airport) error {
+	tx := pr.DB.Clauses(clause.Returning{}).Omit("id").Updates(p)
+
+	if tx.Error != nil {
+		return errors.Wrap(tx.Error, "pgAirportRepo.Update error while inserting in repo")
+	}
+
+	return nil
+}
+
+func (pr *pgAirportRepo) Delete(id int) error {
+	tx := pr.DB.Delete(&models.Airport{}, id)
+
+	if errors.Is(tx.Error, gorm.ErrForeignKeyViolated) {
+		return errors.Wrap(repository.ErrAirportInUse, "pgAirportRepo.Delete error")
+	}
+	if tx.Error != nil {
+		return errors.Wrap(tx.Error, "pgAirportRepo.Delete error")
+	}
+
+	return nil
+}
+
+func (pr *pgAirportRepo) GetAll() ([]*models.Airport, error) {
+	var airports []*models.Airport
+
+	tx := pr.DB.Order("id").Find(&airports)
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgAirportRepo.GetAll error")
+	}
+
+	return airports, nil
+}
+
+func (pr *pgAirportRepo) GetByIDs(ids []int) ([]*models.Airport, error) {
+	var airports []*models.Airport
+
+	tx := pr.DB.Where("id IN ?", ids).Find(&airports)
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgAirportRepo.GetByIDs error")
+	}
+
+	return airports, nil
+}
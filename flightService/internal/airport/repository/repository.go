@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"flight_booking_system/flightService/models"
+	"github.com/pkg/errors"
+)
+
+// ErrAirportInUse — на аэропорт ссылаются рейсы, удалить его нельзя.
+var ErrAirportInUse = errors.New("airport is used by flights")
+
+type AirportRepositoryI interface {
+	Create(p *models.Airport) error
+	Get(id int) (*models.Airport, error)
+	Update(p *models.Airport) error
+	Delete(id int) error
+	GetAll() ([]*models.Airport, error)
+	GetByIDs(ids []int) ([]*models.Airport, error)
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	airportRep "flight_booking_system/flightService/internal/airport/repository"
+	"flight_booking_system/flightService/models"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidAirport = errors.New("invalid airport")
+
+type AirportUseCaseI interface {
+	Create(p *models.Airport) error
+	Get(id int) (*models.Airport, error)
+	Update(p *models.Airport) error
+	Delete(id int) error
+	GetAll() ([]*models.Airport, error)
+}
+
+type airportUseCase struct {
+	airportRepository airportRep.AirportRepositoryI
+}
+
+func New(aRep airportRep.AirportRepositoryI) AirportUseCaseI {
+	return &airportUseCase{
+		airportRepository: aRep,
+	}
+}
+
+func validateAirport(p *models.Airport) error {
+	switch {
+	case p.Name == "":
+		return errors.Wrap(ErrInvalidAirport, "name is required")
+	case p.City == "":
+		return errors.Wrap(ErrInvalidAirport, "city is required")
+	case p.Country == "":
+		return errors.Wrap(ErrInvalidAirport, "country is required")
+	}
+
+	return nil
+}
+
+func (pUC *airportUseCase) Create(p *models.Airport) error {
+	err := validateAirport(p)
+	if err != nil {
+		return err
+	}
+
+	err = pUC.airportRepository.Create(p)
+	if err != nil {
+		return errors.Wrap(err, "airportUseCase.Create error")
+	}
+
+	return nil
+}
+
+func (pUC *airportUseCase) Get(id int) (*models.Airport, error) {
+	resAirport, err := pUC.airportRepository.Get(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "airportUseCase.Get error")
+	}
+
+	return resAirport, nil
+}
+
+// Update меняет только переданные поля, проверяется итоговый аэропорт.
+func (pUC *airportUseCase) Update(p *models.Airport) error {
+	existing, err := pUC.airportRepository.Get(p.ID)
+	if err != nil {
+		return errors.Wrap(err, "airportUseCase.Update error: Airport not found")
+	}
+
+	merged := *existing
+	if p.Name != "" {
+		merged.Name = p.Name
+	}
+	if p.City != "" {
+		merged.City = p.City
+	}
+	if p.Country != "" {
+		merged.Country = p.Country
+	}
+
+	err = validateAirport(&merged)
+	if err != nil {
+		return err
+	}
+
+	*p = merged
+	err = pUC.airportRepository.Update(p)
+	if err != nil {
+		return errors.Wrap(err, "airportUseCase.Update error: Can't update in repo")
+	}
+
+	return nil
+}
+
+func (pUC *airportUseCase) Delete(id int) error {
+	_, err := pUC.airportRepository.Get(id)
+	if err != nil {
+		return errors.Wrap(err, "airportUseCase.Delete error: Airport not found")
+	}
+
+	err = pUC.airportRepository.Delete(id)
+	if err != nil {
+		return errors.Wrap(err, "airportUseCase.Delete error: Can't delete in repo")
+	}
+
+	return nil
+}
+
+func (pUC *airportUseCase) GetAll() ([]*models.Airport, error) {
+	airports, err := pUC.airportRepository.GetAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "airportUseCase.GetAll error")
+	}
+
+	return airports, nil
+}
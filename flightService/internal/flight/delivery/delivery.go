@@ -2,15 +2,19 @@ package delivery
 
 import (
 	"encoding/json"
+	flightRep "flight_booking_system/flightService/internal/flight/repository"
 	flightUseCase "flight_booking_system/flightService/internal/flight/usecase"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"flight_booking_system/flightService/models"
 	"flight_booking_system/flightService/pkg/logger"
 	//"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
 )
 
 type FlightHandler struct {
@@ -52,7 +56,14 @@ func (ah *FlightHandler) Create(w http.ResponseWriter, r *http.Request) {
 	//	return
 	//}
 
+	flight.ID = 0
 	err = ah.FlightUseCase.Create(&flight)
+	if errors.Is(err, flightUseCase.ErrInvalidFlight) {
+		ah.Logger.Infow("can`t create flight",
+			"err:", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		ah.Logger.Infow("can`t create flight",
 			"err:", err.Error())
@@ -176,6 +187,12 @@ func (ah *FlightHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	flight.ID = flightId
 	err = ah.FlightUseCase.Update(flight)
+	if errors.Is(err, flightUseCase.ErrInvalidFlight) {
+		ah.Logger.Infow("can`t update flight",
+			"err:", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		ah.Logger.Infow("can`t update flight",
 			"err:", err.Error())
@@ -205,6 +222,76 @@ func (ah *FlightHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// UpdateStatus меняет оперативный статус рейса, расчётное время и выход.
+func (ah *FlightHandler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	flightId, err := strconv.Atoi(r.PathValue("flightId"))
+	if err != nil {
+		ah.Logger.Infow("fail to convert id to int",
+			"err:", err.Error())
+		http.Error(w, "bad flight id", http.StatusBadRequest)
+		return
+	}
+
+	update := models.FlightStatusUpdate{}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		ah.Logger.Errorw("can`t read body of request",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	err = r.Body.Close()
+	if err != nil {
+		ah.Logger.Errorw("can`t close body of request", "err:", err.Error())
+		http.Error(w, "close error", http.StatusInternalServerError)
+		return
+	}
+
+	err = json.Unmarshal(body, &update)
+	if err != nil {
+		ah.Logger.Infow("can`t unmarshal form",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	flight, err := ah.FlightUseCase.UpdateStatus(flightId, update)
+	if err != nil {
+		ah.Logger.Infow("can`t update flight status",
+			"err:", err.Error())
+		switch {
+		case errors.Is(err, flightUseCase.ErrInvalidFlight):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, flightUseCase.ErrIllegalStatus):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "can`t update flight status", http.StatusNotFound)
+		}
+		return
+	}
+
+	resp, err := json.Marshal(flight)
+	if err != nil {
+		ah.Logger.Errorw("can`t marshal flight",
+			"err:", err.Error())
+		http.Error(w, "can`t make flight", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ah.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		http.Error(w, "can`t write response", http.StatusInternalServerError)
+		return
+	}
+}
+
 func (ah *FlightHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	flightIdString := r.PathValue("flightId")
 	if flightIdString == "" {
@@ -316,3 +403,439 @@ func (ah *FlightHandler) GetAllPaginate(w http.ResponseWriter, r *http.Request)
 		return
 	}
 }
+
+const maxBatchSize = 100
+
+// GetBatch возвращает рейсы по нескольким номерам за один запрос:
+// /api/v1/flightsBatch?flightNumber=A&flightNumber=B
+func (ah *FlightHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	flightNumbers := r.URL.Query()["flightNumber"]
+	if len(flightNumbers) > maxBatchSize {
+		ah.Logger.Infow("too many flight numbers in batch",
+			"count", len(flightNumbers))
+		http.Error(w, fmt.Sprintf("no more than %d flight numbers allowed", maxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	flights, err := ah.FlightUseCase.GetBatch(flightNumbers)
+	if err != nil {
+		ah.Logger.Infow("can`t get flights batch",
+			"err:", err.Error())
+		http.Error(w, "can`t get flights batch", http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(flights)
+	if err != nil {
+		ah.Logger.Errorw("can`t marshal flight",
+			"err:", err.Error())
+		http.Error(w, "can`t make flight", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ah.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		http.Error(w, "can`t write response", http.StatusInternalServerError)
+		return
+	}
+}
+
+const dateLayout = "2006-01-02"
+
+// parseDate принимает дату (2006-01-02) или момент времени в RFC3339.
+// Дата без времени в качестве верхней границы включает весь день.
+func parseDate(value string, endOfDay bool) (time.Time, error) {
+	t, err := time.Parse(dateLayout, value)
+	if err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func parseFlightFilter(q url.Values) (models.FlightFilter, error) {
+	filter := models.FlightFilter{
+		FromCity:    q.Get("fromCity"),
+		ToCity:      q.Get("toCity"),
+		FromCountry: q.Get("fromCountry"),
+		ToCountry:   q.Get("toCountry"),
+		SortBy:      q.Get("sort"),
+	}
+
+	var err error
+	for name, dst := range map[string]*int{
+		"fromAirportId": &filter.FromAirportID,
+		"toAirportId":   &filter.ToAirportID,
+		"maxPrice":      &filter.MaxPrice,
+	} {
+		if value := q.Get(name); value != "" {
+			*dst, err = strconv.Atoi(value)
+			if err != nil {
+				return filter, errors.Errorf("bad %s", name)
+			}
+		}
+	}
+
+	if value := q.Get("dateFrom"); value != "" {
+		filter.DateFrom, err = parseDate(value, false)
+		if err != nil {
+			return filter, errors.New("bad dateFrom")
+		}
+	}
+
+	if value := q.Get("dateTo"); value != "" {
+		filter.DateTo, err = parseDate(value, true)
+		if err != nil {
+			return filter, errors.New("bad dateTo")
+		}
+	}
+
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		filter.SortDesc = true
+	default:
+		return filter, errors.New("bad order")
+	}
+
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	size, _ := strconv.Atoi(q.Get("size"))
+	switch {
+	case size <= 0:
+		size = 10
+	case size > 100:
+		size = 100
+	}
+
+	filter.Offset = (page - 1) * size
+	filter.Limit = size
+
+	return filter, nil
+}
+
+// Search ищет рейсы по маршруту, датам и цене:
+// /api/v1/flightsSearch?fromCity=Москва&dateFrom=2024-10-01&maxPrice=2000&sort=price&order=asc&page=1&size=10
+func (ah *FlightHandler) Search(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFlightFilter(r.URL.Query())
+	if err != nil {
+		ah.Logger.Infow("can`t parse flight filter",
+			"err:", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	flightsPage, err := ah.FlightUseCase.Search(filter)
+	if err != nil {
+		ah.Logger.Infow("can`t search flights",
+			"err:", err.Error())
+		if errors.Is(err, flightUseCase.ErrInvalidFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "can`t search flights", http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(flightsPage)
+	if err != nil {
+		ah.Logger.Errorw("can`t marshal flights page",
+			"err:", err.Error())
+		http.Error(w, "can`t make flights page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ah.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		http.Error(w, "can`t write response", http.StatusInternalServerError)
+		return
+	}
+}
+
+func parseRouteQuery(q url.Values) (models.RouteQuery, error) {
+	query := models.RouteQuery{
+		FareClass:      q.Get("fareClass"),
+		SortBy:         q.Get("sort"),
+		MaxConnections: flightUseCase.DefaultMaxConnections,
+	}
+
+	var err error
+	var minConnection, maxConnection int
+	for name, dst := range map[string]*int{
+		"fromAirportId":        &query.FromAirportID,
+		"toAirportId":          &query.ToAirportID,
+		"maxConnections":       &query.MaxConnections,
+		"minConnectionMinutes": &minConnection,
+		"maxConnectionMinutes": &maxConnection,
+		"limit":                &query.Limit,
+	} {
+		if value := q.Get(name); value != "" {
+			*dst, err = strconv.Atoi(value)
+			if err != nil {
+				return query, errors.Errorf("bad %s", name)
+			}
+		}
+	}
+	query.MinConnection = time.Duration(minConnection) * time.Minute
+	query.MaxConnection = time.Duration(maxConnection) * time.Minute
+
+	query.Date, err = time.Parse(dateLayout, q.Get("date"))
+	if err != nil {
+		return query, errors.New("bad date")
+	}
+
+	return query, nil
+}
+
+// FindRoutes ищет маршруты с пересадками на дату вылета:
+// /api/v1/routes?fromAirportId=2&toAirportId=1&date=2024-10-01&maxConnections=1&minConnectionMinutes=60&sort=duration
+func (ah *FlightHandler) FindRoutes(w http.ResponseWriter, r *http.Request) {
+	query, err := parseRouteQuery(r.URL.Query())
+	if err != nil {
+		ah.Logger.Infow("can`t parse route query",
+			"err:", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	routes, err := ah.FlightUseCase.FindRoutes(query)
+	if err != nil {
+		ah.Logger.Infow("can`t find routes",
+			"err:", err.Error())
+		if errors.Is(err, flightUseCase.ErrInvalidFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "can`t find routes", http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(routes)
+	if err != nil {
+		ah.Logger.Errorw("can`t marshal routes",
+			"err:", err.Error())
+		http.Error(w, "can`t make routes", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ah.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		return
+	}
+}
+
+type seatReservationRequest struct {
+	TicketUID string `json:"ticketUid"`
+	FareClass string `json:"fareClass"`
+}
+
+// ReserveSeat занимает место на рейсе под билет. 409 — мест не осталось.
+func (ah *FlightHandler) ReserveSeat(w http.ResponseWriter, r *http.Request) {
+	flightId, err := strconv.Atoi(r.PathValue("flightId"))
+	if err != nil {
+		ah.Logger.Infow("fail to convert id to int",
+			"err:", err.Error())
+		http.Error(w, "bad flight id", http.StatusBadRequest)
+		return
+	}
+
+	reservation := seatReservationRequest{}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		ah.Logger.Errorw("can`t read body of request",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	err = r.Body.Close()
+	if err != nil {
+		ah.Logger.Errorw("can`t close body of request", "err:", err.Error())
+		http.Error(w, "close error", http.StatusInternalServerError)
+		return
+	}
+
+	err = json.Unmarshal(body, &reservation)
+	if err != nil {
+		ah.Logger.Infow("can`t unmarshal form",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	err = ah.FlightUseCase.ReserveSeat(flightId, reservation.FareClass, reservation.TicketUID)
+	if err != nil {
+		ah.Logger.Infow("can`t reserve seat",
+			"err:", err.Error())
+		switch {
+		case errors.Is(err, flightUseCase.ErrInvalidFlight):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, flightRep.ErrFlightNotFound):
+			http.Error(w, "flight not found", http.StatusNotFound)
+		case errors.Is(err, flightRep.ErrFareNotFound):
+			http.Error(w, "fare class is not sold on this flight", http.StatusNotFound)
+		case errors.Is(err, flightRep.ErrSoldOut):
+			http.Error(w, "flight is sold out", http.StatusConflict)
+		case errors.Is(err, flightRep.ErrFlightClosed):
+			http.Error(w, "flight is cancelled or departed", http.StatusConflict)
+		default:
+			http.Error(w, "can`t reserve seat", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/reservations/%s", reservation.TicketUID))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// GetFares отдаёт классы обслуживания рейса с ценами и остатком мест.
+func (ah *FlightHandler) GetFares(w http.ResponseWriter, r *http.Request) {
+	flightId, err := strconv.Atoi(r.PathValue("flightId"))
+	if err != nil {
+		ah.Logger.Infow("fail to convert id to int",
+			"err:", err.Error())
+		http.Error(w, "bad flight id", http.StatusBadRequest)
+		return
+	}
+
+	fares, err := ah.FlightUseCase.GetFares(flightId)
+	if errors.Is(err, flightRep.ErrFlightNotFound) {
+		ah.Logger.Infow("can`t get fares",
+			"err:", err.Error())
+		http.Error(w, "flight not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		ah.Logger.Errorw("can`t get fares",
+			"err:", err.Error())
+		http.Error(w, "can`t get fares", http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(fares)
+	if err != nil {
+		ah.Logger.Errorw("can`t marshal fares",
+			"err:", err.Error())
+		http.Error(w, "can`t make fares", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ah.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		http.Error(w, "can`t write response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// SaveFare создаёт или меняет класс обслуживания рейса.
+func (ah *FlightHandler) SaveFare(w http.ResponseWriter, r *http.Request) {
+	flightId, err := strconv.Atoi(r.PathValue("flightId"))
+	if err != nil {
+		ah.Logger.Infow("fail to convert id to int",
+			"err:", err.Error())
+		http.Error(w, "bad flight id", http.StatusBadRequest)
+		return
+	}
+
+	fare := models.Fare{}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		ah.Logger.Errorw("can`t read body of request",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	err = r.Body.Close()
+	if err != nil {
+		ah.Logger.Errorw("can`t close body of request", "err:", err.Error())
+		http.Error(w, "close error", http.StatusInternalServerError)
+		return
+	}
+
+	err = json.Unmarshal(body, &fare)
+	if err != nil {
+		ah.Logger.Infow("can`t unmarshal form",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	fare.FlightID = flightId
+	fare.FareClass = r.PathValue("fareClass")
+
+	err = ah.FlightUseCase.SaveFare(&fare)
+	if err != nil {
+		ah.Logger.Infow("can`t save fare",
+			"err:", err.Error())
+		switch {
+		case errors.Is(err, flightUseCase.ErrInvalidFlight):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, flightRep.ErrFlightNotFound):
+			http.Error(w, "flight not found", http.StatusNotFound)
+		default:
+			http.Error(w, "can`t save fare", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resp, err := json.Marshal(models.FareToDTO(&fare))
+	if err != nil {
+		ah.Logger.Errorw("can`t marshal fare",
+			"err:", err.Error())
+		http.Error(w, "can`t make fare", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ah.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		http.Error(w, "can`t write response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// ReleaseSeat освобождает место билета; повторный вызов тоже отвечает 204.
+func (ah *FlightHandler) ReleaseSeat(w http.ResponseWriter, r *http.Request) {
+	err := ah.FlightUseCase.ReleaseSeat(r.PathValue("ticketUid"))
+	if err != nil {
+		ah.Logger.Errorw("can`t release seat",
+			"err:", err.Error())
+		http.Error(w, "can`t release seat", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
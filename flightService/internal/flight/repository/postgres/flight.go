@@ -1,6 +1,9 @@
 package postgres
 
 import (
+	"time"
+
+	airportRep "flight_booking_system/flightService/internal/airport/repository"
 	"flight_booking_system/flightService/internal/flight/repository"
 	"flight_booking_system/flightService/models"
 	"flight_booking_system/flightService/pkg/logger"
@@ -10,14 +13,18 @@ import (
 )
 
 type pgFlightRepo struct {
-	Logger logger.Logger
-	DB     *gorm.DB
+	Logger   logger.Logger
+	DB       *gorm.DB
+	Airports airportRep.AirportRepositoryI
 }
 
-func New(logger logger.Logger, db *gorm.DB) repository.FlightRepositoryI {
+// New принимает репозиторий аэропортов (обычно кэширующий), через который
+// рейсы дополняются названиями аэропортов.
+func New(logger logger.Logger, db *gorm.DB, airports airportRep.AirportRepositoryI) repository.FlightRepositoryI {
 	return &pgFlightRepo{
-		Logger: logger,
-		DB:     db,
+		Logger:   logger,
+		DB:       db,
+		Airports: airports,
 	}
 }
 
@@ -42,11 +49,42 @@ func (pr *pgFlightRepo) Get(id int) (*models.Flight, error) {
 	return &p, nil
 }
 
+// Update не трогает seats_reserved, вместимость, классы обслуживания и
+// оперативный статус: число занятых мест меняют только ReserveSeat и
+// ReleaseSeat, вместимость и классы — SaveFare, статус — UpdateStatus. Цена рейса — это цена эконом-класса,
+// поэтому она меняется в нём в той же транзакции.
 func (pr *pgFlightRepo) Update(p *models.Flight) error {
-	tx := pr.DB.Clauses(clause.Returning{}).Omit("id").Updates(p)
+	err := pr.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.Returning{}).
+			Omit("id", "capacity", "seats_reserved", "status", "estimated_departure", "estimated_arrival", "gate", clause.Associations).
+			Updates(p)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		return tx.Model(&models.Fare{}).
+			Where("flight_id = ? AND fare_class = ?", p.ID, models.FareEconomy).
+			Update("price", p.Price).Error
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "pgFlightRepo.Update error while inserting in repo")
+	}
+
+	return nil
+}
+
+// UpdateStatus пишет и пустые значения: так сбрасываются расчётное время и выход.
+func (pr *pgFlightRepo) UpdateStatus(p *models.Flight) error {
+	tx := pr.DB.Model(p).
+		Select("status", "estimated_departure", "estimated_arrival", "gate").
+		Updates(p)
 
 	if tx.Error != nil {
-		return errors.Wrap(tx.Error, "pgFlightRepo.Update error while inserting in repo")
+		return errors.Wrap(tx.Error, "pgFlightRepo.UpdateStatus error")
+	}
+	if tx.RowsAffected == 0 {
+		return errors.Wrap(repository.ErrFlightNotFound, "pgFlightRepo.UpdateStatus error")
 	}
 
 	return nil
@@ -62,25 +100,95 @@ func (pr *pgFlightRepo) Delete(id int) error {
 	return nil
 }
 
-func (pr *pgFlightRepo) getFlightDTOs(flights []*models.Flight) (ret []*models.FlightDTO, err error) {
+// availableSeats возвращает число свободных мест рейсов по квотам их классов
+// обслуживания.
+func (pr *pgFlightRepo) availableSeats(flights []*models.Flight) (map[int]int, error) {
+	if len(flights) == 0 {
+		return nil, nil
+	}
+
+	flightIDs := make([]int, 0, len(flights))
+	for _, flight := range flights {
+		flightIDs = append(flightIDs, flight.ID)
+	}
+
+	var seats []struct {
+		FlightID  int
+		Available int
+	}
+	tx := pr.DB.Model(&models.Fare{}).
+		Select("flight_id, SUM(capacity - seats_reserved) AS available").
+		Where("flight_id IN ?", flightIDs).
+		Group("flight_id").
+		Find(&seats)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	res := make(map[int]int, len(seats))
+	for _, s := range seats {
+		res[s.FlightID] = s.Available
+	}
+
+	return res, nil
+}
+
+// getFlightDTOs загружает аэропорты всех рейсов одним запросом (или берёт
+// из кэша), а не по два запроса на каждый рейс. Свободные места считаются по
+// классам обслуживания так же, как их продаёт ReserveSeat.
+func (pr *pgFlightRepo) getFlightDTOs(flights []*models.Flight) ([]*models.FlightDTO, error) {
+	available, err := pr.availableSeats(flights)
+	if err != nil {
+		return nil, errors.Wrap(err, "pgFlightRepo.getFlightDTOs error")
+	}
+
+	ids := make([]int, 0, 2*len(flights))
+	seen := make(map[int]struct{}, 2*len(flights))
+	for _, flight := range flights {
+		for _, id := range []int{flight.FromAirportID, flight.ToAirportID} {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	airportsByID := make(map[int]*models.Airport, len(ids))
+	if len(ids) > 0 {
+		airports, err := pr.Airports.GetByIDs(ids)
+		if err != nil {
+			return nil, errors.Wrap(err, "pgFlightRepo.getAirports error")
+		}
+
+		for _, airport := range airports {
+			airportsByID[airport.ID] = airport
+		}
+	}
+
+	ret := make([]*models.FlightDTO, 0, len(flights))
 	for _, flight := range flights {
-		var fromA, toA models.Airport
-		tx := pr.DB.Where("id = ?", flight.FromAirportID).Take(&fromA)
-		if tx.Error != nil {
-			return nil, errors.Wrap(tx.Error, "pgFlightRepo.getAirports error")
+		fromA, ok := airportsByID[flight.FromAirportID]
+		if !ok {
+			return nil, errors.Errorf("pgFlightRepo.getAirports error: airport %d not found", flight.FromAirportID)
 		}
 
-		tx = pr.DB.Where("id = ?", flight.ToAirportID).Take(&toA)
-		if tx.Error != nil {
-			return nil, errors.Wrap(tx.Error, "pgFlightRepo.getAirports error")
+		toA, ok := airportsByID[flight.ToAirportID]
+		if !ok {
+			return nil, errors.Errorf("pgFlightRepo.getAirports error: airport %d not found", flight.ToAirportID)
 		}
 
 		ret = append(ret, &models.FlightDTO{
-			FlightNumber: flight.FlightNumber,
-			Date:         flight.DateTime,
-			FromAirport:  fromA.City + " " + fromA.Name,
-			ToAirport:    toA.City + " " + toA.Name,
-			Price:        flight.Price,
+			ID:                 flight.ID,
+			FlightNumber:       flight.FlightNumber,
+			Date:               flight.DateTime,
+			FromAirport:        fromA.City + " " + fromA.Name,
+			ToAirport:          toA.City + " " + toA.Name,
+			Price:              flight.Price,
+			AvailableSeats:     available[flight.ID],
+			Status:             flight.Status,
+			EstimatedDeparture: flight.EstimatedDeparture,
+			EstimatedArrival:   flight.EstimatedArrival,
+			Gate:               flight.Gate,
 		})
 	}
 
@@ -97,7 +205,7 @@ func (pr *pgFlightRepo) GetAll() ([]*models.FlightDTO, error) {
 
 	flightDTOs, err := pr.getFlightDTOs(flights)
 	if err != nil {
-		return nil, errors.Wrap(tx.Error, "pgFlightRepo.GetAll error")
+		return nil, errors.Wrap(err, "pgFlightRepo.GetAll error")
 	}
 
 	return flightDTOs, nil
@@ -114,7 +222,24 @@ func (pr *pgFlightRepo) GetAllByFlightNumber(flightNumber string) ([]*models.Fli
 
 	flightDTOs, err := pr.getFlightDTOs(flights)
 	if err != nil {
-		return nil, errors.Wrap(tx.Error, "pgFlightRepo.GetAll error")
+		return nil, errors.Wrap(err, "pgFlightRepo.GetAll error")
+	}
+
+	return flightDTOs, nil
+}
+
+func (pr *pgFlightRepo) GetAllByFlightNumbers(flightNumbers []string) ([]*models.FlightDTO, error) {
+	var flights []*models.Flight
+
+	tx := pr.DB.Where("flight_number IN ?", flightNumbers).Find(&flights)
+
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgFlightRepo.GetAllByFlightNumbers error")
+	}
+
+	flightDTOs, err := pr.getFlightDTOs(flights)
+	if err != nil {
+		return nil, errors.Wrap(err, "pgFlightRepo.GetAllByFlightNumbers error")
 	}
 
 	return flightDTOs, nil
@@ -131,8 +256,237 @@ func (pr *pgFlightRepo) GetAllPaginate(offset, limit int) ([]*models.FlightDTO,
 
 	flightDTOs, err := pr.getFlightDTOs(flights)
 	if err != nil {
-		return nil, errors.Wrap(tx.Error, "pgFlightRepo.GetAll error")
+		return nil, errors.Wrap(err, "pgFlightRepo.GetAll error")
 	}
 
 	return flightDTOs, nil
 }
+
+var sortColumns = map[string]string{
+	models.SortByDate:  "flight.datetime",
+	models.SortByPrice: "flight.price",
+}
+
+func (pr *pgFlightRepo) searchQuery(filter models.FlightFilter) *gorm.DB {
+	q := pr.DB.Model(&models.Flight{}).
+		Joins(`JOIN airport from_airport ON from_airport.id = flight.from_airport_id`).
+		Joins(`JOIN airport to_airport ON to_airport.id = flight.to_airport_id`)
+
+	if filter.FromAirportID != 0 {
+		q = q.Where("flight.from_airport_id = ?", filter.FromAirportID)
+	}
+	if filter.ToAirportID != 0 {
+		q = q.Where("flight.to_airport_id = ?", filter.ToAirportID)
+	}
+	if filter.FromCity != "" {
+		q = q.Where("from_airport.city = ?", filter.FromCity)
+	}
+	if filter.ToCity != "" {
+		q = q.Where("to_airport.city = ?", filter.ToCity)
+	}
+	if filter.FromCountry != "" {
+		q = q.Where("from_airport.country = ?", filter.FromCountry)
+	}
+	if filter.ToCountry != "" {
+		q = q.Where("to_airport.country = ?", filter.ToCountry)
+	}
+	if !filter.DateFrom.IsZero() {
+		q = q.Where("flight.datetime >= ?", filter.DateFrom)
+	}
+	if !filter.DateTo.IsZero() {
+		q = q.Where("flight.datetime <= ?", filter.DateTo)
+	}
+	if filter.MaxPrice != 0 {
+		q = q.Where("flight.price <= ?", filter.MaxPrice)
+	}
+
+	return q
+}
+
+// Search возвращает страницу рейсов по фильтру и общее число подходящих рейсов.
+func (pr *pgFlightRepo) Search(filter models.FlightFilter) ([]*models.FlightDTO, int64, error) {
+	var total int64
+	tx := pr.searchQuery(filter).Count(&total)
+	if tx.Error != nil {
+		return nil, 0, errors.Wrap(tx.Error, "pgFlightRepo.Search error while counting")
+	}
+
+	column, ok := sortColumns[filter.SortBy]
+	if !ok {
+		column = sortColumns[models.SortByDate]
+	}
+
+	var flights []*models.Flight
+	tx = pr.searchQuery(filter).Select("flight.*").
+		Order(clause.OrderByColumn{Column: clause.Column{Name: column, Raw: true}, Desc: filter.SortDesc}).
+		Order("flight.id").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&flights)
+	if tx.Error != nil {
+		return nil, 0, errors.Wrap(tx.Error, "pgFlightRepo.Search error")
+	}
+
+	flightDTOs, err := pr.getFlightDTOs(flights)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "pgFlightRepo.Search error")
+	}
+
+	return flightDTOs, total, nil
+}
+
+func (pr *pgFlightRepo) GetDepartures(from, to time.Time) ([]*models.Flight, error) {
+	var flights []*models.Flight
+
+	tx := pr.DB.Preload("Fares").
+		Where("datetime >= ? AND datetime < ?", from, to).
+		Order("datetime").Order("id").
+		Find(&flights)
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgFlightRepo.GetDepartures error")
+	}
+
+	return flights, nil
+}
+
+func (pr *pgFlightRepo) GetFares(flightID int) ([]*models.Fare, error) {
+	var fares []*models.Fare
+
+	tx := pr.DB.Where("flight_id = ?", flightID).Order("price").Find(&fares)
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgFlightRepo.GetFares error")
+	}
+
+	return fares, nil
+}
+
+// SaveFare создаёт класс обслуживания или меняет его цену, квоту и правила.
+// Число проданных мест не перезаписывается. Вместимость рейса пересчитывается
+// по квотам классов, а цена эконом-класса сразу становится ценой рейса.
+func (pr *pgFlightRepo) SaveFare(fare *models.Fare) error {
+	err := pr.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "flight_id"}, {Name: "fare_class"}},
+			DoUpdates: clause.AssignmentColumns([]string{"price", "capacity", "refundable", "exchangeable", "exchange_fee"}),
+		}).Omit("seats_reserved").Create(fare)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		updates := map[string]any{
+			"capacity": tx.Model(&models.Fare{}).Select("SUM(capacity)").Where("flight_id = ?", fare.FlightID),
+		}
+		if fare.FareClass == models.FareEconomy {
+			updates["price"] = fare.Price
+		}
+
+		return tx.Model(&models.Flight{}).Where("id = ?", fare.FlightID).Updates(updates).Error
+	})
+
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return errors.Wrap(repository.ErrFlightNotFound, "pgFlightRepo.SaveFare error")
+	}
+	if err != nil {
+		return errors.Wrap(err, "pgFlightRepo.SaveFare error")
+	}
+
+	return nil
+}
+
+// ReserveSeat занимает место в классе обслуживания рейса под билет. Строки
+// рейса и класса блокируются до конца транзакции, поэтому параллельные
+// покупки не продадут больше мест, чем квота класса. Вместимость рейса —
+// сумма квот, поэтому отдельно она не проверяется.
+// Повторный вызов с тем же ticketUID ничего не меняет.
+func (pr *pgFlightRepo) ReserveSeat(flightID int, fareClass string, ticketUID string) error {
+	err := pr.DB.Transaction(func(tx *gorm.DB) error {
+		var flight models.Flight
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", flightID).Take(&flight)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return repository.ErrFlightNotFound
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+		if flight.Closed() {
+			return repository.ErrFlightClosed
+		}
+
+		var fare models.Fare
+		res = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("flight_id = ? AND fare_class = ?", flightID, fareClass).Take(&fare)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return repository.ErrFareNotFound
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+
+		var reservation models.SeatReservation
+		res = tx.Where("ticket_uid = ?", ticketUID).Limit(1).Find(&reservation)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			if reservation.FlightID != flightID || reservation.FareClass != fareClass {
+				return errors.Errorf("ticket %s already has a %s seat on flight %d", ticketUID, reservation.FareClass, reservation.FlightID)
+			}
+			return nil
+		}
+
+		if fare.SeatsReserved >= fare.Capacity {
+			return repository.ErrSoldOut
+		}
+
+		res = tx.Create(&models.SeatReservation{TicketUID: ticketUID, FlightID: flightID, FareClass: fareClass})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		res = tx.Model(&models.Flight{}).Where("id = ?", flightID).
+			UpdateColumn("seats_reserved", gorm.Expr("seats_reserved + 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+
+		return tx.Model(&models.Fare{}).Where("flight_id = ? AND fare_class = ?", flightID, fareClass).
+			UpdateColumn("seats_reserved", gorm.Expr("seats_reserved + 1")).Error
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "pgFlightRepo.ReserveSeat error")
+	}
+
+	return nil
+}
+
+// ReleaseSeat освобождает место билета. Если места нет (уже освобождено или
+// билет куплен до учёта мест), ничего не делает.
+func (pr *pgFlightRepo) ReleaseSeat(ticketUID string) error {
+	err := pr.DB.Transaction(func(tx *gorm.DB) error {
+		var reservations []models.SeatReservation
+		res := tx.Clauses(clause.Returning{}).Where("ticket_uid = ?", ticketUID).Delete(&reservations)
+		if res.Error != nil {
+			return res.Error
+		}
+		if len(reservations) == 0 {
+			return nil
+		}
+
+		reservation := reservations[0]
+		res = tx.Model(&models.Flight{}).Where("id = ?", reservation.FlightID).
+			UpdateColumn("seats_reserved", gorm.Expr("seats_reserved - 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+
+		return tx.Model(&models.Fare{}).Where("flight_id = ? AND fare_class = ?", reservation.FlightID, reservation.FareClass).
+			UpdateColumn("seats_reserved", gorm.Expr("seats_reserved - 1")).Error
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "pgFlightRepo.ReleaseSeat error")
+	}
+
+	return nil
+}
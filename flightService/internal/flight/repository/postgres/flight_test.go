@@ -2,18 +2,22 @@ package postgres
 
 import (
 	"database/sql"
+	airportCache "flight_booking_system/flightService/internal/airport/repository/cache"
+	pgAirport "flight_booking_system/flightService/internal/airport/repository/postgres"
 	flightRep "flight_booking_system/flightService/internal/flight/repository"
 	"flight_booking_system/flightService/internal/testBuilders"
 	"flight_booking_system/flightService/models"
 	"flight_booking_system/flightService/pkg/logger"
+	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/bxcodec/faker"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/ozontech/allure-go/pkg/framework/suite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"regexp"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type FlightRepoTestSuite struct {
@@ -29,10 +33,10 @@ func TestFlightRepoSuite(t *testing.T) {
 	suite.RunSuite(t, new(FlightRepoTestSuite))
 }
 
-func (s *FlightRepoTestSuite) BeforeEach(t provider.T) {
+func newGormMock() (*sql.DB, *gorm.DB, sqlmock.Sqlmock, error) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
-		t.Fatal("error while creating sql mock")
+		return nil, nil, nil, err
 	}
 
 	dialector := postgres.New(postgres.Config{
@@ -43,7 +47,16 @@ func (s *FlightRepoTestSuite) BeforeEach(t provider.T) {
 	})
 	gormDB, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
-		t.Fatal("error gorm open")
+		return nil, nil, nil, err
+	}
+
+	return db, gormDB, mock, nil
+}
+
+func (s *FlightRepoTestSuite) BeforeEach(t provider.T) {
+	db, gormDB, mock, err := newGormMock()
+	if err != nil {
+		t.Fatal("error while creating sql mock")
 	}
 
 	var logger logger.Logger
@@ -52,7 +65,7 @@ func (s *FlightRepoTestSuite) BeforeEach(t provider.T) {
 	s.gormDB = gormDB
 	s.mock = mock
 
-	s.repo = New(logger, gormDB)
+	s.repo = New(logger, gormDB, airportCache.New(pgAirport.New(logger, gormDB)))
 	s.flightBuilder = testBuilders.NewFlightBuilder()
 }
 
@@ -62,21 +75,53 @@ func (s *FlightRepoTestSuite) AfterEach(t provider.T) {
 	s.db.Close()
 }
 
+var flightColumns = []string{"id", "flight_number", "datetime", "from_airport_id", "to_airport_id", "price", "capacity", "seats_reserved"}
+
+func flightRows(flights ...models.Flight) *sqlmock.Rows {
+	rows := sqlmock.NewRows(flightColumns)
+	for _, flight := range flights {
+		rows.AddRow(flight.ID, flight.FlightNumber, flight.DateTime, flight.FromAirportID, flight.ToAirportID, flight.Price, flight.Capacity, flight.SeatsReserved)
+	}
+
+	return rows
+}
+
+const availableSeatsQuery = `SELECT flight_id, SUM(capacity - seats_reserved) AS available FROM "flight_fare" WHERE flight_id IN `
+
+// availableRows возвращает свободные места рейсов парами id, места.
+func availableRows(seats ...int) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"flight_id", "available"})
+	for i := 0; i+1 < len(seats); i += 2 {
+		rows.AddRow(seats[i], seats[i+1])
+	}
+
+	return rows
+}
+
+func airportRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "city", "country"}).
+		AddRow(1, "Шереметьево", "Москва", "Россия").
+		AddRow(2, "Пулково", "Санкт-Петербург", "Россия")
+}
+
 func (s *FlightRepoTestSuite) TestCreateFlight(t provider.T) {
 	flight := s.flightBuilder.
 		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
+		WithFlightNumber("AFL031").
+		WithDateTime(time.Date(2021, 10, 8, 20, 0, 0, 0, time.UTC)).
+		WithFromAirportID(2).
+		WithToAirportID(1).
+		WithPrice(1500).
+		WithCapacity(100).
+		WithDurationMinutes(90).
+		WithStatus(models.FlightScheduled).
 		Build()
 
 	s.mock.ExpectBegin()
 
 	s.mock.ExpectQuery(regexp.QuoteMeta(
-		`INSERT INTO "flight" ("uid","username","flight_number","price","status","id") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id"`)).
-		WithArgs(flight.UID, flight.Username, flight.FlightNumber, flight.Price, flight.Status, flight.ID).
+		`INSERT INTO "flight" ("flight_number","datetime","from_airport_id","to_airport_id","price","capacity","seats_reserved","aircraft_type_id","duration_minutes","status","estimated_departure","estimated_arrival","gate","id") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14) RETURNING "id"`)).
+		WithArgs(flight.FlightNumber, flight.DateTime, flight.FromAirportID, flight.ToAirportID, flight.Price, flight.Capacity, flight.SeatsReserved, flight.AircraftTypeID, flight.DurationMinutes, flight.Status, flight.EstimatedDeparture, flight.EstimatedArrival, flight.Gate, flight.ID).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 	s.mock.ExpectCommit()
@@ -89,27 +134,18 @@ func (s *FlightRepoTestSuite) TestCreateFlight(t provider.T) {
 func (s *FlightRepoTestSuite) TestGetFlight(t provider.T) {
 	flight := s.flightBuilder.
 		WithID(1).
-		WithUID("").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
+		WithFlightNumber("AFL031").
+		WithDateTime(time.Date(2021, 10, 8, 20, 0, 0, 0, time.UTC)).
+		WithFromAirportID(2).
+		WithToAirportID(1).
+		WithPrice(1500).
+		WithCapacity(100).
 		Build()
 
-	rows := sqlmock.NewRows([]string{"id", "flight_uid", "username", "flight_number", "price", "status"}).
-		AddRow(
-			flight.ID,
-			flight.UID,
-			flight.Username,
-			flight.FlightNumber,
-			flight.Price,
-			flight.Status,
-		)
-
 	s.mock.ExpectQuery(regexp.QuoteMeta(
 		`SELECT * FROM "flight" WHERE id = $1 LIMIT $2`)).
 		WithArgs(flight.ID, 1).
-		WillReturnRows(rows)
+		WillReturnRows(flightRows(flight))
 
 	resFlight, err := s.repo.Get(flight.ID)
 	t.Assert().NoError(err)
@@ -119,28 +155,25 @@ func (s *FlightRepoTestSuite) TestGetFlight(t provider.T) {
 func (s *FlightRepoTestSuite) TestUpdateFlight(t provider.T) {
 	flight := s.flightBuilder.
 		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
+		WithFlightNumber("AFL031").
+		WithDateTime(time.Date(2021, 10, 8, 20, 0, 0, 0, time.UTC)).
+		WithFromAirportID(2).
+		WithToAirportID(1).
+		WithPrice(1500).
+		WithCapacity(100).
 		Build()
 
-	rows := sqlmock.NewRows([]string{"id", "flight_uid", "username", "flight_number", "price", "status"}).
-		AddRow(
-			flight.ID,
-			flight.UID,
-			flight.Username,
-			flight.FlightNumber,
-			flight.Price,
-			flight.Status,
-		)
-
 	s.mock.ExpectBegin()
 
 	s.mock.ExpectQuery(regexp.QuoteMeta(
-		`UPDATE "flight" SET "uid"=$1,"username"=$2,"flight_number"=$3,"price"=$4,"status"=$5 WHERE "id" = $6 RETURNING *`)).
-		WithArgs(flight.UID, flight.Username, flight.FlightNumber, flight.Price, flight.Status, flight.ID).WillReturnRows(rows)
+		`UPDATE "flight" SET "flight_number"=$1,"datetime"=$2,"from_airport_id"=$3,"to_airport_id"=$4,"price"=$5 WHERE "id" = $6 RETURNING *`)).
+		WithArgs(flight.FlightNumber, flight.DateTime, flight.FromAirportID, flight.ToAirportID, flight.Price, flight.ID).
+		WillReturnRows(flightRows(flight))
+
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "flight_fare" SET "price"=$1 WHERE flight_id = $2 AND fare_class = $3`)).
+		WithArgs(flight.Price, flight.ID, models.FareEconomy).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	s.mock.ExpectCommit()
 
@@ -148,51 +181,381 @@ func (s *FlightRepoTestSuite) TestUpdateFlight(t provider.T) {
 	t.Assert().NoError(err)
 }
 
-func (s *FlightRepoTestSuite) TestDeleteFlight(t provider.T) {
+func (s *FlightRepoTestSuite) TestUpdateFlightStatus(t provider.T) {
+	estimated := time.Date(2021, 10, 8, 21, 0, 0, 0, time.UTC)
 	flight := s.flightBuilder.
 		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
+		WithFlightNumber("AFL031").
+		WithStatus(models.FlightDelayed).
 		Build()
+	flight.EstimatedDeparture = &estimated
 
 	s.mock.ExpectBegin()
 
 	s.mock.ExpectExec(regexp.QuoteMeta(
-		`DELETE FROM "flight" WHERE "flight"."id" = $1`)).
-		WithArgs(flight.ID).WillReturnResult(sqlmock.NewResult(int64(flight.ID), 1))
+		`UPDATE "flight" SET "status"=$1,"estimated_departure"=$2,"estimated_arrival"=$3,"gate"=$4 WHERE "id" = $5`)).
+		WithArgs(models.FlightDelayed, &estimated, nil, "", flight.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	s.mock.ExpectCommit()
 
-	err := s.repo.Delete(flight.ID)
+	err := s.repo.UpdateStatus(&flight)
 	t.Assert().NoError(err)
 }
 
-func (s *FlightRepoTestSuite) TestGetAll(t provider.T) {
-	flights := make([]models.Flight, 10)
-	for _, flight := range flights {
-		err := faker.FakeData(&flight)
-		t.Assert().NoError(err)
-	}
+func (s *FlightRepoTestSuite) TestUpdateFlightStatusNotFound(t provider.T) {
+	flight := s.flightBuilder.WithID(1).WithStatus(models.FlightCancelled).Build()
 
-	flightsPtr := make([]*models.Flight, len(flights))
-	for i, flight := range flights {
-		flightsPtr[i] = &flight
-	}
+	s.mock.ExpectBegin()
+
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "flight" SET "status"=$1,"estimated_departure"=$2,"estimated_arrival"=$3,"gate"=$4 WHERE "id" = $5`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	s.mock.ExpectCommit()
+
+	err := s.repo.UpdateStatus(&flight)
+	t.Assert().ErrorIs(err, flightRep.ErrFlightNotFound)
+}
+
+func (s *FlightRepoTestSuite) TestDeleteFlight(t provider.T) {
+	s.mock.ExpectBegin()
+
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM "flight" WHERE "flight"."id" = $1`)).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
 
-	rowsFlights := sqlmock.NewRows([]string{"id", "flight_uid", "username", "flight_number", "price", "status"})
+	s.mock.ExpectCommit()
 
-	for i := range flights {
-		rowsFlights.AddRow(flights[i].ID, flights[i].UID, flights[i].Username, flights[i].FlightNumber, flights[i].Price, flights[i].Status)
+	err := s.repo.Delete(1)
+	t.Assert().NoError(err)
+}
+
+func (s *FlightRepoTestSuite) TestGetAll(t provider.T) {
+	date := time.Date(2021, 10, 8, 20, 0, 0, 0, time.UTC)
+	flights := []models.Flight{
+		{ID: 1, FlightNumber: "AFL031", DateTime: date, FromAirportID: 2, ToAirportID: 1, Price: 1500, Capacity: 100, SeatsReserved: 10},
+		{ID: 2, FlightNumber: "AFL032", DateTime: date, FromAirportID: 1, ToAirportID: 2, Price: 1700, Capacity: 50},
 	}
 
 	s.mock.ExpectQuery(regexp.QuoteMeta(
 		`SELECT * FROM "flight"`)).
-		WillReturnRows(rowsFlights)
+		WillReturnRows(flightRows(flights...))
+
+	// Свободные места считаются по квотам классов, а не по полям рейса
+	s.mock.ExpectQuery(regexp.QuoteMeta(availableSeatsQuery+`($1,$2) GROUP BY "flight_id"`)).
+		WithArgs(1, 2).
+		WillReturnRows(availableRows(1, 85, 2, 50))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "airport" WHERE id IN ($1,$2)`)).
+		WithArgs(2, 1).
+		WillReturnRows(airportRows())
 
 	resFlights, err := s.repo.GetAll()
 	t.Assert().NoError(err)
-	t.Assert().Equal(flightsPtr, resFlights)
+	t.Assert().Equal([]*models.FlightDTO{
+		{ID: 1, FlightNumber: "AFL031", Date: date, FromAirport: "Санкт-Петербург Пулково", ToAirport: "Москва Шереметьево", Price: 1500, AvailableSeats: 85},
+		{ID: 2, FlightNumber: "AFL032", Date: date, FromAirport: "Москва Шереметьево", ToAirport: "Санкт-Петербург Пулково", Price: 1700, AvailableSeats: 50},
+	}, resFlights)
+}
+
+func (s *FlightRepoTestSuite) TestGetAllUsesAirportCache(t provider.T) {
+	flight := models.Flight{ID: 1, FlightNumber: "AFL031", FromAirportID: 2, ToAirportID: 1, Price: 1500}
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "flight"`)).
+		WillReturnRows(flightRows(flight))
+	s.mock.ExpectQuery(regexp.QuoteMeta(availableSeatsQuery)).
+		WillReturnRows(availableRows(1, 100))
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "airport" WHERE id IN ($1,$2)`)).
+		WillReturnRows(airportRows())
+
+	_, err := s.repo.GetAll()
+	t.Assert().NoError(err)
+
+	// аэропорты уже в кэше — второй запрос списка идёт только в таблицы рейсов
+	s.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "flight"`)).
+		WillReturnRows(flightRows(flight))
+	s.mock.ExpectQuery(regexp.QuoteMeta(availableSeatsQuery)).
+		WillReturnRows(availableRows(1, 100))
+
+	_, err = s.repo.GetAll()
+	t.Assert().NoError(err)
+}
+
+// fareRows возвращает строки flight_fare для эконом-класса первого рейса.
+func fareRows(capacity, reserved int) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"flight_id", "fare_class", "price", "capacity", "seats_reserved", "refundable"}).
+		AddRow(1, models.FareEconomy, 1500, capacity, reserved, true)
+}
+
+var reservationColumns = []string{"ticket_uid", "flight_id", "created_at", "fare_class"}
+
+func (s *FlightRepoTestSuite) expectLocks(flight models.Flight, fares *sqlmock.Rows) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "flight" WHERE id = $1 LIMIT $2 FOR UPDATE`)).
+		WithArgs(flight.ID, 1).
+		WillReturnRows(flightRows(flight))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "flight_fare" WHERE flight_id = $1 AND fare_class = $2 LIMIT $3 FOR UPDATE`)).
+		WithArgs(flight.ID, models.FareEconomy, 1).
+		WillReturnRows(fares)
+}
+
+func (s *FlightRepoTestSuite) TestReserveSeat(t provider.T) {
+	flight := models.Flight{ID: 1, FlightNumber: "AFL031", Capacity: 2, SeatsReserved: 1}
+
+	s.mock.ExpectBegin()
+	s.expectLocks(flight, fareRows(2, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "seat_reservation" WHERE ticket_uid = $1 LIMIT $2`)).
+		WithArgs("uid", 1).
+		WillReturnRows(sqlmock.NewRows(reservationColumns))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "seat_reservation" ("ticket_uid","flight_id","created_at","seat_number","fare_class") VALUES ($1,$2,$3,$4,$5)`)).
+		WithArgs("uid", flight.ID, sqlmock.AnyArg(), nil, models.FareEconomy).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "flight" SET "seats_reserved"=seats_reserved + 1 WHERE id = $1`)).
+		WithArgs(flight.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "flight_fare" SET "seats_reserved"=seats_reserved + 1 WHERE flight_id = $1 AND fare_class = $2`)).
+		WithArgs(flight.ID, models.FareEconomy).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.ReserveSeat(flight.ID, models.FareEconomy, "uid")
+	t.Assert().NoError(err)
+}
+
+func (s *FlightRepoTestSuite) TestReserveSeatTwice(t provider.T) {
+	flight := models.Flight{ID: 1, FlightNumber: "AFL031", Capacity: 1, SeatsReserved: 1}
+
+	s.mock.ExpectBegin()
+	s.expectLocks(flight, fareRows(1, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "seat_reservation" WHERE ticket_uid = $1 LIMIT $2`)).
+		WillReturnRows(sqlmock.NewRows(reservationColumns).
+			AddRow("uid", flight.ID, time.Now(), models.FareEconomy))
+	s.mock.ExpectCommit()
+
+	err := s.repo.ReserveSeat(flight.ID, models.FareEconomy, "uid")
+	t.Assert().NoError(err)
+}
+
+func (s *FlightRepoTestSuite) TestReserveSeatSoldOut(t provider.T) {
+	flight := models.Flight{ID: 1, FlightNumber: "AFL031", Capacity: 1, SeatsReserved: 1}
+
+	s.mock.ExpectBegin()
+	s.expectLocks(flight, fareRows(1, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "seat_reservation" WHERE ticket_uid = $1 LIMIT $2`)).
+		WillReturnRows(sqlmock.NewRows(reservationColumns))
+	s.mock.ExpectRollback()
+
+	err := s.repo.ReserveSeat(flight.ID, models.FareEconomy, "uid")
+	t.Assert().ErrorIs(err, flightRep.ErrSoldOut)
+}
+
+func (s *FlightRepoTestSuite) TestReserveSeatFareSoldOut(t provider.T) {
+	flight := models.Flight{ID: 1, FlightNumber: "AFL031", Capacity: 100, SeatsReserved: 10}
+
+	s.mock.ExpectBegin()
+	s.expectLocks(flight, fareRows(10, 10))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "seat_reservation" WHERE ticket_uid = $1 LIMIT $2`)).
+		WillReturnRows(sqlmock.NewRows(reservationColumns))
+	s.mock.ExpectRollback()
+
+	err := s.repo.ReserveSeat(flight.ID, models.FareEconomy, "uid")
+	t.Assert().ErrorIs(err, flightRep.ErrSoldOut)
+}
+
+func (s *FlightRepoTestSuite) TestReserveSeatFareNotFound(t provider.T) {
+	flight := models.Flight{ID: 1, FlightNumber: "AFL031", Capacity: 100}
+
+	s.mock.ExpectBegin()
+	s.expectLocks(flight, sqlmock.NewRows([]string{"flight_id", "fare_class"}))
+	s.mock.ExpectRollback()
+
+	err := s.repo.ReserveSeat(flight.ID, models.FareEconomy, "uid")
+	t.Assert().ErrorIs(err, flightRep.ErrFareNotFound)
+}
+
+func (s *FlightRepoTestSuite) TestReserveSeatFlightNotFound(t provider.T) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "flight" WHERE id = $1 LIMIT $2 FOR UPDATE`)).
+		WillReturnRows(flightRows())
+	s.mock.ExpectRollback()
+
+	err := s.repo.ReserveSeat(1, models.FareEconomy, "uid")
+	t.Assert().ErrorIs(err, flightRep.ErrFlightNotFound)
+}
+
+func (s *FlightRepoTestSuite) TestReserveSeatFlightCancelled(t provider.T) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "flight" WHERE id = $1 LIMIT $2 FOR UPDATE`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "capacity", "seats_reserved", "status"}).
+			AddRow(1, 100, 10, models.FlightCancelled))
+	s.mock.ExpectRollback()
+
+	err := s.repo.ReserveSeat(1, models.FareEconomy, "uid")
+	t.Assert().ErrorIs(err, flightRep.ErrFlightClosed)
+}
+
+func (s *FlightRepoTestSuite) TestReleaseSeat(t provider.T) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`DELETE FROM "seat_reservation" WHERE ticket_uid = $1 RETURNING *`)).
+		WithArgs("uid").
+		WillReturnRows(sqlmock.NewRows(reservationColumns).
+			AddRow("uid", 1, time.Now(), models.FareEconomy))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "flight" SET "seats_reserved"=seats_reserved - 1 WHERE id = $1`)).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "flight_fare" SET "seats_reserved"=seats_reserved - 1 WHERE flight_id = $1 AND fare_class = $2`)).
+		WithArgs(1, models.FareEconomy).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.ReleaseSeat("uid")
+	t.Assert().NoError(err)
+}
+
+func (s *FlightRepoTestSuite) TestReleaseSeatMissing(t provider.T) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`DELETE FROM "seat_reservation" WHERE ticket_uid = $1 RETURNING *`)).
+		WithArgs("uid").
+		WillReturnRows(sqlmock.NewRows(reservationColumns))
+	s.mock.ExpectCommit()
+
+	err := s.repo.ReleaseSeat("uid")
+	t.Assert().NoError(err)
+}
+
+func (s *FlightRepoTestSuite) TestGetDepartures(t provider.T) {
+	from := time.Date(2021, 10, 8, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "flight" WHERE datetime >= $1 AND datetime < $2 ORDER BY datetime,id`)).
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "flight_number", "datetime", "duration_minutes"}).
+			AddRow(1, "AFL031", from.Add(20*time.Hour), 90))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "flight_fare" WHERE "flight_fare"."flight_id" = $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"flight_id", "fare_class", "price", "capacity"}).
+			AddRow(1, models.FareEconomy, 1500, 100))
+
+	flights, err := s.repo.GetDepartures(from, to)
+	t.Assert().NoError(err)
+	t.Require().Len(flights, 1)
+	t.Assert().Equal(from.Add(21*time.Hour+30*time.Minute), flights[0].ArrivalTime())
+	t.Require().Len(flights[0].Fares, 1)
+	t.Assert().Equal(1500, flights[0].Fares[0].Price)
+}
+
+func (s *FlightRepoTestSuite) TestSaveFare(t provider.T) {
+	fare := &models.Fare{FlightID: 1, FareClass: models.FareComfort, Price: 2500, Capacity: 20, Exchangeable: true, ExchangeFee: 500}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "flight_fare" ("flight_id","fare_class","price","capacity","refundable","exchangeable","exchange_fee") VALUES ($1,$2,$3,$4,$5,$6,$7) ON CONFLICT ("flight_id","fare_class") DO UPDATE SET "price"="excluded"."price","capacity"="excluded"."capacity","refundable"="excluded"."refundable","exchangeable"="excluded"."exchangeable","exchange_fee"="excluded"."exchange_fee"`)).
+		WithArgs(1, models.FareComfort, 2500, 20, false, true, 500).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "flight" SET "capacity"=(SELECT SUM(capacity) FROM "flight_fare" WHERE flight_id = $1) WHERE id = $2`)).
+		WithArgs(1, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.SaveFare(fare)
+	t.Assert().NoError(err)
+}
+
+func (s *FlightRepoTestSuite) TestSaveFareEconomy(t provider.T) {
+	fare := &models.Fare{FlightID: 1, FareClass: models.FareEconomy, Price: 1800, Capacity: 80, Refundable: true}
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO "flight_fare" ("flight_id","fare_class","price","capacity","refundable","exchangeable","exchange_fee") VALUES ($1,$2,$3,$4,$5,$6,$7) ON CONFLICT ("flight_id","fare_class") DO UPDATE SET "price"="excluded"."price","capacity"="excluded"."capacity","refundable"="excluded"."refundable","exchangeable"="excluded"."exchangeable","exchange_fee"="excluded"."exchange_fee"`)).
+		WithArgs(1, models.FareEconomy, 1800, 80, true, false, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "flight" SET "capacity"=(SELECT SUM(capacity) FROM "flight_fare" WHERE flight_id = $1),"price"=$2 WHERE id = $3`)).
+		WithArgs(1, 1800, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.SaveFare(fare)
+	t.Assert().NoError(err)
+}
+
+// countQueries считает SELECT-запросы, которые gorm отправил в базу.
+func countQueries(gormDB *gorm.DB) *atomic.Int64 {
+	var queries atomic.Int64
+	_ = gormDB.Callback().Query().After("gorm:query").Register("test:count_queries", func(*gorm.DB) {
+		queries.Add(1)
+	})
+
+	return &queries
+}
+
+// BenchmarkGetAllPaginate показывает, что число запросов на страницу не
+// зависит от её размера: запрос рейсов, запрос свободных мест и не больше
+// одного запроса аэропортов, пока кэш пуст.
+func BenchmarkGetAllPaginate(b *testing.B) {
+	for _, size := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			db, gormDB, mock, err := newGormMock()
+			if err != nil {
+				b.Fatal("error while creating sql mock")
+			}
+			defer db.Close()
+
+			var logger logger.Logger
+			repo := New(logger, gormDB, airportCache.New(pgAirport.New(logger, gormDB)))
+			queries := countQueries(gormDB)
+
+			flights := make([]models.Flight, size)
+			for i := range flights {
+				flights[i] = models.Flight{ID: i + 1, FlightNumber: fmt.Sprintf("AFL%03d", i), FromAirportID: 2, ToAirportID: 1, Price: 1500}
+			}
+
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "airport" WHERE id IN ($1,$2)`)).
+				WillReturnRows(airportRows())
+			mock.MatchExpectationsInOrder(false)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "flight" LIMIT $1`)).
+					WillReturnRows(flightRows(flights...))
+				mock.ExpectQuery(regexp.QuoteMeta(availableSeatsQuery)).
+					WillReturnRows(availableRows())
+				b.StartTimer()
+
+				resFlights, err := repo.GetAllPaginate(0, size)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(resFlights) != size {
+					b.Fatalf("got %d flights, want %d", len(resFlights), size)
+				}
+			}
+			b.StopTimer()
+
+			if got, limit := queries.Load(), 2*int64(b.N)+1; got > limit {
+				b.Fatalf("%d queries for %d pages of %d flights, want at most %d", got, b.N, size, limit)
+			}
+			b.ReportMetric(float64(queries.Load())/float64(b.N), "queries/op")
+		})
+	}
 }
@@ -1,13 +1,38 @@
 package repository
 
-import "flight_booking_system/flightService/models"
+import (
+	"time"
+
+	"flight_booking_system/flightService/models"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrFlightNotFound = errors.New("flight not found")
+	// ErrSoldOut — на рейсе не осталось свободных мест.
+	ErrSoldOut = errors.New("flight is sold out")
+	// ErrFareNotFound — на рейсе нет такого класса обслуживания.
+	ErrFareNotFound = errors.New("fare not found")
+	// ErrFlightClosed — рейс отменён или уже вылетел.
+	ErrFlightClosed = errors.New("flight is cancelled or departed")
+)
 
 type FlightRepositoryI interface {
 	Create(p *models.Flight) error
 	Get(id int) (*models.Flight, error)
 	Update(p *models.Flight) error
+	// UpdateStatus сохраняет только статус, расчётное время и выход рейса.
+	UpdateStatus(p *models.Flight) error
 	Delete(id int) error
 	GetAll() ([]*models.FlightDTO, error)
 	GetAllByFlightNumber(flightNumber string) ([]*models.FlightDTO, error)
+	GetAllByFlightNumbers(flightNumbers []string) ([]*models.FlightDTO, error)
 	GetAllPaginate(offset, limit int) ([]*models.FlightDTO, error)
+	Search(filter models.FlightFilter) ([]*models.FlightDTO, int64, error)
+	// GetDepartures возвращает рейсы с классами обслуживания, вылетающие в [from, to).
+	GetDepartures(from, to time.Time) ([]*models.Flight, error)
+	GetFares(flightID int) ([]*models.Fare, error)
+	SaveFare(fare *models.Fare) error
+	ReserveSeat(flightID int, fareClass string, ticketUID string) error
+	ReleaseSeat(ticketUID string) error
 }
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"sort"
+	"time"
+
+	"flight_booking_system/flightService/models"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultMaxConnections = 1
+	// MaxConnections — больше пересадок поиск не рассматривает.
+	MaxConnections       = 3
+	DefaultMinConnection = 45 * time.Minute
+	DefaultMaxConnection = 6 * time.Hour
+
+	defaultRoutesLimit = 20
+	maxRoutesLimit     = 100
+
+	// maxLegDuration ограничивает окно загрузки рейсов для следующих перелётов
+	maxLegDuration = 24 * time.Hour
+)
+
+func (pUC *flightUseCase) validateRouteQuery(query *models.RouteQuery) error {
+	if query.FareClass == "" {
+		query.FareClass = models.FareEconomy
+	}
+	if query.SortBy == "" {
+		query.SortBy = models.RouteSortByPrice
+	}
+	if query.MinConnection == 0 {
+		query.MinConnection = DefaultMinConnection
+	}
+	if query.MaxConnection == 0 {
+		query.MaxConnection = DefaultMaxConnection
+	}
+	switch {
+	case query.Limit <= 0:
+		query.Limit = defaultRoutesLimit
+	case query.Limit > maxRoutesLimit:
+		query.Limit = maxRoutesLimit
+	}
+
+	switch {
+	case query.FromAirportID == 0 || query.ToAirportID == 0:
+		return errors.Wrap(ErrInvalidFilter, "origin and destination airports are required")
+	case query.FromAirportID == query.ToAirportID:
+		return errors.Wrap(ErrInvalidFilter, "origin and destination airports must differ")
+	case query.Date.IsZero():
+		return errors.Wrap(ErrInvalidFilter, "date is required")
+	case models.FareCabin[query.FareClass] == "":
+		return errors.Wrapf(ErrInvalidFilter, "unknown fare class %q", query.FareClass)
+	case query.MaxConnections < 0 || query.MaxConnections > MaxConnections:
+		return errors.Wrapf(ErrInvalidFilter, "max connections must be from 0 to %d", MaxConnections)
+	case query.MinConnection < 0 || query.MaxConnection < query.MinConnection:
+		return errors.Wrap(ErrInvalidFilter, "connection time must satisfy 0 <= min <= max")
+	case query.SortBy != models.RouteSortByPrice && query.SortBy != models.RouteSortByDuration:
+		return errors.Wrapf(ErrInvalidFilter, "unknown sort field %q", query.SortBy)
+	}
+
+	return nil
+}
+
+// availableFare — класс обслуживания рейса, если рейс продаётся и в классе
+// остались места.
+func availableFare(flight *models.Flight, fareClass string) *models.Fare {
+	if flight.Closed() {
+		return nil
+	}
+
+	for i := range flight.Fares {
+		fare := &flight.Fares[i]
+		if fare.FareClass == fareClass && fare.SeatsReserved < fare.Capacity {
+			return fare
+		}
+	}
+
+	return nil
+}
+
+// route — найденная цепочка рейсов и её итоговые цена и время в пути.
+type route struct {
+	flights  []*models.Flight
+	price    int
+	duration time.Duration
+}
+
+func newRoute(flights []*models.Flight, fareClass string) route {
+	r := route{flights: flights}
+	for _, flight := range flights {
+		r.price += availableFare(flight, fareClass).Price
+	}
+	r.duration = flights[len(flights)-1].ArrivalTime().Sub(flights[0].DateTime)
+
+	return r
+}
+
+// findRoutes перебирает в глубину цепочки рейсов от FromAirportID до
+// ToAirportID: первый рейс вылетает в сутки Date, каждый следующий — из
+// аэропорта прилёта предыдущего в пределах допустимой пересадки. Аэропорты в
+// цепочке не повторяются.
+func findRoutes(flights []*models.Flight, query models.RouteQuery) []route {
+	departures := make(map[int][]*models.Flight)
+	for _, flight := range flights {
+		if availableFare(flight, query.FareClass) == nil {
+			continue
+		}
+		departures[flight.FromAirportID] = append(departures[flight.FromAirportID], flight)
+	}
+
+	var routes []route
+	var path []*models.Flight
+	visited := map[int]bool{query.FromAirportID: true}
+
+	var extend func(airportID int, earliest, latest time.Time)
+	extend = func(airportID int, earliest, latest time.Time) {
+		for _, flight := range departures[airportID] {
+			if flight.DateTime.Before(earliest) || flight.DateTime.After(latest) || visited[flight.ToAirportID] {
+				continue
+			}
+
+			path = append(path, flight)
+			switch {
+			case flight.ToAirportID == query.ToAirportID:
+				routes = append(routes, newRoute(append([]*models.Flight(nil), path...), query.FareClass))
+			case len(path) <= query.MaxConnections:
+				arrival := flight.ArrivalTime()
+				visited[flight.ToAirportID] = true
+				extend(flight.ToAirportID, arrival.Add(query.MinConnection), arrival.Add(query.MaxConnection))
+				visited[flight.ToAirportID] = false
+			}
+			path = path[:len(path)-1]
+		}
+	}
+	extend(query.FromAirportID, query.Date, query.Date.AddDate(0, 0, 1).Add(-time.Nanosecond))
+
+	return routes
+}
+
+// sortRoutes упорядочивает маршруты по цене, а при равной цене — по времени в
+// пути, или наоборот для сортировки по времени.
+func sortRoutes(routes []route, sortBy string) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if sortBy == models.RouteSortByDuration && a.duration != b.duration {
+			return a.duration < b.duration
+		}
+		if a.price != b.price {
+			return a.price < b.price
+		}
+		if a.duration != b.duration {
+			return a.duration < b.duration
+		}
+		return a.flights[0].DateTime.Before(b.flights[0].DateTime)
+	})
+}
+
+// FindRoutes ищет маршруты с не более чем MaxConnections пересадками.
+func (pUC *flightUseCase) FindRoutes(query models.RouteQuery) ([]*models.RouteDTO, error) {
+	err := pUC.validateRouteQuery(&query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Последний перелёт вылетает не позже, чем через все пересадки и перелёты
+	// после конца суток вылета
+	to := query.Date.AddDate(0, 0, 1).Add(time.Duration(query.MaxConnections) * (query.MaxConnection + maxLegDuration))
+	flights, err := pUC.flightRepository.GetDepartures(query.Date, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightUseCase.FindRoutes error")
+	}
+
+	routes := findRoutes(flights, query)
+	sortRoutes(routes, query.SortBy)
+	if len(routes) > query.Limit {
+		routes = routes[:query.Limit]
+	}
+
+	res, err := pUC.makeRouteDTOs(routes, query.FareClass)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightUseCase.FindRoutes error")
+	}
+
+	return res, nil
+}
+
+func (pUC *flightUseCase) makeRouteDTOs(routes []route, fareClass string) ([]*models.RouteDTO, error) {
+	ids := make([]int, 0)
+	seen := make(map[int]bool)
+	for _, r := range routes {
+		for _, flight := range r.flights {
+			for _, id := range []int{flight.FromAirportID, flight.ToAirportID} {
+				if !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+
+	airportNames := make(map[int]string, len(ids))
+	if len(ids) > 0 {
+		airports, err := pUC.airportRepository.GetByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, airport := range airports {
+			airportNames[airport.ID] = airport.City + " " + airport.Name
+		}
+	}
+
+	res := make([]*models.RouteDTO, 0, len(routes))
+	for _, r := range routes {
+		dto := &models.RouteDTO{
+			Legs:            make([]*models.RouteLegDTO, len(r.flights)),
+			Connections:     len(r.flights) - 1,
+			TotalPrice:      r.price,
+			DurationMinutes: int(r.duration / time.Minute),
+			DepartureDate:   r.flights[0].DateTime,
+			ArrivalDate:     r.flights[len(r.flights)-1].ArrivalTime(),
+		}
+
+		for i, flight := range r.flights {
+			fare := availableFare(flight, fareClass)
+			leg := &models.RouteLegDTO{
+				FlightID:       flight.ID,
+				FlightNumber:   flight.FlightNumber,
+				FromAirport:    airportNames[flight.FromAirportID],
+				ToAirport:      airportNames[flight.ToAirportID],
+				DepartureDate:  flight.DateTime,
+				ArrivalDate:    flight.ArrivalTime(),
+				FareClass:      fare.FareClass,
+				Price:          fare.Price,
+				AvailableSeats: fare.Capacity - fare.SeatsReserved,
+			}
+			if i > 0 {
+				leg.ConnectionMinutes = int(flight.DateTime.Sub(r.flights[i-1].ArrivalTime()) / time.Minute)
+			}
+			dto.Legs[i] = leg
+		}
+
+		res = append(res, dto)
+	}
+
+	return res, nil
+}
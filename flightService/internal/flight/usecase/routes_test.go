@@ -0,0 +1,418 @@
+package usecase
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	airportMocks "flight_booking_system/flightService/internal/airport/repository/mocks"
+	flightMocks "flight_booking_system/flightService/internal/flight/repository/mocks"
+	"flight_booking_system/flightService/models"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"github.com/stretchr/testify/mock"
+)
+
+var routeDate = time.Date(2030, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+type RoutesTestSuite struct {
+	suite.Suite
+	uc              FlightUseCaseI
+	flightRepoMock  *flightMocks.FlightRepositoryI
+	airportRepoMock *airportMocks.AirportRepositoryI
+}
+
+func TestRoutesTestSuite(t *testing.T) {
+	suite.RunSuite(t, new(RoutesTestSuite))
+}
+
+func (s *RoutesTestSuite) BeforeEach(t provider.T) {
+	s.flightRepoMock = flightMocks.NewFlightRepositoryI(t)
+	s.airportRepoMock = airportMocks.NewAirportRepositoryI(t)
+	s.uc = New(s.flightRepoMock, s.airportRepoMock, nil)
+}
+
+// routeFlight — рейс с одним эконом-классом, вылетающий через departure после
+// начала суток routeDate.
+func routeFlight(id, from, to int, departure time.Duration, minutes, price int) *models.Flight {
+	return &models.Flight{
+		ID:              id,
+		FlightNumber:    fmt.Sprintf("AFL%03d", id),
+		DateTime:        routeDate.Add(departure),
+		FromAirportID:   from,
+		ToAirportID:     to,
+		Price:           price,
+		Capacity:        100,
+		DurationMinutes: minutes,
+		Status:          models.FlightScheduled,
+		Fares: []models.Fare{{
+			FareClass: models.FareEconomy,
+			Price:     price,
+			Capacity:  100,
+		}},
+	}
+}
+
+func routeQuery(from, to, maxConnections int) models.RouteQuery {
+	return models.RouteQuery{
+		FromAirportID:  from,
+		ToAirportID:    to,
+		Date:           routeDate,
+		FareClass:      models.FareEconomy,
+		MaxConnections: maxConnections,
+		MinConnection:  DefaultMinConnection,
+		MaxConnection:  DefaultMaxConnection,
+		SortBy:         models.RouteSortByPrice,
+		Limit:          defaultRoutesLimit,
+	}
+}
+
+// routeIDs — идентификаторы рейсов каждого маршрута по порядку.
+func routeIDs(routes []route) [][]int {
+	res := make([][]int, 0, len(routes))
+	for _, r := range routes {
+		ids := make([]int, len(r.flights))
+		for i, flight := range r.flights {
+			ids[i] = flight.ID
+		}
+		res = append(res, ids)
+	}
+
+	return res
+}
+
+func (s *RoutesTestSuite) TestValidateRouteQuery(t provider.T) {
+	uc := &flightUseCase{}
+
+	valid := models.RouteQuery{FromAirportID: 1, ToAirportID: 2, Date: routeDate}
+
+	cases := map[string]struct {
+		Modify func(q *models.RouteQuery)
+		Error  error
+	}{
+		"valid":                {Modify: func(q *models.RouteQuery) {}},
+		"max connections":      {Modify: func(q *models.RouteQuery) { q.MaxConnections = MaxConnections }},
+		"no origin":            {Modify: func(q *models.RouteQuery) { q.FromAirportID = 0 }, Error: ErrInvalidFilter},
+		"same airports":        {Modify: func(q *models.RouteQuery) { q.ToAirportID = 1 }, Error: ErrInvalidFilter},
+		"no date":              {Modify: func(q *models.RouteQuery) { q.Date = time.Time{} }, Error: ErrInvalidFilter},
+		"unknown fare class":   {Modify: func(q *models.RouteQuery) { q.FareClass = "FIRST" }, Error: ErrInvalidFilter},
+		"negative connections": {Modify: func(q *models.RouteQuery) { q.MaxConnections = -1 }, Error: ErrInvalidFilter},
+		"too many connections": {Modify: func(q *models.RouteQuery) { q.MaxConnections = MaxConnections + 1 }, Error: ErrInvalidFilter},
+		"negative min connection": {
+			Modify: func(q *models.RouteQuery) { q.MinConnection = -time.Minute },
+			Error:  ErrInvalidFilter,
+		},
+		"min above max connection": {
+			Modify: func(q *models.RouteQuery) { q.MinConnection, q.MaxConnection = 2*time.Hour, time.Hour },
+			Error:  ErrInvalidFilter,
+		},
+		"unknown sort": {Modify: func(q *models.RouteQuery) { q.SortBy = "stops" }, Error: ErrInvalidFilter},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			query := valid
+			test.Modify(&query)
+
+			err := uc.validateRouteQuery(&query)
+			t.Assert().ErrorIs(err, test.Error)
+		})
+	}
+
+	t.Run("defaults applied", func(t provider.T) {
+		query := valid
+		err := uc.validateRouteQuery(&query)
+
+		t.Assert().NoError(err)
+		t.Assert().Equal(models.FareEconomy, query.FareClass)
+		t.Assert().Equal(models.RouteSortByPrice, query.SortBy)
+		t.Assert().Equal(DefaultMinConnection, query.MinConnection)
+		t.Assert().Equal(DefaultMaxConnection, query.MaxConnection)
+		t.Assert().Equal(defaultRoutesLimit, query.Limit)
+	})
+
+	t.Run("limit capped", func(t provider.T) {
+		query := valid
+		query.Limit = maxRoutesLimit + 1
+		err := uc.validateRouteQuery(&query)
+
+		t.Assert().NoError(err)
+		t.Assert().Equal(maxRoutesLimit, query.Limit)
+	})
+}
+
+func (s *RoutesTestSuite) TestFindRoutesConnectionBounds(t provider.T) {
+	// Первый рейс прилетает в 09:00
+	first := routeFlight(1, 1, 2, 8*time.Hour, 60, 100)
+
+	cases := map[string]struct {
+		Departure time.Duration
+		Found     bool
+	}{
+		"shorter than min":   {Departure: 9*time.Hour + 30*time.Minute, Found: false},
+		"exactly min":        {Departure: 9*time.Hour + 45*time.Minute, Found: true},
+		"within bounds":      {Departure: 12 * time.Hour, Found: true},
+		"exactly max":        {Departure: 15 * time.Hour, Found: true},
+		"longer than max":    {Departure: 15*time.Hour + time.Minute, Found: false},
+		"before the arrival": {Departure: 8 * time.Hour, Found: false},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			second := routeFlight(2, 2, 3, test.Departure, 60, 100)
+
+			routes := findRoutes([]*models.Flight{first, second}, routeQuery(1, 3, 1))
+
+			if test.Found {
+				t.Assert().Equal([][]int{{1, 2}}, routeIDs(routes))
+			} else {
+				t.Assert().Empty(routes)
+			}
+		})
+	}
+}
+
+func (s *RoutesTestSuite) TestFindRoutesFirstLegDate(t provider.T) {
+	cases := map[string]struct {
+		Departure time.Duration
+		Found     bool
+	}{
+		"day before":   {Departure: -time.Minute, Found: false},
+		"start of day": {Departure: 0, Found: true},
+		"end of day":   {Departure: 24*time.Hour - time.Minute, Found: true},
+		"next day":     {Departure: 24 * time.Hour, Found: false},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			flight := routeFlight(1, 1, 2, test.Departure, 60, 100)
+
+			routes := findRoutes([]*models.Flight{flight}, routeQuery(1, 2, 0))
+
+			t.Assert().Equal(test.Found, len(routes) == 1)
+		})
+	}
+}
+
+func (s *RoutesTestSuite) TestFindRoutesMaxConnections(t provider.T) {
+	// Цепочка 1 -> 2 -> 3 -> 4 с пересадками по часу
+	flights := []*models.Flight{
+		routeFlight(1, 1, 2, 8*time.Hour, 60, 100),
+		routeFlight(2, 2, 3, 10*time.Hour, 60, 100),
+		routeFlight(3, 3, 4, 12*time.Hour, 60, 100),
+	}
+
+	cases := map[string]struct {
+		MaxConnections int
+		Routes         [][]int
+	}{
+		"direct only":    {MaxConnections: 0, Routes: [][]int{}},
+		"one connection": {MaxConnections: 1, Routes: [][]int{}},
+		"two connections": {
+			MaxConnections: 2,
+			Routes:         [][]int{{1, 2, 3}},
+		},
+		"three connections": {
+			MaxConnections: 3,
+			Routes:         [][]int{{1, 2, 3}},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			routes := findRoutes(flights, routeQuery(1, 4, test.MaxConnections))
+			t.Assert().Equal(test.Routes, routeIDs(routes))
+		})
+	}
+}
+
+func (s *RoutesTestSuite) TestFindRoutesNoRevisit(t provider.T) {
+	cases := map[string]struct {
+		Flights []*models.Flight
+		Routes  [][]int
+	}{
+		"back to origin": {
+			// 1 -> 2 -> 1 -> 3 не подходит, остаётся прямой рейс
+			Flights: []*models.Flight{
+				routeFlight(1, 1, 2, 6*time.Hour, 60, 100),
+				routeFlight(2, 2, 1, 8*time.Hour, 60, 100),
+				routeFlight(3, 1, 3, 10*time.Hour, 60, 100),
+			},
+			Routes: [][]int{{3}},
+		},
+		"back to connection": {
+			// 1 -> 2 -> 4 -> 2 -> 3 не подходит, остаётся 1 -> 2 -> 3
+			Flights: []*models.Flight{
+				routeFlight(1, 1, 2, 6*time.Hour, 60, 100),
+				routeFlight(2, 2, 4, 8*time.Hour, 60, 100),
+				routeFlight(3, 4, 2, 10*time.Hour, 60, 100),
+				routeFlight(4, 2, 3, 12*time.Hour, 60, 100),
+			},
+			Routes: [][]int{{1, 4}},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			routes := findRoutes(test.Flights, routeQuery(1, 3, MaxConnections))
+			t.Assert().Equal(test.Routes, routeIDs(routes))
+		})
+	}
+}
+
+func (s *RoutesTestSuite) TestFindRoutesSoldOut(t provider.T) {
+	cases := map[string]struct {
+		Modify func(f *models.Flight)
+		Found  bool
+	}{
+		"available": {Modify: func(f *models.Flight) {}, Found: true},
+		"other fare sold out": {
+			Modify: func(f *models.Flight) {
+				f.Fares = append(f.Fares, models.Fare{FareClass: models.FareBusiness, Price: 300, Capacity: 10, SeatsReserved: 10})
+			},
+			Found: true,
+		},
+		"fare sold out": {
+			Modify: func(f *models.Flight) { f.Fares[0].SeatsReserved = f.Fares[0].Capacity },
+			Found:  false,
+		},
+		"no such fare": {
+			Modify: func(f *models.Flight) { f.Fares[0].FareClass = models.FareBusiness },
+			Found:  false,
+		},
+		"cancelled": {
+			Modify: func(f *models.Flight) { f.Status = models.FlightCancelled },
+			Found:  false,
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			first := routeFlight(1, 1, 2, 8*time.Hour, 60, 100)
+			second := routeFlight(2, 2, 3, 10*time.Hour, 60, 100)
+			test.Modify(second)
+
+			routes := findRoutes([]*models.Flight{first, second}, routeQuery(1, 3, 1))
+
+			if test.Found {
+				t.Assert().Equal([][]int{{1, 2}}, routeIDs(routes))
+			} else {
+				t.Assert().Empty(routes)
+			}
+		})
+	}
+}
+
+func (s *RoutesTestSuite) TestSortRoutes(t provider.T) {
+	newTestRoute := func(id int, departure time.Duration, minutes, price int) route {
+		return newRoute([]*models.Flight{routeFlight(id, 1, 2, departure, minutes, price)}, models.FareEconomy)
+	}
+
+	cases := map[string]struct {
+		SortBy string
+		Routes []int
+	}{
+		// При равной цене раньше идёт более быстрый маршрут
+		"price": {SortBy: models.RouteSortByPrice, Routes: []int{3, 2, 4, 1}},
+		// При равном времени в пути раньше идёт более дешёвый маршрут
+		"duration": {SortBy: models.RouteSortByDuration, Routes: []int{1, 3, 4, 2}},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			routes := []route{
+				newTestRoute(1, 8*time.Hour, 120, 300),
+				newTestRoute(2, 8*time.Hour, 300, 100),
+				newTestRoute(3, 8*time.Hour, 180, 100),
+				newTestRoute(4, 6*time.Hour, 180, 200),
+			}
+
+			sortRoutes(routes, test.SortBy)
+
+			ids := make([]int, len(routes))
+			for i, r := range routes {
+				ids[i] = r.flights[0].ID
+			}
+			t.Assert().Equal(test.Routes, ids)
+		})
+	}
+
+	t.Run("departure breaks ties", func(t provider.T) {
+		routes := []route{
+			newTestRoute(1, 10*time.Hour, 120, 100),
+			newTestRoute(2, 8*time.Hour, 120, 100),
+		}
+
+		sortRoutes(routes, models.RouteSortByPrice)
+
+		t.Assert().Equal(2, routes[0].flights[0].ID)
+		t.Assert().Equal(1, routes[1].flights[0].ID)
+	})
+}
+
+func (s *RoutesTestSuite) TestFindRoutesDepartureWindow(t provider.T) {
+	cases := map[string]struct {
+		MaxConnections int
+		To             time.Time
+	}{
+		"direct": {
+			MaxConnections: 0,
+			To:             routeDate.AddDate(0, 0, 1),
+		},
+		"two connections": {
+			MaxConnections: 2,
+			To:             routeDate.AddDate(0, 0, 1).Add(2 * (DefaultMaxConnection + maxLegDuration)),
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			s.flightRepoMock.On("GetDepartures", routeDate, test.To).Return([]*models.Flight{}, nil).Once()
+
+			routes, err := s.uc.FindRoutes(models.RouteQuery{
+				FromAirportID:  1,
+				ToAirportID:    2,
+				Date:           routeDate,
+				MaxConnections: test.MaxConnections,
+			})
+
+			t.Assert().NoError(err)
+			t.Assert().Empty(routes)
+		})
+	}
+}
+
+func (s *RoutesTestSuite) TestFindRoutes(t provider.T) {
+	flights := []*models.Flight{
+		routeFlight(1, 1, 2, 8*time.Hour, 60, 100),
+		routeFlight(2, 2, 3, 10*time.Hour, 90, 150),
+		routeFlight(3, 1, 3, 9*time.Hour, 120, 400),
+	}
+
+	s.flightRepoMock.On("GetDepartures", routeDate, mock.AnythingOfType("time.Time")).Return(flights, nil)
+	s.airportRepoMock.On("GetByIDs", mock.Anything).Return([]*models.Airport{
+		{ID: 1, City: "Москва", Name: "Шереметьево"},
+		{ID: 2, City: "Казань", Name: "Казань"},
+		{ID: 3, City: "Сочи", Name: "Сочи"},
+	}, nil)
+
+	routes, err := s.uc.FindRoutes(models.RouteQuery{
+		FromAirportID:  1,
+		ToAirportID:    3,
+		Date:           routeDate,
+		MaxConnections: 1,
+	})
+
+	t.Require().NoError(err)
+	t.Require().Len(routes, 2)
+
+	t.Assert().Equal(1, routes[0].Connections)
+	t.Assert().Equal(250, routes[0].TotalPrice)
+	t.Assert().Equal(210, routes[0].DurationMinutes)
+	t.Assert().Equal(60, routes[0].Legs[1].ConnectionMinutes)
+	t.Assert().Equal("Москва Шереметьево", routes[0].Legs[0].FromAirport)
+	t.Assert().Equal("Сочи Сочи", routes[0].Legs[1].ToAirport)
+
+	t.Assert().Equal(0, routes[1].Connections)
+	t.Assert().Equal(400, routes[1].TotalPrice)
+}
@@ -1,32 +1,177 @@
 package usecase
 
 import (
+	"time"
+
+	airportRep "flight_booking_system/flightService/internal/airport/repository"
 	flightRep "flight_booking_system/flightService/internal/flight/repository"
+	seatRep "flight_booking_system/flightService/internal/seat/repository"
 	"flight_booking_system/flightService/models"
 	"github.com/pkg/errors"
 )
 
+var (
+	ErrInvalidFlight = errors.New("invalid flight")
+	ErrInvalidFilter = errors.New("invalid flight filter")
+	// ErrIllegalStatus — из текущего статуса рейса в запрошенный перейти нельзя.
+	ErrIllegalStatus = errors.New("illegal flight status transition")
+)
+
+// maxGateLength — длина номера выхода на посадку в схеме БД.
+const maxGateLength = 10
+
+// statusTransitions — допустимые смены оперативного статуса рейса. DEPARTED и
+// CANCELLED — конечные статусы.
+var statusTransitions = map[string][]string{
+	models.FlightScheduled: {models.FlightDelayed, models.FlightBoarding, models.FlightCancelled},
+	models.FlightDelayed:   {models.FlightScheduled, models.FlightBoarding, models.FlightCancelled},
+	models.FlightBoarding:  {models.FlightDeparted, models.FlightDelayed, models.FlightCancelled},
+}
+
+func knownStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok || status == models.FlightDeparted || status == models.FlightCancelled
+}
+
+func canChangeStatus(from, to string) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
 type FlightUseCaseI interface {
 	Create(p *models.Flight) error
 	Get(id int) (*models.Flight, error)
 	Update(p *models.Flight) error
+	UpdateStatus(flightID int, update models.FlightStatusUpdate) (*models.Flight, error)
 	Delete(id int) error
 	GetAll(flightNumber string) ([]*models.FlightDTO, error)
 	GetAllPaginate(offset, limit int) ([]*models.FlightDTO, error)
+	GetBatch(flightNumbers []string) ([]*models.FlightDTO, error)
+	Search(filter models.FlightFilter) (*models.FlightsPage, error)
+	FindRoutes(query models.RouteQuery) ([]*models.RouteDTO, error)
+	GetFares(flightID int) ([]*models.FareDTO, error)
+	SaveFare(fare *models.Fare) error
+	ReserveSeat(flightID int, fareClass string, ticketUID string) error
+	ReleaseSeat(ticketUID string) error
 }
 
 type flightUseCase struct {
-	flightRepository flightRep.FlightRepositoryI
+	flightRepository  flightRep.FlightRepositoryI
+	airportRepository airportRep.AirportRepositoryI
+	seatRepository    seatRep.SeatRepositoryI
 }
 
-func New(aRep flightRep.FlightRepositoryI) FlightUseCaseI {
+func New(fRep flightRep.FlightRepositoryI, aRep airportRep.AirportRepositoryI, sRep seatRep.SeatRepositoryI) FlightUseCaseI {
 	return &flightUseCase{
-		flightRepository: aRep,
+		flightRepository:  fRep,
+		airportRepository: aRep,
+		seatRepository:    sRep,
 	}
 }
 
+func (pUC *flightUseCase) validate(p *models.Flight) error {
+	switch {
+	case p.FlightNumber == "":
+		return errors.Wrap(ErrInvalidFlight, "flight number is required")
+	case p.Price <= 0:
+		return errors.Wrap(ErrInvalidFlight, "price must be positive")
+	case p.Capacity <= 0:
+		return errors.Wrap(ErrInvalidFlight, "capacity must be positive")
+	case p.DurationMinutes <= 0:
+		return errors.Wrap(ErrInvalidFlight, "duration must be positive")
+	case p.Capacity < p.SeatsReserved:
+		return errors.Wrapf(ErrInvalidFlight, "capacity is less than %d already reserved seats", p.SeatsReserved)
+	case !p.DateTime.After(time.Now()):
+		return errors.Wrap(ErrInvalidFlight, "flight date must be in the future")
+	case p.FromAirportID == p.ToAirportID:
+		return errors.Wrap(ErrInvalidFlight, "departure and arrival airports must differ")
+	}
+
+	for _, airportID := range []int{p.FromAirportID, p.ToAirportID} {
+		_, err := pUC.airportRepository.Get(airportID)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidFlight, "airport %d not found", airportID)
+		}
+	}
+
+	if p.AircraftTypeID != nil {
+		_, err := pUC.seatRepository.GetAircraftType(*p.AircraftTypeID)
+		if err != nil {
+			return errors.Wrapf(ErrInvalidFlight, "aircraft type %d not found", *p.AircraftTypeID)
+		}
+	}
+
+	return nil
+}
+
+func validateFare(fare *models.Fare) error {
+	switch {
+	case models.FareCabin[fare.FareClass] == "":
+		return errors.Wrapf(ErrInvalidFlight, "unknown fare class %q", fare.FareClass)
+	case fare.Price <= 0:
+		return errors.Wrap(ErrInvalidFlight, "fare price must be positive")
+	case fare.Capacity <= 0:
+		return errors.Wrap(ErrInvalidFlight, "fare capacity must be positive")
+	case fare.ExchangeFee < 0:
+		return errors.Wrap(ErrInvalidFlight, "exchange fee must not be negative")
+	}
+
+	return nil
+}
+
+// Create без явных классов обслуживания заводит один эконом-класс по цене и
+// вместимости рейса. Если эконом-класс передан, цена рейса берётся из него.
+// Вместимость рейса — сумма квот его классов обслуживания.
 func (pUC *flightUseCase) Create(p *models.Flight) error {
-	err := pUC.flightRepository.Create(p)
+	p.SeatsReserved = 0
+	p.Status = models.FlightScheduled
+	p.EstimatedDeparture = nil
+	p.EstimatedArrival = nil
+	p.Gate = ""
+
+	if len(p.Fares) == 0 {
+		p.Fares = []models.Fare{{
+			FareClass:  models.FareEconomy,
+			Price:      p.Price,
+			Capacity:   p.Capacity,
+			Refundable: true,
+		}}
+	}
+
+	capacity := 0
+	seen := make(map[string]bool, len(p.Fares))
+	for i := range p.Fares {
+		fare := &p.Fares[i]
+		fare.FlightID = 0
+		fare.SeatsReserved = 0
+
+		err := validateFare(fare)
+		if err != nil {
+			return err
+		}
+		if seen[fare.FareClass] {
+			return errors.Wrapf(ErrInvalidFlight, "fare class %s is duplicated", fare.FareClass)
+		}
+		seen[fare.FareClass] = true
+
+		if fare.FareClass == models.FareEconomy {
+			p.Price = fare.Price
+		}
+		capacity += fare.Capacity
+	}
+	p.Capacity = capacity
+
+	err := pUC.validate(p)
+	if err != nil {
+		return err
+	}
+
+	err = pUC.flightRepository.Create(p)
 
 	if err != nil {
 		return errors.Wrap(err, "flightUseCase.Create error")
@@ -45,13 +190,47 @@ func (pUC *flightUseCase) Get(id int) (*models.Flight, error) {
 	return resFlight, nil
 }
 
+// Update меняет только переданные поля, проверяется итоговый рейс.
+// Вместимость складывается из квот классов и меняется через SaveFare.
 func (pUC *flightUseCase) Update(p *models.Flight) error {
-	_, err := pUC.flightRepository.Get(p.ID)
+	existing, err := pUC.flightRepository.Get(p.ID)
 
 	if err != nil {
 		return errors.Wrap(err, "flightUseCase.Update error: Flight not found")
 	}
 
+	merged := *existing
+	if p.FlightNumber != "" {
+		merged.FlightNumber = p.FlightNumber
+	}
+	if !p.DateTime.IsZero() {
+		merged.DateTime = p.DateTime
+	}
+	if p.FromAirportID != 0 {
+		merged.FromAirportID = p.FromAirportID
+	}
+	if p.ToAirportID != 0 {
+		merged.ToAirportID = p.ToAirportID
+	}
+	if p.Price != 0 {
+		merged.Price = p.Price
+	}
+	if p.Capacity != 0 && p.Capacity != existing.Capacity {
+		return errors.Wrap(ErrInvalidFlight, "capacity is the sum of fare capacities, change fares instead")
+	}
+	if p.AircraftTypeID != nil {
+		merged.AircraftTypeID = p.AircraftTypeID
+	}
+	if p.DurationMinutes != 0 {
+		merged.DurationMinutes = p.DurationMinutes
+	}
+
+	err = pUC.validate(&merged)
+	if err != nil {
+		return err
+	}
+
+	*p = merged
 	err = pUC.flightRepository.Update(p)
 
 	if err != nil {
@@ -61,6 +240,61 @@ func (pUC *flightUseCase) Update(p *models.Flight) error {
 	return nil
 }
 
+// UpdateStatus меняет оперативный статус рейса. Тот же статус можно передать
+// повторно, чтобы сменить расчётное время или выход; для отменённого или
+// вылетевшего рейса повтор ничего не меняет. Возврат в SCHEDULED сбрасывает
+// расчётное время, а без расчётного прилёта он считается от расчётного вылета.
+func (pUC *flightUseCase) UpdateStatus(flightID int, update models.FlightStatusUpdate) (*models.Flight, error) {
+	if !knownStatus(update.Status) {
+		return nil, errors.Wrapf(ErrInvalidFlight, "unknown flight status %q", update.Status)
+	}
+
+	flight, err := pUC.flightRepository.Get(flightID)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightUseCase.UpdateStatus error: Flight not found")
+	}
+
+	switch {
+	case update.Status == flight.Status && flight.Closed():
+		return flight, nil
+	case update.Status != flight.Status && !canChangeStatus(flight.Status, update.Status):
+		return nil, errors.Wrapf(ErrIllegalStatus, "flightUseCase.UpdateStatus error: %s -> %s", flight.Status, update.Status)
+	}
+
+	if update.EstimatedDeparture != nil {
+		flight.EstimatedDeparture = update.EstimatedDeparture
+		arrival := update.EstimatedDeparture.Add(time.Duration(flight.DurationMinutes) * time.Minute)
+		flight.EstimatedArrival = &arrival
+	}
+	if update.EstimatedArrival != nil {
+		flight.EstimatedArrival = update.EstimatedArrival
+	}
+	if update.Gate != "" {
+		flight.Gate = update.Gate
+	}
+	if update.Status == models.FlightScheduled {
+		flight.EstimatedDeparture = nil
+		flight.EstimatedArrival = nil
+	}
+
+	switch {
+	case update.Status == models.FlightDelayed && (flight.EstimatedDeparture == nil || !flight.EstimatedDeparture.After(flight.DateTime)):
+		return nil, errors.Wrap(ErrInvalidFlight, "delayed flight needs estimated departure after the scheduled one")
+	case flight.EstimatedDeparture != nil && flight.EstimatedArrival != nil && !flight.EstimatedArrival.After(*flight.EstimatedDeparture):
+		return nil, errors.Wrap(ErrInvalidFlight, "estimated arrival must be after estimated departure")
+	case len(flight.Gate) > maxGateLength:
+		return nil, errors.Wrapf(ErrInvalidFlight, "gate must be at most %d characters", maxGateLength)
+	}
+
+	flight.Status = update.Status
+	err = pUC.flightRepository.UpdateStatus(flight)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightUseCase.UpdateStatus error")
+	}
+
+	return flight, nil
+}
+
 func (pUC *flightUseCase) Delete(id int) error {
 	_, err := pUC.flightRepository.Get(id)
 
@@ -103,3 +337,120 @@ func (pUC *flightUseCase) GetAllPaginate(offset, limit int) ([]*models.FlightDTO
 
 	return flights, nil
 }
+
+func (pUC *flightUseCase) GetBatch(flightNumbers []string) ([]*models.FlightDTO, error) {
+	if len(flightNumbers) == 0 {
+		return []*models.FlightDTO{}, nil
+	}
+
+	flights, err := pUC.flightRepository.GetAllByFlightNumbers(flightNumbers)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "flightUseCase.GetBatch error")
+	}
+
+	return flights, nil
+}
+
+func (pUC *flightUseCase) Search(filter models.FlightFilter) (*models.FlightsPage, error) {
+	switch {
+	case filter.SortBy != "" && filter.SortBy != models.SortByDate && filter.SortBy != models.SortByPrice:
+		return nil, errors.Wrapf(ErrInvalidFilter, "unknown sort field %q", filter.SortBy)
+	case filter.MaxPrice < 0:
+		return nil, errors.Wrap(ErrInvalidFilter, "max price must not be negative")
+	case !filter.DateFrom.IsZero() && !filter.DateTo.IsZero() && filter.DateFrom.After(filter.DateTo):
+		return nil, errors.Wrap(ErrInvalidFilter, "dateFrom must not be after dateTo")
+	}
+
+	flights, total, err := pUC.flightRepository.Search(filter)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "flightUseCase.Search error")
+	}
+
+	if flights == nil {
+		flights = []*models.FlightDTO{}
+	}
+
+	return &models.FlightsPage{Items: flights, Total: total}, nil
+}
+
+func (pUC *flightUseCase) GetFares(flightID int) ([]*models.FareDTO, error) {
+	_, err := pUC.flightRepository.Get(flightID)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightUseCase.GetFares error")
+	}
+
+	fares, err := pUC.flightRepository.GetFares(flightID)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightUseCase.GetFares error")
+	}
+
+	res := make([]*models.FareDTO, 0, len(fares))
+	for _, fare := range fares {
+		res = append(res, models.FareToDTO(fare))
+	}
+
+	return res, nil
+}
+
+// SaveFare не даёт уменьшить квоту класса ниже уже проданных мест.
+func (pUC *flightUseCase) SaveFare(fare *models.Fare) error {
+	err := validateFare(fare)
+	if err != nil {
+		return err
+	}
+
+	fares, err := pUC.flightRepository.GetFares(fare.FlightID)
+	if err != nil {
+		return errors.Wrap(err, "flightUseCase.SaveFare error")
+	}
+
+	fare.SeatsReserved = 0
+	for _, existing := range fares {
+		if existing.FareClass == fare.FareClass {
+			fare.SeatsReserved = existing.SeatsReserved
+		}
+	}
+	if fare.Capacity < fare.SeatsReserved {
+		return errors.Wrapf(ErrInvalidFlight, "fare capacity is less than %d already reserved seats", fare.SeatsReserved)
+	}
+
+	err = pUC.flightRepository.SaveFare(fare)
+	if err != nil {
+		return errors.Wrap(err, "flightUseCase.SaveFare error")
+	}
+
+	return nil
+}
+
+// ReserveSeat без класса обслуживания бронирует эконом.
+func (pUC *flightUseCase) ReserveSeat(flightID int, fareClass string, ticketUID string) error {
+	if ticketUID == "" {
+		return errors.Wrap(ErrInvalidFlight, "ticket uid is required")
+	}
+	if fareClass == "" {
+		fareClass = models.FareEconomy
+	}
+	if models.FareCabin[fareClass] == "" {
+		return errors.Wrapf(ErrInvalidFlight, "unknown fare class %q", fareClass)
+	}
+
+	err := pUC.flightRepository.ReserveSeat(flightID, fareClass, ticketUID)
+
+	if err != nil {
+		return errors.Wrap(err, "flightUseCase.ReserveSeat error")
+	}
+
+	return nil
+}
+
+func (pUC *flightUseCase) ReleaseSeat(ticketUID string) error {
+	err := pUC.flightRepository.ReleaseSeat(ticketUID)
+
+	if err != nil {
+		return errors.Wrap(err, "flightUseCase.ReleaseSeat error")
+	}
+
+	return nil
+}
@@ -1,21 +1,24 @@
 package usecase
 
 import (
+	airportMocks "flight_booking_system/flightService/internal/airport/repository/mocks"
+	flightRep "flight_booking_system/flightService/internal/flight/repository"
 	flightMocks "flight_booking_system/flightService/internal/flight/repository/mocks"
 	"flight_booking_system/flightService/internal/testBuilders"
 	"flight_booking_system/flightService/models"
 	"github.com/bxcodec/faker"
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/ozontech/allure-go/pkg/framework/suite"
-	"github.com/pkg/errors"
 	"testing"
+	"time"
 )
 
 type FlightTestSuite struct {
 	suite.Suite
-	uc             FlightUseCaseI
-	flightRepoMock *flightMocks.FlightRepositoryI
-	flightBuilder  *testBuilders.FlightBuilder
+	uc              FlightUseCaseI
+	flightRepoMock  *flightMocks.FlightRepositoryI
+	airportRepoMock *airportMocks.AirportRepositoryI
+	flightBuilder   *testBuilders.FlightBuilder
 }
 
 func TestFlightTestSuite(t *testing.T) {
@@ -24,44 +27,80 @@ func TestFlightTestSuite(t *testing.T) {
 
 func (s *FlightTestSuite) BeforeEach(t provider.T) {
 	s.flightRepoMock = flightMocks.NewFlightRepositoryI(t)
-	s.uc = New(s.flightRepoMock)
+	s.airportRepoMock = airportMocks.NewAirportRepositoryI(t)
+	s.uc = New(s.flightRepoMock, s.airportRepoMock, nil)
 	s.flightBuilder = testBuilders.NewFlightBuilder()
 }
 
-func (s *FlightTestSuite) TestCreateFlight(t provider.T) {
-	flight := s.flightBuilder.WithID(1).
-		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
+func (s *FlightTestSuite) validFlight() models.Flight {
+	return s.flightBuilder.WithID(1).
+		WithFlightNumber("AFL031").
+		WithDateTime(time.Now().AddDate(0, 1, 0).Truncate(time.Minute)).
+		WithFromAirportID(1).
+		WithToAirportID(2).
+		WithPrice(1500).
+		WithCapacity(100).
+		WithDurationMinutes(120).
+		WithStatus(models.FlightScheduled).
 		Build()
+}
+
+func (s *FlightTestSuite) expectAirports() {
+	s.airportRepoMock.On("Get", 1).Return(&models.Airport{ID: 1}, nil)
+	s.airportRepoMock.On("Get", 2).Return(&models.Airport{ID: 2}, nil)
+}
+
+func (s *FlightTestSuite) TestCreateFlight(t provider.T) {
+	flight := s.validFlight()
 
+	s.expectAirports()
 	s.flightRepoMock.On("Create", &flight).Return(nil)
 	err := s.uc.Create(&flight)
 
 	t.Assert().NoError(err)
 	t.Assert().Equal(flight.ID, 1)
+	t.Assert().Equal(models.FlightScheduled, flight.Status)
+	t.Assert().Len(flight.Fares, 1)
+	t.Assert().Equal(models.FareEconomy, flight.Fares[0].FareClass)
+	t.Assert().Equal(flight.Price, flight.Fares[0].Price)
+}
+
+func (s *FlightTestSuite) TestCreateFlightCapacityFromFares(t provider.T) {
+	flight := s.validFlight()
+	flight.Fares = []models.Fare{
+		{FareClass: models.FareEconomy, Price: 1500, Capacity: 132},
+		{FareClass: models.FareComfort, Price: 2500, Capacity: 30},
+		{FareClass: models.FareBusiness, Price: 4500, Capacity: 12},
+	}
+
+	s.expectAirports()
+	s.flightRepoMock.On("Create", &flight).Return(nil)
+	err := s.uc.Create(&flight)
+
+	t.Require().NoError(err)
+	t.Assert().Equal(174, flight.Capacity)
+}
+
+func (s *FlightTestSuite) TestUpdateFlightCapacity(t provider.T) {
+	existing := s.validFlight()
+	flight := models.Flight{ID: existing.ID, Capacity: existing.Capacity + 10}
+
+	s.flightRepoMock.On("Get", existing.ID).Return(&existing, nil)
+	err := s.uc.Update(&flight)
+
+	t.Assert().ErrorIs(err, ErrInvalidFlight)
 }
 
 func (s *FlightTestSuite) TestUpdateFlight(t provider.T) {
-	var err error
-	flight := s.flightBuilder.WithID(1).
-		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
-		Build()
+	flight := s.validFlight()
+	existing := flight
 
 	notFoundFlight := s.flightBuilder.WithID(0).Build()
 
-	s.flightRepoMock.On("Get", flight.ID).Return(&flight, nil)
+	s.expectAirports()
+	s.flightRepoMock.On("Get", flight.ID).Return(&existing, nil)
 	s.flightRepoMock.On("Update", &flight).Return(nil)
-	s.flightRepoMock.On("Get", notFoundFlight.ID).Return(&notFoundFlight, errors.Wrap(err, "Flight not found"))
-	s.flightRepoMock.On("Update", &notFoundFlight).Return(errors.Wrap(err, "Flight not found"))
+	s.flightRepoMock.On("Get", notFoundFlight.ID).Return(nil, flightRep.ErrFlightNotFound)
 
 	cases := map[string]struct {
 		ArgData *models.Flight
@@ -73,7 +112,7 @@ func (s *FlightTestSuite) TestUpdateFlight(t provider.T) {
 		},
 		"Flight not found": {
 			ArgData: &notFoundFlight,
-			Error:   errors.Wrap(err, "Flight not found"),
+			Error:   flightRep.ErrFlightNotFound,
 		},
 	}
 
@@ -86,14 +125,7 @@ func (s *FlightTestSuite) TestUpdateFlight(t provider.T) {
 }
 
 func (s *FlightTestSuite) TestGetFlight(t provider.T) {
-	flight := s.flightBuilder.WithID(1).
-		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
-		Build()
+	flight := s.validFlight()
 
 	s.flightRepoMock.On("Get", flight.ID).Return(&flight, nil)
 	result, err := s.uc.Get(flight.ID)
@@ -103,22 +135,13 @@ func (s *FlightTestSuite) TestGetFlight(t provider.T) {
 }
 
 func (s *FlightTestSuite) TestDeleteFlight(t provider.T) {
-	var err error
-	flight := s.flightBuilder.WithID(1).
-		WithID(1).
-		WithUID("uid").
-		WithUsername("username").
-		WithFlightNumber("flightNumber").
-		WithPrice(20).
-		WithStatus("status").
-		Build()
+	flight := s.validFlight()
 
 	notFoundFlight := s.flightBuilder.WithID(0).Build()
 
 	s.flightRepoMock.On("Get", flight.ID).Return(&flight, nil)
 	s.flightRepoMock.On("Delete", flight.ID).Return(nil)
-	s.flightRepoMock.On("Get", notFoundFlight.ID).Return(&notFoundFlight, errors.Wrap(err, "Flight not found"))
-	s.flightRepoMock.On("Delete", notFoundFlight.ID).Return(errors.Wrap(err, "Flight not found"))
+	s.flightRepoMock.On("Get", notFoundFlight.ID).Return(nil, flightRep.ErrFlightNotFound)
 
 	cases := map[string]struct {
 		FlightID int
@@ -130,7 +153,7 @@ func (s *FlightTestSuite) TestDeleteFlight(t provider.T) {
 		},
 		"Flight not found": {
 			FlightID: notFoundFlight.ID,
-			Error:    errors.Wrap(err, "Flight not found"),
+			Error:    flightRep.ErrFlightNotFound,
 		},
 	}
 
@@ -143,19 +166,19 @@ func (s *FlightTestSuite) TestDeleteFlight(t provider.T) {
 }
 
 func (s *FlightTestSuite) TestGetAll(t provider.T) {
-	flights := make([]models.Flight, 0, 10)
+	flights := make([]models.FlightDTO, 0, 10)
 	err := faker.FakeData(&flights)
 	t.Assert().NoError(err)
 
-	flightsPtr := make([]*models.Flight, len(flights))
-	for i, flight := range flights {
-		flightsPtr[i] = &flight
+	flightsPtr := make([]*models.FlightDTO, len(flights))
+	for i := range flights {
+		flightsPtr[i] = &flights[i]
 	}
 
 	s.flightRepoMock.On("GetAll").Return(flightsPtr, nil)
 
 	cases := map[string]struct {
-		Flights []models.Flight
+		Flights []models.FlightDTO
 		Error   error
 	}{
 		"success": {
@@ -166,7 +189,7 @@ func (s *FlightTestSuite) TestGetAll(t provider.T) {
 
 	for name, test := range cases {
 		t.Run(name, func(t provider.T) {
-			resFlights, err := s.uc.GetAll()
+			resFlights, err := s.uc.GetAll("")
 			t.Assert().ErrorIs(err, test.Error)
 			t.Assert().Equal(flightsPtr, resFlights)
 		})
@@ -0,0 +1,116 @@
+package delivery
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	flightRep "flight_booking_system/flightService/internal/flight/repository"
+	seatRep "flight_booking_system/flightService/internal/seat/repository"
+	seatUseCase "flight_booking_system/flightService/internal/seat/usecase"
+	"flight_booking_system/flightService/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+type SeatHandler struct {
+	SeatUseCase seatUseCase.SeatUseCaseI
+	Logger      logger.Logger
+}
+
+type seatRequest struct {
+	SeatNumber string `json:"seatNumber"`
+}
+
+func (sh *SeatHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	sh.Logger.Infow(msg,
+		"err:", err.Error())
+
+	switch {
+	case errors.Is(err, flightRep.ErrFlightNotFound):
+		http.Error(w, "flight not found", http.StatusNotFound)
+	case errors.Is(err, seatRep.ErrReservationNotFound):
+		http.Error(w, "ticket has no seat on flight", http.StatusNotFound)
+	case errors.Is(err, seatRep.ErrNoSeatMap):
+		http.Error(w, "flight has no seat map", http.StatusNotFound)
+	case errors.Is(err, seatRep.ErrSeatNotFound):
+		http.Error(w, "no such seat on aircraft", http.StatusBadRequest)
+	case errors.Is(err, seatRep.ErrWrongCabin):
+		http.Error(w, "seat is not available for the ticket fare class", http.StatusBadRequest)
+	case errors.Is(err, seatRep.ErrSeatTaken):
+		http.Error(w, "seat is already taken", http.StatusConflict)
+	default:
+		http.Error(w, msg, http.StatusInternalServerError)
+	}
+}
+
+// GetSeatMap — схема салона рейса с занятыми и свободными креслами.
+func (sh *SeatHandler) GetSeatMap(w http.ResponseWriter, r *http.Request) {
+	flightId, err := strconv.Atoi(r.PathValue("flightId"))
+	if err != nil {
+		sh.Logger.Infow("fail to convert id to int",
+			"err:", err.Error())
+		http.Error(w, "bad flight id", http.StatusBadRequest)
+		return
+	}
+
+	seats, err := sh.SeatUseCase.GetSeatMap(flightId)
+	if err != nil {
+		sh.writeError(w, err, "can`t get seat map")
+		return
+	}
+
+	resp, err := json.Marshal(seats)
+	if err != nil {
+		sh.Logger.Errorw("can`t marshal seats",
+			"err:", err.Error())
+		http.Error(w, "can`t make seats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		sh.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		return
+	}
+}
+
+// AssignSeat выбирает кресло для билета, у которого уже есть место на рейсе.
+func (sh *SeatHandler) AssignSeat(w http.ResponseWriter, r *http.Request) {
+	seat := seatRequest{}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		sh.Logger.Errorw("can`t read body of request",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	err = r.Body.Close()
+	if err != nil {
+		sh.Logger.Errorw("can`t close body of request", "err:", err.Error())
+		http.Error(w, "close error", http.StatusInternalServerError)
+		return
+	}
+
+	err = json.Unmarshal(body, &seat)
+	if err != nil {
+		sh.Logger.Infow("can`t unmarshal form",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	err = sh.SeatUseCase.AssignSeat(r.PathValue("ticketUid"), seat.SeatNumber)
+	if err != nil {
+		sh.writeError(w, err, "can`t assign seat")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
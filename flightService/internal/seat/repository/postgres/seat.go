@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	flightRep "flight_booking_system/flightService/internal/flight/repository"
+	"flight_booking_system/flightService/internal/seat/repository"
+	"flight_booking_system/flightService/models"
+	"flight_booking_system/flightService/pkg/logger"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type pgSeatRepo struct {
+	Logger logger.Logger
+	DB     *gorm.DB
+}
+
+func New(logger logger.Logger, db *gorm.DB) repository.SeatRepositoryI {
+	return &pgSeatRepo{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (pr *pgSeatRepo) GetAircraftType(id int) (*models.AircraftType, error) {
+	var p models.AircraftType
+	tx := pr.DB.Where("id = ?", id).Take(&p)
+
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgSeatRepo.GetAircraftType error")
+	}
+
+	return &p, nil
+}
+
+func getFlight(db *gorm.DB, flightID int) (*models.Flight, error) {
+	var flight models.Flight
+	tx := db.Where("id = ?", flightID).Take(&flight)
+
+	if errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		return nil, flightRep.ErrFlightNotFound
+	}
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if flight.AircraftTypeID == nil {
+		return nil, repository.ErrNoSeatMap
+	}
+
+	return &flight, nil
+}
+
+// GetSeatMap возвращает кресла самолёта рейса с отметкой, свободно ли кресло.
+func (pr *pgSeatRepo) GetSeatMap(flightID int) ([]*models.SeatDTO, error) {
+	flight, err := getFlight(pr.DB, flightID)
+	if err != nil {
+		return nil, errors.Wrap(err, "pgSeatRepo.GetSeatMap error")
+	}
+
+	var seats []*models.SeatDTO
+	tx := pr.DB.Table("aircraft_seat AS s").
+		Select("s.seat_number, s.seat_row AS row, s.letter, s.cabin_class, s.exit_row, r.ticket_uid IS NULL AS available").
+		Joins("LEFT JOIN seat_reservation r ON r.flight_id = ? AND r.seat_number = s.seat_number", flightID).
+		Where("s.aircraft_type_id = ?", *flight.AircraftTypeID).
+		Order("s.seat_row, s.letter").
+		Scan(&seats)
+
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgSeatRepo.GetSeatMap error")
+	}
+
+	return seats, nil
+}
+
+// AssignSeat закрепляет кресло за билетом или, если seatNumber пуст, снимает
+// выбор. Два билета не получат одно кресло: это гарантирует уникальный индекс
+// (flight_id, seat_number) в seat_reservation.
+func (pr *pgSeatRepo) AssignSeat(ticketUID string, seatNumber string) error {
+	err := pr.DB.Transaction(func(tx *gorm.DB) error {
+		var reservation models.SeatReservation
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("ticket_uid = ?", ticketUID).Take(&reservation)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return repository.ErrReservationNotFound
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+
+		var seat *string
+		if seatNumber != "" {
+			flight, err := getFlight(tx, reservation.FlightID)
+			if err != nil {
+				return err
+			}
+
+			var found models.Seat
+			res = tx.Where("aircraft_type_id = ? AND seat_number = ?", *flight.AircraftTypeID, seatNumber).
+				Limit(1).Find(&found)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return repository.ErrSeatNotFound
+			}
+			if found.CabinClass != models.FareCabin[reservation.FareClass] {
+				return repository.ErrWrongCabin
+			}
+
+			seat = &seatNumber
+		}
+
+		res = tx.Model(&models.SeatReservation{}).Where("ticket_uid = ?", ticketUID).
+			UpdateColumn("seat_number", seat)
+		if errors.Is(res.Error, gorm.ErrDuplicatedKey) {
+			return repository.ErrSeatTaken
+		}
+
+		return res.Error
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "pgSeatRepo.AssignSeat error")
+	}
+
+	return nil
+}
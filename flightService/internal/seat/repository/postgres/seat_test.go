@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"database/sql"
+	flightRep "flight_booking_system/flightService/internal/flight/repository"
+	seatRep "flight_booking_system/flightService/internal/seat/repository"
+	"flight_booking_system/flightService/models"
+	"flight_booking_system/flightService/pkg/logger"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type SeatRepoTestSuite struct {
+	suite.Suite
+	db     *sql.DB
+	gormDB *gorm.DB
+	mock   sqlmock.Sqlmock
+	repo   seatRep.SeatRepositoryI
+}
+
+func TestSeatRepoSuite(t *testing.T) {
+	suite.RunSuite(t, new(SeatRepoTestSuite))
+}
+
+func (s *SeatRepoTestSuite) BeforeEach(t provider.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("error while creating sql mock")
+	}
+
+	dialector := postgres.New(postgres.Config{
+		DSN:                  "sqlmock_db_0",
+		DriverName:           "postgres",
+		Conn:                 db,
+		PreferSimpleProtocol: true,
+	})
+	gormDB, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatal("error gorm open")
+	}
+
+	var logger logger.Logger
+
+	s.db = db
+	s.gormDB = gormDB
+	s.mock = mock
+
+	s.repo = New(logger, gormDB)
+}
+
+func (s *SeatRepoTestSuite) AfterEach(t provider.T) {
+	err := s.mock.ExpectationsWereMet()
+	t.Assert().NoError(err)
+	s.db.Close()
+}
+
+func flightRows(aircraftTypeID any) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "flight_number", "aircraft_type_id"}).
+		AddRow(1, "AFL031", aircraftTypeID)
+}
+
+func reservationRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"ticket_uid", "flight_id", "created_at", "seat_number", "fare_class"}).
+		AddRow("uid", 1, time.Now(), nil, models.FareEconomy)
+}
+
+func (s *SeatRepoTestSuite) TestGetSeatMap(t provider.T) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "flight" WHERE id = $1 LIMIT $2`)).
+		WithArgs(1, 1).
+		WillReturnRows(flightRows(1))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT s.seat_number, s.seat_row AS row, s.letter, s.cabin_class, s.exit_row, r.ticket_uid IS NULL AS available FROM aircraft_seat AS s LEFT JOIN seat_reservation r ON r.flight_id = $1 AND r.seat_number = s.seat_number WHERE s.aircraft_type_id = $2 ORDER BY s.seat_row, s.letter`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"seat_number", "row", "letter", "cabin_class", "exit_row", "available"}).
+			AddRow("1A", 1, "A", models.CabinBusiness, false, false).
+			AddRow("12A", 12, "A", models.CabinEconomy, true, true))
+
+	seats, err := s.repo.GetSeatMap(1)
+	t.Assert().NoError(err)
+	t.Assert().Equal([]*models.SeatDTO{
+		{SeatNumber: "1A", Row: 1, Letter: "A", CabinClass: models.CabinBusiness, ExitRow: false, Available: false},
+		{SeatNumber: "12A", Row: 12, Letter: "A", CabinClass: models.CabinEconomy, ExitRow: true, Available: true},
+	}, seats)
+}
+
+func (s *SeatRepoTestSuite) TestGetSeatMapWithoutAircraft(t provider.T) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "flight" WHERE id = $1 LIMIT $2`)).
+		WillReturnRows(flightRows(nil))
+
+	_, err := s.repo.GetSeatMap(1)
+	t.Assert().ErrorIs(err, seatRep.ErrNoSeatMap)
+}
+
+func (s *SeatRepoTestSuite) TestGetSeatMapFlightNotFound(t provider.T) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "flight" WHERE id = $1 LIMIT $2`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := s.repo.GetSeatMap(1)
+	t.Assert().ErrorIs(err, flightRep.ErrFlightNotFound)
+}
+
+// expectSeatLookup ожидает поиск кресла 12A; пустой cabin — кресла нет в салоне.
+func (s *SeatRepoTestSuite) expectSeatLookup(cabin string) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "seat_reservation" WHERE ticket_uid = $1 LIMIT $2 FOR UPDATE`)).
+		WithArgs("uid", 1).
+		WillReturnRows(reservationRows())
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "flight" WHERE id = $1 LIMIT $2`)).
+		WithArgs(1, 1).
+		WillReturnRows(flightRows(1))
+
+	seatRows := sqlmock.NewRows([]string{"aircraft_type_id", "seat_number", "cabin_class"})
+	if cabin != "" {
+		seatRows.AddRow(1, "12A", cabin)
+	}
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "aircraft_seat" WHERE aircraft_type_id = $1 AND seat_number = $2 LIMIT $3`)).
+		WithArgs(1, "12A", 1).
+		WillReturnRows(seatRows)
+}
+
+func (s *SeatRepoTestSuite) TestAssignSeat(t provider.T) {
+	s.mock.ExpectBegin()
+	s.expectSeatLookup(models.CabinEconomy)
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "seat_reservation" SET "seat_number"=$1 WHERE ticket_uid = $2`)).
+		WithArgs("12A", "uid").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.AssignSeat("uid", "12A")
+	t.Assert().NoError(err)
+}
+
+func (s *SeatRepoTestSuite) TestAssignSeatTaken(t provider.T) {
+	s.mock.ExpectBegin()
+	s.expectSeatLookup(models.CabinEconomy)
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "seat_reservation" SET "seat_number"=$1 WHERE ticket_uid = $2`)).
+		WithArgs("12A", "uid").
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	s.mock.ExpectRollback()
+
+	err := s.repo.AssignSeat("uid", "12A")
+	t.Assert().ErrorIs(err, seatRep.ErrSeatTaken)
+}
+
+func (s *SeatRepoTestSuite) TestAssignUnknownSeat(t provider.T) {
+	s.mock.ExpectBegin()
+	s.expectSeatLookup("")
+	s.mock.ExpectRollback()
+
+	err := s.repo.AssignSeat("uid", "12A")
+	t.Assert().ErrorIs(err, seatRep.ErrSeatNotFound)
+}
+
+func (s *SeatRepoTestSuite) TestAssignSeatInAnotherCabin(t provider.T) {
+	s.mock.ExpectBegin()
+	s.expectSeatLookup(models.CabinBusiness)
+	s.mock.ExpectRollback()
+
+	err := s.repo.AssignSeat("uid", "12A")
+	t.Assert().ErrorIs(err, seatRep.ErrWrongCabin)
+}
+
+func (s *SeatRepoTestSuite) TestAssignSeatWithoutReservation(t provider.T) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "seat_reservation" WHERE ticket_uid = $1 LIMIT $2 FOR UPDATE`)).
+		WithArgs("uid", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"ticket_uid"}))
+	s.mock.ExpectRollback()
+
+	err := s.repo.AssignSeat("uid", "12A")
+	t.Assert().ErrorIs(err, seatRep.ErrReservationNotFound)
+}
+
+func (s *SeatRepoTestSuite) TestClearSeat(t provider.T) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "seat_reservation" WHERE ticket_uid = $1 LIMIT $2 FOR UPDATE`)).
+		WithArgs("uid", 1).
+		WillReturnRows(reservationRows())
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "seat_reservation" SET "seat_number"=$1 WHERE ticket_uid = $2`)).
+		WithArgs(nil, "uid").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectCommit()
+
+	err := s.repo.AssignSeat("uid", "")
+	t.Assert().NoError(err)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"flight_booking_system/flightService/models"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNoSeatMap — у рейса не задан тип самолёта.
+	ErrNoSeatMap = errors.New("flight has no seat map")
+	// ErrSeatNotFound — такого кресла нет в салоне самолёта рейса.
+	ErrSeatNotFound = errors.New("seat not found")
+	// ErrSeatTaken — кресло уже занято другим билетом.
+	ErrSeatTaken = errors.New("seat is already taken")
+	// ErrWrongCabin — кресло в салоне, не соответствующем классу билета.
+	ErrWrongCabin = errors.New("seat is in another cabin than the fare class")
+	// ErrReservationNotFound — у билета нет места на рейсе.
+	ErrReservationNotFound = errors.New("seat reservation not found")
+)
+
+type SeatRepositoryI interface {
+	GetAircraftType(id int) (*models.AircraftType, error)
+	GetSeatMap(flightID int) ([]*models.SeatDTO, error)
+	AssignSeat(ticketUID string, seatNumber string) error
+}
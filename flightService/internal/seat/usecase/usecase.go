@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	seatRep "flight_booking_system/flightService/internal/seat/repository"
+	"flight_booking_system/flightService/models"
+	"github.com/pkg/errors"
+)
+
+type SeatUseCaseI interface {
+	GetSeatMap(flightID int) ([]*models.SeatDTO, error)
+	AssignSeat(ticketUID string, seatNumber string) error
+}
+
+type seatUseCase struct {
+	seatRepository seatRep.SeatRepositoryI
+}
+
+func New(sRep seatRep.SeatRepositoryI) SeatUseCaseI {
+	return &seatUseCase{
+		seatRepository: sRep,
+	}
+}
+
+func (pUC *seatUseCase) GetSeatMap(flightID int) ([]*models.SeatDTO, error) {
+	seats, err := pUC.seatRepository.GetSeatMap(flightID)
+	if err != nil {
+		return nil, errors.Wrap(err, "seatUseCase.GetSeatMap error")
+	}
+
+	if seats == nil {
+		seats = []*models.SeatDTO{}
+	}
+
+	return seats, nil
+}
+
+// AssignSeat выбирает или меняет кресло билета; пустой seatNumber снимает выбор.
+func (pUC *seatUseCase) AssignSeat(ticketUID string, seatNumber string) error {
+	err := pUC.seatRepository.AssignSeat(ticketUID, seatNumber)
+	if err != nil {
+		return errors.Wrap(err, "seatUseCase.AssignSeat error")
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package testBuilders
 
 import (
+	"time"
+
 	"flight_booking_system/flightService/models"
 )
 
@@ -22,7 +24,7 @@ func (b *FlightBuilder) WithFlightNumber(flightNumber string) *FlightBuilder {
 	return b
 }
 
-func (b *FlightBuilder) WithDateTime(dateTime string) *FlightBuilder {
+func (b *FlightBuilder) WithDateTime(dateTime time.Time) *FlightBuilder {
 	b.flight.DateTime = dateTime
 	return b
 }
@@ -42,6 +44,21 @@ func (b *FlightBuilder) WithPrice(price int) *FlightBuilder {
 	return b
 }
 
+func (b *FlightBuilder) WithCapacity(capacity int) *FlightBuilder {
+	b.flight.Capacity = capacity
+	return b
+}
+
+func (b *FlightBuilder) WithDurationMinutes(durationMinutes int) *FlightBuilder {
+	b.flight.DurationMinutes = durationMinutes
+	return b
+}
+
+func (b *FlightBuilder) WithStatus(status string) *FlightBuilder {
+	b.flight.Status = status
+	return b
+}
+
 func (b *FlightBuilder) Build() models.Flight {
 	return b.flight
 }
@@ -2,6 +2,17 @@ package models
 
 import "time"
 
+const RoleAdmin = "ADMIN"
+
+// Оперативные статусы рейса. DEPARTED и CANCELLED — конечные.
+const (
+	FlightScheduled = "SCHEDULED"
+	FlightBoarding  = "BOARDING"
+	FlightDeparted  = "DEPARTED"
+	FlightDelayed   = "DELAYED"
+	FlightCancelled = "CANCELLED"
+)
+
 type Tabler interface {
 	TableName() string
 }
@@ -24,16 +35,144 @@ func (Flight) TableName() string {
 type Flight struct {
 	ID            int       `json:"id" db:"id"`
 	FlightNumber  string    `json:"flightNumber" db:"flight_number"`
-	DateTime      time.Time `json:"dateTime" db:"datetime"`
+	DateTime      time.Time `json:"dateTime" db:"datetime" gorm:"column:datetime"`
 	FromAirportID int       `json:"from_airport_id" db:"from_airport_id"`
 	ToAirportID   int       `json:"to_airport_id" db:"to_airport_id"`
 	Price         int       `json:"price" db:"price"`
+	Capacity      int       `json:"capacity" db:"capacity"`
+	SeatsReserved int       `json:"seatsReserved" db:"seats_reserved"`
+	// AircraftTypeID задаёт схему салона; без неё места не выбираются
+	AircraftTypeID *int `json:"aircraftTypeId,omitempty" db:"aircraft_type_id"`
+	// DurationMinutes — время в пути; по нему считаются прилёт и пересадки
+	DurationMinutes int `json:"durationMinutes" db:"duration_minutes"`
+	// Status, расчётное время и выход меняются только через UpdateStatus
+	Status             string     `json:"status" db:"status"`
+	EstimatedDeparture *time.Time `json:"estimatedDeparture,omitempty" db:"estimated_departure"`
+	EstimatedArrival   *time.Time `json:"estimatedArrival,omitempty" db:"estimated_arrival"`
+	Gate               string     `json:"gate,omitempty" db:"gate"`
+	// Fares создаются вместе с рейсом; дальше ими управляют отдельно
+	Fares []Fare `json:"fares,omitempty" gorm:"foreignKey:FlightID"`
+}
+
+// ArrivalTime — время прилёта по времени вылета и времени в пути.
+func (f *Flight) ArrivalTime() time.Time {
+	return f.DateTime.Add(time.Duration(f.DurationMinutes) * time.Minute)
+}
+
+// Closed — рейс отменён или уже вылетел, места на него не продаются.
+func (f *Flight) Closed() bool {
+	return f.Status == FlightCancelled || f.Status == FlightDeparted
+}
+
+// FlightStatusUpdate — новый оперативный статус рейса. Пустые расчётное время
+// и выход оставляют прежние значения.
+type FlightStatusUpdate struct {
+	Status             string     `json:"status"`
+	EstimatedDeparture *time.Time `json:"estimatedDeparture,omitempty"`
+	EstimatedArrival   *time.Time `json:"estimatedArrival,omitempty"`
+	Gate               string     `json:"gate,omitempty"`
+}
+
+func (SeatReservation) TableName() string {
+	return "seat_reservation"
+}
+
+type SeatReservation struct {
+	TicketUID string    `json:"ticketUid" db:"ticket_uid" gorm:"primaryKey"`
+	FlightID  int       `json:"flightId" db:"flight_id"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	// SeatNumber пуст, пока пассажир не выбрал кресло
+	SeatNumber *string `json:"seatNumber,omitempty" db:"seat_number"`
+	FareClass  string  `json:"fareClass" db:"fare_class"`
 }
 
 type FlightDTO struct {
-	FlightNumber string    `json:"flightNumber"`
-	Date         time.Time `json:"date"`
-	FromAirport  string    `json:"fromAirport"`
-	ToAirport    string    `json:"toAirport"`
-	Price        int       `json:"price"`
+	ID                 int        `json:"id"`
+	FlightNumber       string     `json:"flightNumber"`
+	Date               time.Time  `json:"date"`
+	FromAirport        string     `json:"fromAirport"`
+	ToAirport          string     `json:"toAirport"`
+	Price              int        `json:"price"`
+	AvailableSeats     int        `json:"availableSeats"`
+	Status             string     `json:"status"`
+	EstimatedDeparture *time.Time `json:"estimatedDeparture,omitempty"`
+	EstimatedArrival   *time.Time `json:"estimatedArrival,omitempty"`
+	Gate               string     `json:"gate,omitempty"`
+}
+
+const (
+	SortByDate  = "date"
+	SortByPrice = "price"
+)
+
+// FlightFilter — условия поиска рейсов. Пустые поля не ограничивают выборку.
+type FlightFilter struct {
+	FromAirportID int
+	ToAirportID   int
+	FromCity      string
+	ToCity        string
+	FromCountry   string
+	ToCountry     string
+	DateFrom      time.Time
+	DateTo        time.Time
+	MaxPrice      int
+	SortBy        string
+	SortDesc      bool
+	Offset        int
+	Limit         int
+}
+
+type FlightsPage struct {
+	Items []*FlightDTO `json:"items"`
+	Total int64        `json:"totalElements"`
+}
+
+const (
+	FareEconomy  = "ECONOMY"
+	FareComfort  = "COMFORT"
+	FareBusiness = "BUSINESS"
+)
+
+// FareCabin — в каком салоне можно выбрать кресло для класса обслуживания.
+var FareCabin = map[string]string{
+	FareEconomy:  CabinEconomy,
+	FareComfort:  CabinEconomy,
+	FareBusiness: CabinBusiness,
+}
+
+func (Fare) TableName() string {
+	return "flight_fare"
+}
+
+// Fare — класс обслуживания на рейсе с собственной ценой, квотой мест и
+// правилами возврата и обмена.
+type Fare struct {
+	FlightID      int    `json:"flightId" db:"flight_id" gorm:"primaryKey"`
+	FareClass     string `json:"fareClass" db:"fare_class" gorm:"primaryKey"`
+	Price         int    `json:"price" db:"price"`
+	Capacity      int    `json:"capacity" db:"capacity"`
+	SeatsReserved int    `json:"seatsReserved" db:"seats_reserved"`
+	Refundable    bool   `json:"refundable" db:"refundable"`
+	Exchangeable  bool   `json:"exchangeable" db:"exchangeable"`
+	ExchangeFee   int    `json:"exchangeFee" db:"exchange_fee"`
+}
+
+type FareDTO struct {
+	FareClass      string `json:"fareClass"`
+	Price          int    `json:"price"`
+	AvailableSeats int    `json:"availableSeats"`
+	Refundable     bool   `json:"refundable"`
+	Exchangeable   bool   `json:"exchangeable"`
+	ExchangeFee    int    `json:"exchangeFee"`
+}
+
+func FareToDTO(fare *Fare) *FareDTO {
+	return &FareDTO{
+		FareClass:      fare.FareClass,
+		Price:          fare.Price,
+		AvailableSeats: fare.Capacity - fare.SeatsReserved,
+		Refundable:     fare.Refundable,
+		Exchangeable:   fare.Exchangeable,
+		ExchangeFee:    fare.ExchangeFee,
+	}
 }
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+const (
+	RouteSortByPrice    = "price"
+	RouteSortByDuration = "duration"
+)
+
+// RouteQuery — поиск маршрутов с пересадками из аэропорта в аэропорт с
+// вылетом в сутки Date.
+type RouteQuery struct {
+	FromAirportID  int
+	ToAirportID    int
+	Date           time.Time
+	FareClass      string
+	MaxConnections int
+	// MinConnection и MaxConnection ограничивают время пересадки в аэропорту
+	MinConnection time.Duration
+	MaxConnection time.Duration
+	SortBy        string
+	Limit         int
+}
+
+type RouteLegDTO struct {
+	FlightID       int       `json:"flightId"`
+	FlightNumber   string    `json:"flightNumber"`
+	FromAirport    string    `json:"fromAirport"`
+	ToAirport      string    `json:"toAirport"`
+	DepartureDate  time.Time `json:"departureDate"`
+	ArrivalDate    time.Time `json:"arrivalDate"`
+	FareClass      string    `json:"fareClass"`
+	Price          int       `json:"price"`
+	AvailableSeats int       `json:"availableSeats"`
+	// ConnectionMinutes — пересадка перед этим перелётом, у первого перелёта 0
+	ConnectionMinutes int `json:"connectionMinutes"`
+}
+
+type RouteDTO struct {
+	Legs            []*RouteLegDTO `json:"legs"`
+	Connections     int            `json:"connections"`
+	TotalPrice      int            `json:"totalPrice"`
+	DurationMinutes int            `json:"durationMinutes"`
+	DepartureDate   time.Time      `json:"departureDate"`
+	ArrivalDate     time.Time      `json:"arrivalDate"`
+}
@@ -0,0 +1,41 @@
+package models
+
+const (
+	CabinEconomy  = "ECONOMY"
+	CabinBusiness = "BUSINESS"
+	CabinFirst    = "FIRST"
+)
+
+func (AircraftType) TableName() string {
+	return "aircraft_type"
+}
+
+type AircraftType struct {
+	ID   int    `json:"id" db:"id"`
+	Code string `json:"code" db:"code"`
+	Name string `json:"name" db:"name"`
+}
+
+func (Seat) TableName() string {
+	return "aircraft_seat"
+}
+
+// Seat — кресло в схеме салона типа самолёта.
+type Seat struct {
+	AircraftTypeID int    `json:"-" db:"aircraft_type_id" gorm:"primaryKey"`
+	SeatNumber     string `json:"seatNumber" db:"seat_number" gorm:"primaryKey"`
+	Row            int    `json:"row" db:"seat_row" gorm:"column:seat_row"`
+	Letter         string `json:"letter" db:"letter"`
+	CabinClass     string `json:"cabinClass" db:"cabin_class"`
+	ExitRow        bool   `json:"exitRow" db:"exit_row"`
+}
+
+// SeatDTO — кресло на конкретном рейсе.
+type SeatDTO struct {
+	SeatNumber string `json:"seatNumber"`
+	Row        int    `json:"row"`
+	Letter     string `json:"letter"`
+	CabinClass string `json:"cabinClass"`
+	ExitRow    bool   `json:"exitRow"`
+	Available  bool   `json:"available"`
+}
@@ -7,7 +7,8 @@ import (
 	"flight_booking_system/flightService/pkg/logger"
 )
 
-const sessionHeader = "Authorization"
+// sessionHeader — identity-токен, который выпускает gateway для пользователя.
+const sessionHeader = "X-User-Identity"
 
 type AuthSessionsManager interface {
 	GetUser(string) (int, string, error)
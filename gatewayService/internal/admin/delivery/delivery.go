@@ -0,0 +1,243 @@
+package delivery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"flight_booking_system/gatewayService/pkg/bonusclient"
+	"flight_booking_system/gatewayService/pkg/flightclient"
+	"flight_booking_system/gatewayService/pkg/logger"
+	"flight_booking_system/gatewayService/pkg/ticketclient"
+	"github.com/pkg/errors"
+)
+
+// AdminHandler — API администратора для аэропортов и рейсов. Проверки
+// данных выполняет Flight Service, gateway только передаёт запросы; при
+// отмене рейса gateway ещё и возвращает его билеты.
+type AdminHandler struct {
+	Logger       logger.Logger
+	FlightClient *flightclient.Client
+	TicketClient *ticketclient.Client
+	BonusClient  *bonusclient.Client
+	Identity     IdentityIssuer
+}
+
+func (ah *AdminHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	var apiErr *apiclient.Error
+	switch {
+	case errors.Is(err, apiclient.ErrBadRequest) && errors.As(err, &apiErr):
+		ah.Logger.Infow(msg, "err:", err.Error())
+		http.Error(w, apiErr.Message, http.StatusBadRequest)
+	case errors.Is(err, apiclient.ErrNotFound):
+		ah.Logger.Infow(msg, "err:", err.Error())
+		http.Error(w, msg+": not found", http.StatusNotFound)
+	case errors.Is(err, apiclient.ErrConflict) && errors.As(err, &apiErr):
+		ah.Logger.Infow(msg, "err:", err.Error())
+		http.Error(w, apiErr.Message, http.StatusConflict)
+	case errors.Is(err, apiclient.ErrUnavailable):
+		ah.Logger.Errorw(msg, "err:", err.Error())
+		http.Error(w, msg+": flight service unavailable", http.StatusServiceUnavailable)
+	default:
+		ah.Logger.Errorw(msg, "err:", err.Error())
+		http.Error(w, msg, http.StatusInternalServerError)
+	}
+}
+
+func (ah *AdminHandler) writeJSON(w http.ResponseWriter, statusCode int, res any) {
+	resp, err := json.Marshal(res)
+	if err != nil {
+		ah.Logger.Errorw("can`t marshal response", "err:", err.Error())
+		http.Error(w, "can`t marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ah.Logger.Errorw("can`t write response", "err:", err.Error())
+		return
+	}
+}
+
+func (ah *AdminHandler) readJSON(w http.ResponseWriter, r *http.Request, in any) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		ah.Logger.Errorw("can`t read body of request", "err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return false
+	}
+	_ = r.Body.Close()
+
+	err = json.Unmarshal(body, in)
+	if err != nil {
+		ah.Logger.Infow("can`t unmarshal form", "err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func (ah *AdminHandler) pathID(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		http.Error(w, "bad "+name, http.StatusBadRequest)
+		return 0, false
+	}
+
+	return id, true
+}
+
+func (ah *AdminHandler) GetAirports(w http.ResponseWriter, r *http.Request) {
+	airports, err := ah.FlightClient.ListAirports(r.Context())
+	if err != nil {
+		ah.writeError(w, err, "can`t get airports")
+		return
+	}
+
+	ah.writeJSON(w, http.StatusOK, airports)
+}
+
+func (ah *AdminHandler) CreateAirport(w http.ResponseWriter, r *http.Request) {
+	airportRequest := models.AirportRequest{}
+	if !ah.readJSON(w, r, &airportRequest) {
+		return
+	}
+
+	id, err := ah.FlightClient.CreateAirport(r.Context(), airportRequest)
+	if err != nil {
+		ah.writeError(w, err, "can`t create airport")
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/admin/airports/%d", id))
+	ah.writeJSON(w, http.StatusCreated, models.AirportResponse{
+		ID:      id,
+		Name:    airportRequest.Name,
+		City:    airportRequest.City,
+		Country: airportRequest.Country,
+	})
+}
+
+func (ah *AdminHandler) UpdateAirport(w http.ResponseWriter, r *http.Request) {
+	id, ok := ah.pathID(w, r, "airportId")
+	if !ok {
+		return
+	}
+
+	airportRequest := models.AirportRequest{}
+	if !ah.readJSON(w, r, &airportRequest) {
+		return
+	}
+
+	airport, err := ah.FlightClient.UpdateAirport(r.Context(), id, airportRequest)
+	if err != nil {
+		ah.writeError(w, err, "can`t update airport")
+		return
+	}
+
+	ah.writeJSON(w, http.StatusOK, airport)
+}
+
+func (ah *AdminHandler) DeleteAirport(w http.ResponseWriter, r *http.Request) {
+	id, ok := ah.pathID(w, r, "airportId")
+	if !ok {
+		return
+	}
+
+	err := ah.FlightClient.DeleteAirport(r.Context(), id)
+	if err != nil {
+		ah.writeError(w, err, "can`t delete airport")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (ah *AdminHandler) CreateFlight(w http.ResponseWriter, r *http.Request) {
+	flightRequest := models.AdminFlightRequest{}
+	if !ah.readJSON(w, r, &flightRequest) {
+		return
+	}
+
+	id, err := ah.FlightClient.CreateFlight(r.Context(), flightRequest)
+	if err != nil {
+		ah.writeError(w, err, "can`t create flight")
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/admin/flights/%d", id))
+	ah.writeJSON(w, http.StatusCreated, models.AdminFlightResponse{
+		ID:              id,
+		FlightNumber:    flightRequest.FlightNumber,
+		DateTime:        flightRequest.DateTime,
+		FromAirportID:   flightRequest.FromAirportID,
+		ToAirportID:     flightRequest.ToAirportID,
+		Price:           flightRequest.Price,
+		Capacity:        flightRequest.Capacity,
+		DurationMinutes: flightRequest.DurationMinutes,
+	})
+}
+
+func (ah *AdminHandler) UpdateFlight(w http.ResponseWriter, r *http.Request) {
+	id, ok := ah.pathID(w, r, "flightId")
+	if !ok {
+		return
+	}
+
+	flightRequest := models.AdminFlightRequest{}
+	if !ah.readJSON(w, r, &flightRequest) {
+		return
+	}
+
+	flight, err := ah.FlightClient.UpdateFlight(r.Context(), id, flightRequest)
+	if err != nil {
+		ah.writeError(w, err, "can`t update flight")
+		return
+	}
+
+	ah.writeJSON(w, http.StatusOK, flight)
+}
+
+// SaveFare создаёт или меняет класс обслуживания рейса.
+func (ah *AdminHandler) SaveFare(w http.ResponseWriter, r *http.Request) {
+	id, ok := ah.pathID(w, r, "flightId")
+	if !ok {
+		return
+	}
+
+	fareRequest := models.AdminFareRequest{}
+	if !ah.readJSON(w, r, &fareRequest) {
+		return
+	}
+
+	fare, err := ah.FlightClient.SaveFare(r.Context(), id, r.PathValue("fareClass"), fareRequest)
+	if err != nil {
+		ah.writeError(w, err, "can`t save fare")
+		return
+	}
+
+	ah.writeJSON(w, http.StatusOK, fare)
+}
+
+func (ah *AdminHandler) DeleteFlight(w http.ResponseWriter, r *http.Request) {
+	id, ok := ah.pathID(w, r, "flightId")
+	if !ok {
+		return
+	}
+
+	err := ah.FlightClient.DeleteFlight(r.Context(), id)
+	if err != nil {
+		ah.writeError(w, err, "can`t delete flight")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,168 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"github.com/pkg/errors"
+)
+
+// IdentityIssuer выпускает identity-токен, с которым gateway ходит в сервисы
+// от имени владельца билета.
+type IdentityIssuer interface {
+	CreateIdentity(user *models.AuthUser) (string, error)
+}
+
+// refundedOnCancel — билеты в этих статусах возвращаются при отмене рейса.
+// Уже отменённые не обрабатываются повторно: билет отменяется последним,
+// после освобождения места и отката бонусов.
+func refundedOnCancel(status string) bool {
+	switch status {
+	case models.TicketHeld, models.TicketPaid, models.TicketCheckedIn:
+		return true
+	}
+
+	return false
+}
+
+// UpdateFlightStatus меняет оперативный статус рейса: задержку, выход на
+// посадку, вылет или отмену. При отмене все билеты рейса отменяются с полным
+// возвратом независимо от правил тарифа.
+func (ah *AdminHandler) UpdateFlightStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := ah.pathID(w, r, "flightId")
+	if !ok {
+		return
+	}
+
+	statusRequest := models.AdminFlightStatusRequest{}
+	if !ah.readJSON(w, r, &statusRequest) {
+		return
+	}
+
+	flight, err := ah.FlightClient.UpdateFlightStatus(r.Context(), id, statusRequest)
+	if err != nil {
+		ah.writeError(w, err, "can`t update flight status")
+		return
+	}
+
+	res := models.AdminFlightStatusResponse{AdminFlightResponse: *flight}
+	if flight.Status == models.FlightCancelled {
+		res.CancelledTickets, res.FailedTickets, err = ah.cancelFlightTickets(r.Context(), flight.FlightNumber)
+		if err != nil {
+			ah.Logger.Errorw("can`t get tickets of cancelled flight", "flightNumber", flight.FlightNumber, "err:", err.Error())
+			http.Error(w, "flight is cancelled, but its tickets are not: repeat the request", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	ah.writeJSON(w, http.StatusOK, res)
+}
+
+// cancelFlightTickets возвращает билеты отменённого рейса. Маршрут, один из
+// перелётов которого на этом рейсе, отменяется целиком. Ошибка по одному
+// билету не останавливает остальные — такие билеты попадают в failed, а
+// повтор запроса доводит их возврат до конца.
+func (ah *AdminHandler) cancelFlightTickets(ctx context.Context, flightNumber string) (cancelled []string, failed []string, err error) {
+	tickets, err := ah.TicketClient.ListFlightTickets(ctx, flightNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	itineraries := make(map[string]bool)
+	for _, ticket := range tickets {
+		if !refundedOnCancel(ticket.Status) {
+			continue
+		}
+		if ticket.ItineraryUID != "" {
+			if itineraries[ticket.ItineraryUID] {
+				continue
+			}
+			itineraries[ticket.ItineraryUID] = true
+		}
+
+		err = ah.refundTicket(ctx, ticket)
+		if err != nil {
+			ah.Logger.Errorw("can`t refund ticket of cancelled flight", "flightNumber", flightNumber,
+				"ticketUid", ticket.TicketUID, "err:", err.Error())
+			failed = append(failed, ticket.TicketUID)
+			continue
+		}
+		cancelled = append(cancelled, ticket.TicketUID)
+	}
+
+	return cancelled, failed, nil
+}
+
+// refundTicket освобождает места билета (или всех перелётов его маршрута),
+// откатывает бонусы и только затем отменяет билеты: освобождение места и откат
+// бонусов идемпотентны, поэтому после сбоя повтор продолжает возврат. Билеты
+// отменяются от имени администратора, а маршрут, билеты и бонусы
+// запрашиваются от имени владельца.
+func (ah *AdminHandler) refundTicket(ctx context.Context, ticket *models.FlightTicketResponse) error {
+	identity, err := ah.Identity.CreateIdentity(&models.AuthUser{Username: ticket.Username, Role: models.RoleUser})
+	if err != nil {
+		return errors.Wrap(err, "can`t create owner identity")
+	}
+	ownerCtx := apiclient.WithIdentity(ctx, identity)
+
+	legs := []*models.TicketResponse{&ticket.TicketResponse}
+	bonusKeys := []string{ticket.TicketUID}
+	if ticket.ItineraryUID != "" {
+		itinerary, err := ah.TicketClient.GetItinerary(ownerCtx, ticket.Username, ticket.ItineraryUID)
+		if err != nil {
+			return err
+		}
+		legs = itinerary.Tickets
+		bonusKeys = []string{ticket.ItineraryUID}
+	}
+
+	// На билет, полученный обменом, записана только разница тарифов, поэтому
+	// полный возврат откатывает движения бонусов по всей цепочке обменов.
+	// Места билетов цепочки освобождены ещё при обмене. Цепочка ищется до
+	// отмены: отменённый билет повторно не обрабатывается
+	if ticket.ItineraryUID == "" && ticket.Status != models.TicketHeld {
+		previous, err := ah.TicketClient.ExchangedChain(ownerCtx, ticket.Username, ticket.TicketUID)
+		if err != nil {
+			return err
+		}
+		bonusKeys = append(bonusKeys, previous...)
+	}
+
+	// Неоплаченная бронь бонусов не начисляла, откатывать по ней нечего
+	held := true
+	refunded := make([]*models.TicketResponse, 0, len(legs))
+	for _, leg := range legs {
+		if !refundedOnCancel(leg.Status) {
+			continue
+		}
+		if leg.Status != models.TicketHeld {
+			held = false
+		}
+
+		err = ah.FlightClient.ReleaseSeat(ctx, leg.TicketUID)
+		if err != nil {
+			return err
+		}
+		refunded = append(refunded, leg)
+	}
+
+	if !held {
+		for _, bonusKey := range bonusKeys {
+			_, err = ah.BonusClient.RevertHistory(ownerCtx, ticket.Username, bonusKey)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, leg := range refunded {
+		err = ah.TicketClient.CancelTicketForFlight(ctx, ticket.Username, leg.TicketUID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package delivery
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	authUseCase "flight_booking_system/gatewayService/internal/auth/usecase"
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+type AuthHandler struct {
+	AuthUseCase authUseCase.AuthUseCaseI
+	Logger      logger.Logger
+}
+
+func (ah *AuthHandler) readJSON(w http.ResponseWriter, r *http.Request, in any) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		ah.Logger.Errorw("can`t read body of request", "err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return false
+	}
+	_ = r.Body.Close()
+
+	err = json.Unmarshal(body, in)
+	if err != nil {
+		ah.Logger.Infow("can`t unmarshal form", "err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func (ah *AuthHandler) writeTokens(w http.ResponseWriter, tokens *models.TokenResponse, err error) {
+	if errors.Is(err, authUseCase.ErrInvalidCredentials) {
+		ah.Logger.Infow("authentication failed", "err:", err.Error())
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		ah.Logger.Errorw("can`t issue tokens", "err:", err.Error())
+		http.Error(w, "can`t issue tokens", http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(tokens)
+	if err != nil {
+		ah.Logger.Errorw("can`t marshal tokens", "err:", err.Error())
+		http.Error(w, "can`t marshal tokens", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ah.Logger.Errorw("can`t write response", "err:", err.Error())
+		return
+	}
+}
+
+func (ah *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	loginRequest := models.LoginRequest{}
+	if !ah.readJSON(w, r, &loginRequest) {
+		return
+	}
+
+	if loginRequest.Username == "" || loginRequest.Password == "" {
+		http.Error(w, "username and password are required", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := ah.AuthUseCase.Login(loginRequest.Username, loginRequest.Password)
+	ah.writeTokens(w, tokens, err)
+}
+
+func (ah *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	refreshRequest := models.RefreshRequest{}
+	if !ah.readJSON(w, r, &refreshRequest) {
+		return
+	}
+
+	if refreshRequest.RefreshToken == "" {
+		http.Error(w, "refreshToken is required", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := ah.AuthUseCase.Refresh(refreshRequest.RefreshToken)
+	ah.writeTokens(w, tokens, err)
+}
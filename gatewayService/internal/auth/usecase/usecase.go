@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	userRep "flight_booking_system/gatewayService/internal/user/repository"
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/session"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+type SessionsManager interface {
+	CreateSession(user *models.AuthUser, tokenType string) (string, error)
+	GetRefreshUser(token string) (*models.AuthUser, error)
+}
+
+type AuthUseCaseI interface {
+	Login(username string, password string) (*models.TokenResponse, error)
+	Refresh(refreshToken string) (*models.TokenResponse, error)
+	CreateAdmin(username string, password string) error
+}
+
+type authUseCase struct {
+	userRepository userRep.UserRepositoryI
+	sessions       SessionsManager
+	accessTTL      int
+}
+
+// New принимает время жизни access-токена в секундах, оно отдаётся клиенту в expiresIn.
+func New(uRep userRep.UserRepositoryI, sessions SessionsManager, accessTTL int) AuthUseCaseI {
+	return &authUseCase{
+		userRepository: uRep,
+		sessions:       sessions,
+		accessTTL:      accessTTL,
+	}
+}
+
+func (aUC *authUseCase) issueTokens(user *models.User) (*models.TokenResponse, error) {
+	authUser := &models.AuthUser{
+		ID:       user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+	}
+
+	accessToken, err := aUC.sessions.CreateSession(authUser, session.TokenTypeAccess)
+	if err != nil {
+		return nil, errors.Wrap(err, "can`t create access token")
+	}
+
+	refreshToken, err := aUC.sessions.CreateSession(authUser, session.TokenTypeRefresh)
+	if err != nil {
+		return nil, errors.Wrap(err, "can`t create refresh token")
+	}
+
+	return &models.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    aUC.accessTTL,
+	}, nil
+}
+
+func (aUC *authUseCase) Login(username string, password string) (*models.TokenResponse, error) {
+	user, err := aUC.userRepository.GetByUsername(username)
+	if errors.Is(err, userRep.ErrUserNotFound) {
+		return nil, errors.Wrap(ErrInvalidCredentials, "authUseCase.Login error")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "authUseCase.Login error")
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidCredentials, "authUseCase.Login error")
+	}
+
+	tokens, err := aUC.issueTokens(user)
+	if err != nil {
+		return nil, errors.Wrap(err, "authUseCase.Login error")
+	}
+
+	return tokens, nil
+}
+
+// Refresh перечитывает пользователя, чтобы удалённый пользователь или
+// сменившаяся роль не продлевались старым refresh-токеном.
+func (aUC *authUseCase) Refresh(refreshToken string) (*models.TokenResponse, error) {
+	authUser, err := aUC.sessions.GetRefreshUser(refreshToken)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidCredentials, "authUseCase.Refresh error: "+err.Error())
+	}
+
+	user, err := aUC.userRepository.GetByID(authUser.ID)
+	if errors.Is(err, userRep.ErrUserNotFound) {
+		return nil, errors.Wrap(ErrInvalidCredentials, "authUseCase.Refresh error")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "authUseCase.Refresh error")
+	}
+
+	tokens, err := aUC.issueTokens(user)
+	if err != nil {
+		return nil, errors.Wrap(err, "authUseCase.Refresh error")
+	}
+
+	return tokens, nil
+}
+
+// CreateAdmin создаёт администратора с указанным паролем. Если имя уже занято,
+// возвращается ErrUserExists: существующий пользователь не меняется.
+func (aUC *authUseCase) CreateAdmin(username string, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.Wrap(err, "authUseCase.CreateAdmin error")
+	}
+
+	err = aUC.userRepository.Create(&models.User{
+		Username:     username,
+		PasswordHash: string(hash),
+		Role:         models.RoleAdmin,
+	})
+	if err != nil {
+		return errors.Wrap(err, "authUseCase.CreateAdmin error")
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"flight_booking_system/gatewayService/internal/saga"
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"github.com/pkg/errors"
+)
+
+// readBody читает JSON тела запроса в v; при ошибке ответ клиенту уже отправлен.
+func (gh *GatewayHandler) readBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		gh.Logger.Errorw("can`t read body of request",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return false
+	}
+
+	err = r.Body.Close()
+	if err != nil {
+		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
+		http.Error(w, "close error", http.StatusInternalServerError)
+		return false
+	}
+
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		gh.Logger.Infow("can`t unmarshal form",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+// CreateBooking бронирует место без оплаты: билет создаётся в статусе HELD и
+// ждёт оплаты до holdExpiresAt, после чего Ticket Service снимает бронь.
+func (gh *GatewayHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
+		return
+	}
+
+	bookingRequest := models.BookingRequest{}
+	if !gh.readBody(w, r, &bookingRequest) {
+		return
+	}
+
+	ctx := r.Context()
+	compensateCtx := context.WithoutCancel(ctx)
+
+	p := newPurchase(userName, bookingRequest.FlightNumber, bookingRequest.FareClass, bookingRequest.Price, models.TicketHeld)
+
+	err := gh.addReservationSteps(saga.New("CreateBooking", gh.Logger), ctx, compensateCtx, p).Run()
+	if err != nil {
+		gh.Logger.Errorw("can`t create booking", "err:", err.Error())
+		gh.writeReservationError(w, err, p, "can`t create booking")
+		return
+	}
+
+	bookingResponse := models.BookingResponse{
+		UID:          p.ticketUID,
+		FlightNumber: p.flightNumber,
+		FromAirport:  p.flight.FromAirport,
+		ToAirport:    p.flight.ToAirport,
+		Date:         p.flight.Date,
+		Price:        p.fare.Price,
+		FareClass:    p.fare.FareClass,
+		Status:       models.TicketHeld,
+	}
+	if p.ticket.HoldExpiresAt != nil {
+		bookingResponse.HoldExpiresAt = *p.ticket.HoldExpiresAt
+	}
+
+	w.Header().Set("Location", "/api/v1/tickets/"+p.ticketUID)
+	gh.writeJSON(w, http.StatusCreated, bookingResponse)
+}
+
+// ConfirmBooking оплачивает бронь деньгами и/или бонусами. Бонусы
+// списываются до подтверждения билета: если бронь успела истечь, операция
+// с бонусами откатывается.
+func (gh *GatewayHandler) ConfirmBooking(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
+		return
+	}
+
+	confirmRequest := models.ConfirmBookingRequest{}
+	if !gh.readBody(w, r, &confirmRequest) {
+		return
+	}
+
+	ticketUid := r.PathValue("ticketUid")
+
+	ticketResponse, err := gh.TicketClient.GetTicket(r.Context(), userName, ticketUid)
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get booking")
+		return
+	}
+
+	if ticketResponse.Status != models.TicketHeld {
+		gh.Logger.Infow("ticket is not held", "ticketUid", ticketUid, "status", ticketResponse.Status)
+		http.Error(w, "can`t confirm booking: ticket is "+ticketResponse.Status, http.StatusConflict)
+		return
+	}
+
+	flightResponse, err := gh.FlightClient.GetFlightByNumber(r.Context(), ticketResponse.FlightNumber)
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get flight")
+		return
+	}
+
+	ctx := r.Context()
+	compensateCtx := context.WithoutCancel(ctx)
+
+	var operationResponse *models.PrivilegeOperationResponse
+	var confirmed *models.TicketResponse
+
+	confirmSaga := saga.New("ConfirmBooking", gh.Logger).
+		AddStep(gh.privilegeStep(ctx, compensateCtx, userName, ticketUid, confirmRequest.PaidFromBalance,
+			func() int { return ticketResponse.Price }, &operationResponse)).
+		AddStep(saga.Step{
+			Name: "confirm ticket",
+			Action: func() (err error) {
+				confirmed, err = gh.TicketClient.ConfirmTicket(ctx, userName, ticketUid)
+				return err
+			},
+		})
+
+	err = confirmSaga.Run()
+	if err != nil {
+		gh.Logger.Errorw("can`t confirm booking", "err:", err.Error())
+
+		var stepErr *saga.StepError
+		if errors.As(err, &stepErr) && stepErr.Step == "confirm ticket" && errors.Is(err, apiclient.ErrConflict) {
+			http.Error(w, "can`t confirm booking: hold has expired", http.StatusConflict)
+			return
+		}
+
+		writeUnavailable(w, err, "can`t confirm booking: payment was rolled back")
+		return
+	}
+
+	gh.writeJSON(w, http.StatusOK, makeBuyTicketResponse(confirmed, flightResponse, operationResponse))
+}
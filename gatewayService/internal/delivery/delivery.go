@@ -1,46 +1,97 @@
 package delivery
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"math"
 	"net/http"
+	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
+	"flight_booking_system/gatewayService/internal/saga"
 	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"flight_booking_system/gatewayService/pkg/bonusclient"
+	"flight_booking_system/gatewayService/pkg/breaker"
+	appContext "flight_booking_system/gatewayService/pkg/context"
+	"flight_booking_system/gatewayService/pkg/flightclient"
 	"flight_booking_system/gatewayService/pkg/logger"
+	"flight_booking_system/gatewayService/pkg/ticketclient"
 	//"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
 )
 
 const (
-	bonusHost  = "http://bonus_msv:8050"
-	flightHost = "http://flight_msv:8060"
-	ticketHost = "http://ticket_msv:8070"
+	defaultRetryAfter = 5 * time.Second
+	// enrichmentTimeout — общий дедлайн на сбор билетов, рейсов и привилегии
+	enrichmentTimeout = 5 * time.Second
+	// flightBatchSize совпадает с ограничением /api/v1/flightsBatch во Flight Service
+	flightBatchSize = 100
+	// maxParallelRequests ограничивает число одновременных запросов к Flight Service
+	maxParallelRequests = 4
 )
 
+// IdentityIssuer выпускает identity-токен, с которым gateway ходит в сервисы.
+type IdentityIssuer interface {
+	CreateIdentity(user *models.AuthUser) (string, error)
+}
+
 type GatewayHandler struct {
-	Logger logger.Logger
-	Client http.Client
+	Logger       logger.Logger
+	FlightClient *flightclient.Client
+	TicketClient *ticketclient.Client
+	BonusClient  *bonusclient.Client
+	Identity     IdentityIssuer
+}
+
+// serviceContext — контекст с identity-токеном роли RoleService для операций,
+// которые сервисы принимают только от самого gateway.
+func (gh *GatewayHandler) serviceContext(ctx context.Context, userName string) (context.Context, error) {
+	identity, err := gh.Identity.CreateIdentity(&models.AuthUser{Username: userName, Role: models.RoleService})
+	if err != nil {
+		return nil, errors.Wrap(err, "can`t create service identity")
+	}
+
+	return apiclient.WithIdentity(ctx, identity), nil
+}
+
+// flightFilterParams — параметры поиска, которые gateway передаёт во Flight Service
+var flightFilterParams = []string{
+	"fromAirportId", "toAirportId",
+	"fromCity", "toCity",
+	"fromCountry", "toCountry",
+	"dateFrom", "dateTo",
+	"maxPrice",
+	"sort", "order",
+}
+
+// routeQueryParams — параметры поиска маршрутов с пересадками
+var routeQueryParams = []string{
+	"fromAirportId", "toAirportId", "date",
+	"maxConnections", "minConnectionMinutes", "maxConnectionMinutes",
+	"fareClass", "sort", "limit",
 }
 
-func makeFlightsInfoResponse(flightResponses []*models.FlightResponse, page, size int) models.FlightsInfo {
-	flightsInfo := make([]models.FlightInfo, len(flightResponses))
-	for i, response := range flightResponses {
+func makeFlightsInfoResponse(flightsPage *models.FlightsPage, page, size int) models.FlightsInfo {
+	flightsInfo := make([]models.FlightInfo, len(flightsPage.Items))
+	for i, response := range flightsPage.Items {
 		flightsInfo[i] = models.FlightInfo{
-			FlightNumber: response.FlightNumber,
-			FromAirport:  response.FromAirport,
-			ToAirport:    response.ToAirport,
-			Date:         response.Date,
-			Price:        response.Price,
+			FlightNumber:   response.FlightNumber,
+			FromAirport:    response.FromAirport,
+			ToAirport:      response.ToAirport,
+			Date:           response.Date,
+			Price:          response.Price,
+			AvailableSeats: response.AvailableSeats,
 		}
 	}
 
 	return models.FlightsInfo{
 		Page:    page,
 		Size:    size,
-		Total:   len(flightsInfo),
+		Total:   flightsPage.Total,
 		Flights: flightsInfo,
 	}
 }
@@ -49,45 +100,43 @@ func makeTicketInfoResponse(ticketResponses []*models.TicketResponse, flightResp
 	res := make([]models.TicketInfo, len(ticketResponses))
 	for i, ticketResponse := range ticketResponses {
 		res[i] = models.TicketInfo{
-			UID:          ticketResponse.TicketUID,
-			FlightNumber: ticketResponse.FlightNumber,
-			FromAirport:  flightResponses[i].FromAirport,
-			ToAirport:    flightResponses[i].ToAirport,
-			Date:         flightResponses[i].Date,
-			Price:        ticketResponse.Price,
-			Status:       ticketResponse.Status,
+			UID:           ticketResponse.TicketUID,
+			FlightNumber:  ticketResponse.FlightNumber,
+			FromAirport:   flightResponses[i].FromAirport,
+			ToAirport:     flightResponses[i].ToAirport,
+			Date:          flightResponses[i].Date,
+			Price:         ticketResponse.Price,
+			Status:        ticketResponse.Status,
+			Seat:          ticketResponse.Seat,
+			FareClass:     ticketResponse.FareClass,
+			HoldExpiresAt: ticketResponse.HoldExpiresAt,
+			ExchangedFor:  ticketResponse.ExchangedFor,
+			ItineraryUID:  ticketResponse.ItineraryUID,
+
+			FlightStatus:       flightResponses[i].Status,
+			EstimatedDeparture: flightResponses[i].EstimatedDeparture,
+			EstimatedArrival:   flightResponses[i].EstimatedArrival,
+			Gate:               flightResponses[i].Gate,
+		}
+
+		if ticketResponse.Passenger != nil {
+			res[i].BookingReference = ticketResponse.BookingReference
+			res[i].Passenger = makePassengerInfo(ticketResponse.Passenger)
 		}
 	}
 
 	return res
 }
 
-func makeUserInfoResponse(ticketResponses []models.TicketResponse, flightResponses []*models.FlightResponse, privilegeResponse *models.PrivilegeResponse) models.UserInfoResponse {
-	res := models.UserInfoResponse{}
-
-	ticketInfos := make([]models.TicketInfo, len(ticketResponses))
-	for i, ticketResponse := range ticketResponses {
-		ticketInfos[i] = models.TicketInfo{
-			UID:          ticketResponse.TicketUID,
-			FlightNumber: ticketResponse.FlightNumber,
-			FromAirport:  flightResponses[i].FromAirport,
-			ToAirport:    flightResponses[i].ToAirport,
-			Date:         flightResponses[i].Date,
-			Price:        ticketResponse.Price,
-			Status:       ticketResponse.Status,
-		}
+func makeUserInfoResponse(ticketResponses []*models.TicketResponse, flightResponses []*models.FlightResponse, privilegeResponse *models.PrivilegeResponse) models.UserInfoResponse {
+	return models.UserInfoResponse{
+		Tickets: makeTicketInfoResponse(ticketResponses, flightResponses),
+		Privilege: models.PrivilegeInfo{
+			ID:      privilegeResponse.ID,
+			Status:  privilegeResponse.Status,
+			Balance: privilegeResponse.Balance,
+		},
 	}
-
-	privilegeInfo := models.PrivilegeInfo{
-		ID:      privilegeResponse.ID,
-		Status:  privilegeResponse.Status,
-		Balance: privilegeResponse.Balance,
-	}
-
-	res.Tickets = ticketInfos
-	res.Privilege = privilegeInfo
-
-	return res
 }
 
 func makePrivilegeFullResponse(privilegeResponse models.PrivilegeResponse, privilegeHistory []models.PrivilegeHistoryResponse) models.PrivilegeFullResponse {
@@ -98,127 +147,165 @@ func makePrivilegeFullResponse(privilegeResponse models.PrivilegeResponse, privi
 	}
 }
 
-func (gh *GatewayHandler) makeUpdatePrivilegeRequest(privilegeInfo *models.PrivilegeResponse) error {
-	jsonPrivilegeInfo, err := json.Marshal(privilegeInfo)
-	if err != nil {
-		return err
-	}
+// writeUnavailable отвечает 503 с заголовком Retry-After. Если breaker
+// разомкнут, время берётся из него.
+func writeUnavailable(w http.ResponseWriter, err error, msg string) {
+	retryAfter := defaultRetryAfter
 
-	privReq, err := http.NewRequest("PATCH", bonusHost+"/api/v1/privileges", bytes.NewBuffer(jsonPrivilegeInfo))
-	if err != nil {
-		return err
+	var openErr *breaker.OpenError
+	if errors.As(err, &openErr) {
+		retryAfter = openErr.RetryAfter
 	}
 
-	_, err = gh.Client.Do(privReq)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	http.Error(w, msg, http.StatusServiceUnavailable)
 }
 
-func (gh *GatewayHandler) makeCreatePrivilegeHistoryRequest(privilege *models.PrivilegeResponse, operationType string, ticketUID string, balanceDiff int) error {
-	privHistInfo := models.PrivilegeHistoryInfo{
-		TicketUID:     ticketUID,
-		OperationType: operationType,
-		PrivilegeID:   privilege.ID,
-		BalanceDiff:   balanceDiff,
-		Date:          time.Now(),
+// writeClientError переводит ошибку клиента сервиса в ответ gateway.
+func (gh *GatewayHandler) writeClientError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, apiclient.ErrNotFound):
+		gh.Logger.Infow(msg, "err:", err.Error())
+		http.Error(w, msg+": not found", http.StatusNotFound)
+	case errors.Is(err, apiclient.ErrConflict):
+		gh.Logger.Infow(msg, "err:", err.Error())
+		http.Error(w, msg+": conflict", http.StatusConflict)
+	case errors.Is(err, apiclient.ErrBadRequest):
+		gh.Logger.Infow(msg, "err:", err.Error())
+		http.Error(w, msg+": bad request", http.StatusBadRequest)
+	case errors.Is(err, apiclient.ErrUnavailable), errors.Is(err, context.DeadlineExceeded):
+		gh.Logger.Errorw(msg, "err:", err.Error())
+		writeUnavailable(w, err, msg+": service unavailable")
+	default:
+		gh.Logger.Errorw(msg, "err:", err.Error())
+		http.Error(w, msg, http.StatusInternalServerError)
 	}
+}
 
-	jsonHistInfo, err := json.Marshal(privHistInfo)
+func (gh *GatewayHandler) writeJSON(w http.ResponseWriter, statusCode int, res any) {
+	resp, err := json.Marshal(res)
 	if err != nil {
-		return err
+		gh.Logger.Errorw("can`t marshal response", "err:", err.Error())
+		http.Error(w, "can`t marshal response", http.StatusInternalServerError)
+		return
 	}
 
-	privHistReq, err := http.NewRequest("POST", bonusHost+"/api/v1/privileges/history", bytes.NewBuffer(jsonHistInfo))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_, err = w.Write(resp)
 	if err != nil {
-		return err
+		gh.Logger.Errorw("can`t write response", "err:", err.Error())
+		return
 	}
+}
 
-	privHistReq.Header.Set("Content-Type", "application/json")
-
-	_, err = gh.Client.Do(privHistReq)
+// userNameFromRequest берёт имя пользователя из проверенного Auth токена.
+func userNameFromRequest(r *http.Request) string {
+	user, err := appContext.Manager{}.UserFromContext(r.Context())
 	if err != nil {
-		return err
+		return ""
 	}
 
-	return nil
+	return user.Username
 }
 
-func (gh *GatewayHandler) makeCreateTicketRequest(userName string, flightNumber string, price int) (string, error) {
-	ticketInfo := models.TicketInfoRequest{
-		UID:          "",
-		FlightNumber: flightNumber,
-		Username:     userName,
-		Price:        price,
-		Status:       "PAID",
+// getFlightsForTickets получает рейсы для билетов пачками по flightBatchSize,
+// повторяющиеся номера рейсов запрашиваются один раз. Одновременно
+// выполняется не больше maxParallelRequests запросов.
+func (gh *GatewayHandler) getFlightsForTickets(ctx context.Context, ticketResponses []*models.TicketResponse) ([]*models.FlightResponse, error) {
+	seen := make(map[string]struct{}, len(ticketResponses))
+	flightNumbers := make([]string, 0, len(ticketResponses))
+	for _, ticketResponse := range ticketResponses {
+		if _, ok := seen[ticketResponse.FlightNumber]; ok {
+			continue
+		}
+		seen[ticketResponse.FlightNumber] = struct{}{}
+		flightNumbers = append(flightNumbers, ticketResponse.FlightNumber)
 	}
 
-	jsonTicketInfo, err := json.Marshal(ticketInfo)
-	if err != nil {
-		return "", err
-	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	ticketReq, err := http.NewRequest("POST", ticketHost+"/api/v1/tickets", bytes.NewBuffer(jsonTicketInfo))
-	if err != nil {
-		return "", err
-	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	flightsByNumber := make(map[string]*models.FlightResponse, len(flightNumbers))
+	sem := make(chan struct{}, maxParallelRequests)
 
-	ticketReq.Header.Set("Content-Type", "application/json")
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
 
-	ticketResp, err := gh.Client.Do(ticketReq)
-	if err != nil {
-		return "", err
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
 	}
 
-	ticketUID := ticketResp.Header.Get("X-Ticket-UID")
+	for start := 0; start < len(flightNumbers); start += flightBatchSize {
+		batch := flightNumbers[start:min(start+flightBatchSize, len(flightNumbers))]
 
-	return ticketUID, nil
-}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-func (gh *GatewayHandler) makeGetFlightRequest(flightNumber string) (*models.FlightResponse, error) {
-	flightReq, err := http.NewRequest("GET", flightHost+"/api/v1/flights", nil)
-	if err != nil {
-		return nil, err
-	}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				setErr(ctx.Err())
+				return
+			}
+			defer func() { <-sem }()
 
-	flightReq.Header.Set("flightNumber", flightNumber)
+			flightResponses, err := gh.FlightClient.GetFlightsByNumbers(ctx, batch)
+			if err != nil {
+				setErr(err)
+				return
+			}
 
-	flightResp, err := gh.Client.Do(flightReq)
-	if err != nil {
-		return nil, err
+			mu.Lock()
+			defer mu.Unlock()
+			for _, flightResponse := range flightResponses {
+				flightsByNumber[flightResponse.FlightNumber] = flightResponse
+			}
+		}()
 	}
 
-	flightResponse := make([]*models.FlightResponse, 0)
-	body, err := io.ReadAll(flightResp.Body)
-	if err != nil {
-		return nil, err
-	}
+	wg.Wait()
 
-	err = flightResp.Body.Close()
-	if err != nil {
-		return nil, err
+	if firstErr != nil {
+		return nil, firstErr
 	}
 
-	err = json.Unmarshal(body, &flightResponse)
-	if err != nil {
-		return nil, err
+	flightResponses := make([]*models.FlightResponse, len(ticketResponses))
+	for i, ticketResponse := range ticketResponses {
+		flightResponse, ok := flightsByNumber[ticketResponse.FlightNumber]
+		if !ok {
+			return nil, &apiclient.Error{
+				Method:  http.MethodGet,
+				URL:     "/api/v1/flightsBatch",
+				Message: "flight " + ticketResponse.FlightNumber + " not found",
+				Kind:    apiclient.ErrNotFound,
+			}
+		}
+
+		flightResponses[i] = flightResponse
 	}
 
-	return flightResponse[0], nil
+	return flightResponses, nil
 }
 
 func (gh *GatewayHandler) GetFlights(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
-	strPage := q.Get("page")
-	strSize := q.Get("size")
-	page, _ := strconv.Atoi(strPage)
+	page, _ := strconv.Atoi(q.Get("page"))
 	if page <= 0 {
 		page = 1
 	}
 
-	size, _ := strconv.Atoi(strSize)
+	size, _ := strconv.Atoi(q.Get("size"))
 	switch {
 	case size <= 0:
 		size = 1
@@ -226,277 +313,105 @@ func (gh *GatewayHandler) GetFlights(w http.ResponseWriter, r *http.Request) {
 		size = 100
 	}
 
-	flightReq, err := http.NewRequest("GET", flightHost+"/api/v1/flightsPaginate", nil)
-	if err != nil {
-		gh.Logger.Errorw("can`t create flight request")
-		http.Error(w, "can`t create flight request", http.StatusInternalServerError)
-		return
+	filter := url.Values{}
+	for _, name := range flightFilterParams {
+		if value := q.Get(name); value != "" {
+			filter.Set(name, value)
+		}
 	}
-	newQ := flightReq.URL.Query()
-	newQ.Add("page", strPage)
-	newQ.Add("size", strSize)
-	flightReq.URL.RawQuery = newQ.Encode()
 
-	flightResp, err := gh.Client.Do(flightReq)
+	flightsPage, err := gh.FlightClient.SearchFlights(r.Context(), filter, page, size)
 	if err != nil {
+		var apiErr *apiclient.Error
+		if errors.Is(err, apiclient.ErrBadRequest) && errors.As(err, &apiErr) {
+			gh.Logger.Infow("can`t get flights", "err:", err.Error())
+			http.Error(w, apiErr.Message, http.StatusBadRequest)
+			return
+		}
 		gh.Logger.Errorw("can`t get flights", "err:", err.Error())
-		http.Error(w, "can`t get flights", http.StatusBadRequest)
-		return
-	}
-
-	flightResponses := make([]*models.FlightResponse, 0)
-	body, err := io.ReadAll(flightResp.Body)
-	if err != nil {
-		gh.Logger.Errorw("can`t read body of request",
-			"err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
-		return
-	}
-
-	err = flightResp.Body.Close()
-	if err != nil {
-		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
-		http.Error(w, "close error", http.StatusInternalServerError)
-		return
-	}
-
-	err = json.Unmarshal(body, &flightResponses)
-	if err != nil {
-		gh.Logger.Infow("can`t unmarshal form",
-			"err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+		writeUnavailable(w, err, "flight service is unavailable")
 		return
 	}
 
-	res := makeFlightsInfoResponse(flightResponses, page, size)
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	resp, err := json.Marshal(res)
-	if err != nil {
-		gh.Logger.Errorw("can`t marshal response", "err:", err.Error())
-		http.Error(w, "can`t marshal response", http.StatusInternalServerError)
-		return
-	}
-
-	_, err = w.Write(resp)
-	if err != nil {
-		gh.Logger.Errorw("can`t write response", "err:", err.Error())
-		http.Error(w, "can`t write response", http.StatusInternalServerError)
-		return
-	}
+	gh.writeJSON(w, http.StatusOK, makeFlightsInfoResponse(flightsPage, page, size))
 }
 
 func (gh *GatewayHandler) GetMe(w http.ResponseWriter, r *http.Request) {
-	userName := r.Header["X-User-Name"]
-	if len(userName) == 0 {
-		gh.Logger.Errorw("no X-User-Name header found")
-		http.Error(w, "unknown error", http.StatusBadRequest)
-		return
-	}
-
-	ticketReq, err := http.NewRequest("GET", ticketHost+"/api/v1/tickets", nil)
-	if err != nil {
-		gh.Logger.Errorw("can`t create ticket request")
-		http.Error(w, "can`t create ticket request", http.StatusInternalServerError)
-		return
-	}
-	ticketReq.Header["X-User-Name"] = userName
-
-	ticketResp, err := gh.Client.Do(ticketReq)
-	if err != nil {
-		gh.Logger.Errorw("can`t get tickets", "err:", err.Error())
-		http.Error(w, "can`t get tickets", http.StatusBadRequest)
-		return
-	}
-
-	ticketResponses := make([]models.TicketResponse, 0)
-	body, err := io.ReadAll(ticketResp.Body)
-	if err != nil {
-		gh.Logger.Errorw("can`t read body of request",
-			"err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
-		return
-	}
-
-	err = ticketResp.Body.Close()
-	if err != nil {
-		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
-		http.Error(w, "close error", http.StatusInternalServerError)
-		return
-	}
-
-	err = json.Unmarshal(body, &ticketResponses)
-	if err != nil {
-		gh.Logger.Infow("can`t unmarshal form",
-			"err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
 		return
 	}
 
-	flightResponses := make([]*models.FlightResponse, len(ticketResponses))
-
-	for i, ticketResponse := range ticketResponses {
-		flightResponse, err := gh.makeGetFlightRequest(ticketResponse.FlightNumber)
-		if err != nil {
-			gh.Logger.Errorw("can`t create flight request", "err:", err.Error())
-			http.Error(w, "can`t create flight request", http.StatusBadRequest)
-			return
-		}
+	ctx, cancel := context.WithTimeout(r.Context(), enrichmentTimeout)
+	defer cancel()
 
-		flightResponses[i] = flightResponse
-	}
-
-	privilegeReq, err := http.NewRequest("GET", bonusHost+"/api/v1/privileges", nil)
-	if err != nil {
-		gh.Logger.Errorw("can`t create privilege request", "err:", err.Error())
-		http.Error(w, "can`t create privilege request", http.StatusInternalServerError)
-		return
-	}
-	privilegeReq.Header["X-User-Name"] = userName
+	// Привилегия не зависит от билетов, поэтому запрашивается параллельно с ними
+	var privilegeResponse *models.PrivilegeResponse
+	var privilegeErr error
+	privilegeDone := make(chan struct{})
+	go func() {
+		defer close(privilegeDone)
+		privilegeResponse, privilegeErr = gh.BonusClient.GetPrivilege(ctx, userName)
+	}()
 
-	privilegeResp, err := gh.Client.Do(privilegeReq)
+	ticketResponses, err := gh.TicketClient.ListTicketsByUser(ctx, userName)
 	if err != nil {
-		gh.Logger.Errorw("can`t get privilege", "err:", err.Error())
-		http.Error(w, "can`t get privilege", http.StatusInternalServerError)
+		gh.writeClientError(w, err, "can`t get tickets")
 		return
 	}
 
-	privilegeResponse := &models.PrivilegeResponse{}
-	body, err = io.ReadAll(privilegeResp.Body)
+	flightResponses, err := gh.getFlightsForTickets(ctx, ticketResponses)
 	if err != nil {
-		gh.Logger.Errorw("can`t read body of request", "err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+		gh.writeClientError(w, err, "can`t get flights")
 		return
 	}
 
-	err = privilegeResp.Body.Close()
-	if err != nil {
-		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
-		http.Error(w, "close error", http.StatusInternalServerError)
-		return
-	}
+	<-privilegeDone
 
-	err = json.Unmarshal(body, privilegeResponse)
-	if err != nil {
-		gh.Logger.Infow("can`t unmarshal form", "err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
-		return
+	// Если Bonus Service недоступен, билеты всё равно отдаются с пустым блоком привилегии
+	if privilegeErr != nil {
+		gh.Logger.Errorw("can`t get privilege, using placeholder", "err:", privilegeErr.Error())
+		privilegeResponse = &models.PrivilegeResponse{}
 	}
 
-	res := makeUserInfoResponse(ticketResponses, flightResponses, privilegeResponse)
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	resp, err := json.Marshal(res)
-	if err != nil {
-		gh.Logger.Errorw("can`t marshal response", "err:", err.Error())
-		http.Error(w, "can`t marshal response", http.StatusInternalServerError)
-		return
-	}
-
-	_, err = w.Write(resp)
-	if err != nil {
-		gh.Logger.Errorw("can`t write response", "err:", err.Error())
-		http.Error(w, "can`t write response", http.StatusInternalServerError)
-		return
-	}
+	gh.writeJSON(w, http.StatusOK, makeUserInfoResponse(ticketResponses, flightResponses, privilegeResponse))
 }
 
 func (gh *GatewayHandler) GetTickets(w http.ResponseWriter, r *http.Request) {
-	userName := r.Header["X-User-Name"]
-	if len(userName) == 0 {
-		gh.Logger.Errorw("no X-User-Name header found")
-		http.Error(w, "unknown error", http.StatusBadRequest)
-		return
-	}
-
-	ticketReq, err := http.NewRequest("GET", ticketHost+"/api/v1/tickets", nil)
-	if err != nil {
-		gh.Logger.Errorw("can`t create ticket request")
-		http.Error(w, "can`t create ticket request", http.StatusInternalServerError)
-		return
-	}
-	ticketReq.Header["X-User-Name"] = userName
-
-	ticketResp, err := gh.Client.Do(ticketReq)
-	if err != nil {
-		gh.Logger.Errorw("can`t get tickets", "err:", err.Error())
-		http.Error(w, "can`t get tickets", http.StatusBadRequest)
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
 		return
 	}
 
-	ticketResponses := make([]*models.TicketResponse, 0)
-	body, err := io.ReadAll(ticketResp.Body)
-	if err != nil {
-		gh.Logger.Errorw("can`t read body of request",
-			"err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
-		return
-	}
-
-	err = ticketResp.Body.Close()
-	if err != nil {
-		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
-		http.Error(w, "close error", http.StatusInternalServerError)
-		return
-	}
+	ctx, cancel := context.WithTimeout(r.Context(), enrichmentTimeout)
+	defer cancel()
 
-	err = json.Unmarshal(body, &ticketResponses)
+	ticketResponses, err := gh.TicketClient.ListTicketsByUser(ctx, userName)
 	if err != nil {
-		gh.Logger.Infow("can`t unmarshal form",
-			"err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+		gh.writeClientError(w, err, "can`t get tickets")
 		return
 	}
 
-	flightResponses := make([]*models.FlightResponse, len(ticketResponses))
-
-	for i, ticketResponse := range ticketResponses {
-		flightResponse, err := gh.makeGetFlightRequest(ticketResponse.FlightNumber)
-		if err != nil {
-			gh.Logger.Errorw("can`t create flight request", "err:", err.Error())
-			http.Error(w, "can`t create flight request", http.StatusBadRequest)
-			return
-		}
-
-		flightResponses[i] = flightResponse
-	}
-
-	ticketInfoResponse := makeTicketInfoResponse(ticketResponses, flightResponses)
-
-	resp, err := json.Marshal(ticketInfoResponse)
-
+	flightResponses, err := gh.getFlightsForTickets(ctx, ticketResponses)
 	if err != nil {
-		gh.Logger.Errorw("can`t marshal ticketInfoResponse",
-			"err:", err.Error())
-		http.Error(w, "can`t make ticketInfoResponse", http.StatusInternalServerError)
+		gh.writeClientError(w, err, "can`t get flights")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
-	w.WriteHeader(http.StatusOK)
-
-	_, err = w.Write(resp)
-	if err != nil {
-		gh.Logger.Errorw("can`t write response",
-			"err:", err.Error())
-		http.Error(w, "can`t write response", http.StatusInternalServerError)
-		return
-	}
+	gh.writeJSON(w, http.StatusOK, makeTicketInfoResponse(ticketResponses, flightResponses))
 }
 
 func (gh *GatewayHandler) BuyTicket(w http.ResponseWriter, r *http.Request) {
 	buyInfo := models.BuyTicketInfo{}
-	buyInfoResponse := models.BuyTicketResponse{}
 
-	userName := r.Header["X-User-Name"]
-	if len(userName) == 0 {
-		gh.Logger.Errorw("no X-User-Name header found")
-		http.Error(w, "unknown error", http.StatusBadRequest)
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
 		return
 	}
 
@@ -523,380 +438,392 @@ func (gh *GatewayHandler) BuyTicket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Проверка, что рейс существует
-	flightResponse, err := gh.makeGetFlightRequest(buyInfo.FlightNumber)
-	if err != nil {
-		gh.Logger.Errorw("can`t create flight request", "err:", err.Error())
-		http.Error(w, "can`t create flight request", http.StatusBadRequest)
-		return
-	}
+	ctx := r.Context()
+	// Компенсации должны выполниться, даже если клиент уже отключился
+	compensateCtx := context.WithoutCancel(ctx)
 
-	// Инфа о бонусах
-	privilegeReq, err := http.NewRequest("GET", bonusHost+"/api/v1/privileges", nil)
-	if err != nil {
-		gh.Logger.Errorw("can`t create privilege request", "err:", err.Error())
-		http.Error(w, "can`t create privilege request", http.StatusInternalServerError)
-		return
-	}
-	privilegeReq.Header["X-User-Name"] = userName
+	p := newPurchase(userName, buyInfo.FlightNumber, buyInfo.FareClass, buyInfo.Price, models.TicketPaid)
+	var operationResponse *models.PrivilegeOperationResponse
 
-	privilegeResp, err := gh.Client.Do(privilegeReq)
-	if err != nil {
-		gh.Logger.Errorw("can`t get privilege", "err:", err.Error())
-		http.Error(w, "can`t get privilege", http.StatusInternalServerError)
-		return
-	}
+	buySaga := gh.addReservationSteps(saga.New("BuyTicket", gh.Logger), ctx, compensateCtx, p).
+		AddStep(gh.privilegeStep(ctx, compensateCtx, userName, p.ticketUID, buyInfo.PaidFromBalance,
+			func() int { return p.fare.Price }, &operationResponse))
 
-	privilegeResponse := &models.PrivilegeResponse{}
-	body, err = io.ReadAll(privilegeResp.Body)
+	err = buySaga.Run()
 	if err != nil {
-		gh.Logger.Errorw("can`t read body of request", "err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+		gh.Logger.Errorw("can`t buy ticket", "err:", err.Error())
+		gh.writeReservationError(w, err, p, "can`t buy ticket")
 		return
 	}
 
-	err = privilegeResp.Body.Close()
-	if err != nil {
-		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
-		http.Error(w, "close error", http.StatusInternalServerError)
-		return
-	}
+	gh.writeJSON(w, http.StatusOK, makeBuyTicketResponse(p.ticket, p.flight, operationResponse))
+}
 
-	err = json.Unmarshal(body, privilegeResponse)
-	if err != nil {
-		gh.Logger.Infow("can`t unmarshal form", "err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+func (gh *GatewayHandler) GetTicketByUID(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
 		return
 	}
 
-	balanceDiff := 0
-	operationType := "FILL_IN_BALANCE"
-
-	if buyInfo.PaidFromBalance {
-		operationType = "DEBIT_THE_ACCOUNT"
-		if privilegeResponse.Balance >= buyInfo.Price {
-			buyInfoResponse.PaidByBonuses = buyInfo.Price
-			buyInfoResponse.PaidByMoney = 0
-			balanceDiff = buyInfo.Price
-		} else {
-			buyInfoResponse.PaidByBonuses = privilegeResponse.Balance
-			buyInfoResponse.PaidByMoney = buyInfo.Price - privilegeResponse.Balance
-			balanceDiff = privilegeResponse.Balance
-		}
-	} else {
-		buyInfoResponse.PaidByBonuses = 0
-		buyInfoResponse.PaidByMoney = buyInfo.Price
-		balanceDiff = int(math.Round(0.1 * float64(buyInfo.Price)))
-		privilegeResponse.Balance += balanceDiff
-	}
-
-	privilegeResponse.Balance -= buyInfoResponse.PaidByBonuses
+	ticketUid := r.Context().Value("ticketUID").(string)
 
-	// Создание билета
-	ticketUID, err := gh.makeCreateTicketRequest(userName[0], buyInfo.FlightNumber, buyInfo.Price)
+	ticketResponse, err := gh.TicketClient.GetTicket(r.Context(), userName, ticketUid)
 	if err != nil {
-		gh.Logger.Errorw("can`t create ticket", "err:", err.Error())
-		http.Error(w, "can`t create ticket", http.StatusBadRequest)
+		gh.writeClientError(w, err, "can`t get ticket")
 		return
 	}
 
-	err = gh.makeCreatePrivilegeHistoryRequest(privilegeResponse, operationType, ticketUID, balanceDiff)
-
-	err = gh.makeUpdatePrivilegeRequest(privilegeResponse)
-
-	buyInfoResponse.UID = ticketUID
-	buyInfoResponse.FlightNumber = buyInfo.FlightNumber
-	buyInfoResponse.FromAirport = flightResponse.FromAirport
-	buyInfoResponse.ToAirport = flightResponse.ToAirport
-	buyInfoResponse.Date = flightResponse.Date
-	buyInfoResponse.Price = buyInfo.Price
-	buyInfoResponse.Status = "PAID"
-	buyInfoResponse.Privilege = *privilegeResponse
-
-	resp, err := json.Marshal(buyInfoResponse)
+	flightResponse, err := gh.FlightClient.GetFlightByNumber(r.Context(), ticketResponse.FlightNumber)
 	if err != nil {
-		gh.Logger.Errorw("can`t marshal buyInfoResponse",
-			"err:", err.Error())
-		http.Error(w, "can`t marshal buyInfoResponse", http.StatusInternalServerError)
+		gh.writeClientError(w, err, "can`t get flight")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
+	ticketInfoResponse := makeTicketInfoResponse([]*models.TicketResponse{ticketResponse}, []*models.FlightResponse{flightResponse})
 
-	_, err = w.Write(resp)
-	if err != nil {
-		gh.Logger.Errorw("can`t write response",
-			"err:", err.Error())
-		http.Error(w, "can`t write response", http.StatusInternalServerError)
-		return
-	}
+	gh.writeJSON(w, http.StatusOK, ticketInfoResponse[0])
 }
 
-func (gh *GatewayHandler) GetTicketByUID(w http.ResponseWriter, r *http.Request) {
-	userName := r.Header["X-User-Name"]
-	if len(userName) == 0 {
-		gh.Logger.Errorw("no X-User-Name header found")
-		http.Error(w, "unknown error", http.StatusBadRequest)
+func (gh *GatewayHandler) ReturnTicket(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
 		return
 	}
 
 	ticketUid := r.Context().Value("ticketUID").(string)
 
-	ticketReq, err := http.NewRequest("GET", ticketHost+"/api/v1/ticketsByUID", nil)
+	ticketResponse, err := gh.TicketClient.GetTicket(r.Context(), userName, ticketUid)
 	if err != nil {
-		gh.Logger.Errorw("can`t create ticket request")
-		http.Error(w, "can`t create ticket request", http.StatusInternalServerError)
+		gh.writeClientError(w, err, "can`t get ticket")
 		return
 	}
-	ticketReq.Header["X-User-Name"] = userName
-	ticketReq.Header["X-Ticket-Uid"] = []string{ticketUid}
 
-	ticketResp, err := gh.Client.Do(ticketReq)
-	if err != nil {
-		gh.Logger.Errorw("can`t get tickets", "err:", err.Error())
-		http.Error(w, "can`t get tickets", http.StatusBadRequest)
+	switch ticketResponse.Status {
+	case models.TicketHeld, models.TicketPaid:
+	case models.TicketCanceled:
+		gh.Logger.Infow("ticket already returned", "ticketUid", ticketUid)
+		http.Error(w, "ticket already returned", http.StatusConflict)
 		return
-	}
-
-	ticketResponse := &models.TicketResponse{}
-	body, err := io.ReadAll(ticketResp.Body)
-	if err != nil {
-		gh.Logger.Errorw("can`t read body of request",
-			"err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+	case models.TicketExpired:
+		gh.Logger.Infow("ticket hold has expired", "ticketUid", ticketUid)
+		http.Error(w, "ticket hold has expired", http.StatusConflict)
 		return
-	}
-
-	err = ticketResp.Body.Close()
-	if err != nil {
-		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
-		http.Error(w, "close error", http.StatusInternalServerError)
+	default:
+		gh.Logger.Infow("ticket can`t be returned", "ticketUid", ticketUid, "status", ticketResponse.Status)
+		http.Error(w, "ticket can`t be returned in status "+ticketResponse.Status, http.StatusConflict)
 		return
 	}
 
-	err = json.Unmarshal(body, &ticketResponse)
-	if err != nil {
-		gh.Logger.Infow("can`t unmarshal form",
-			"err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+	// Бонусы за маршрут начислены на весь маршрут, поэтому перелёт отдельно не возвращается
+	if ticketResponse.ItineraryUID != "" {
+		gh.Logger.Infow("return of itinerary leg", "ticketUid", ticketUid, "itineraryUid", ticketResponse.ItineraryUID)
+		http.Error(w, "ticket is a leg of itinerary "+ticketResponse.ItineraryUID+": return the whole itinerary", http.StatusConflict)
 		return
 	}
 
-	flightResponse := &models.FlightResponse{}
+	// Неоплаченную бронь можно отменить при любом тарифе, бонусов по ней не было
+	held := ticketResponse.Status == models.TicketHeld
 
-	flightResponse, err = gh.makeGetFlightRequest(ticketResponse.FlightNumber)
+	flightResponse, err := gh.FlightClient.GetFlightByNumber(r.Context(), ticketResponse.FlightNumber)
 	if err != nil {
-		gh.Logger.Errorw("can`t create flight request", "err:", err.Error())
-		http.Error(w, "can`t create flight request", http.StatusBadRequest)
+		gh.writeClientError(w, err, "can`t get flight")
 		return
 	}
-
-	ticketInfoResponse := makeTicketInfoResponse([]*models.TicketResponse{ticketResponse}, []*models.FlightResponse{flightResponse})
-
-	resp, err := json.Marshal(ticketInfoResponse[0])
-
-	if err != nil {
-		gh.Logger.Errorw("can`t marshal ticketInfoResponse",
-			"err:", err.Error())
-		http.Error(w, "can`t make ticketInfoResponse", http.StatusInternalServerError)
+	// Билеты отменённого рейса возвращает администратор при отмене
+	if flightResponse.Closed() {
+		http.Error(w, "can`t return ticket: "+errFlightClosed.Error(), http.StatusConflict)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
-	w.WriteHeader(http.StatusOK)
-
-	_, err = w.Write(resp)
+	fares, err := gh.FlightClient.GetFares(r.Context(), flightResponse.ID)
 	if err != nil {
-		gh.Logger.Errorw("can`t write response",
-			"err:", err.Error())
-		http.Error(w, "can`t write response", http.StatusInternalServerError)
+		gh.writeClientError(w, err, "can`t get fares")
 		return
 	}
-}
 
-func (gh *GatewayHandler) ReturnTicket(w http.ResponseWriter, r *http.Request) {
-	userName := r.Header["X-User-Name"]
-	if len(userName) == 0 {
-		gh.Logger.Errorw("no X-User-Name header found")
-		http.Error(w, "unknown error", http.StatusBadRequest)
+	// Если класс с рейса уже убрали, правила возврата проверить не по чему — билет возвращается
+	fare, err := findFare(fares, ticketResponse.FareClass)
+	if !held && err == nil && !fare.Refundable {
+		gh.Logger.Infow("ticket fare is not refundable", "ticketUid", ticketUid, "fareClass", fare.FareClass)
+		http.Error(w, "ticket fare is not refundable", http.StatusConflict)
 		return
 	}
 
-	ticketUid := r.Context().Value("ticketUID").(string)
+	ctx := r.Context()
+	compensateCtx := context.WithoutCancel(ctx)
 
-	ticketReq, err := http.NewRequest("GET", ticketHost+"/api/v1/ticketsByUID", nil)
-	if err != nil {
-		gh.Logger.Errorw("can`t create ticket request")
-		http.Error(w, "can`t create ticket request", http.StatusInternalServerError)
+	// На билет, полученный обменом, записана только разница тарифов, поэтому
+	// откатываются движения бонусов по всей цепочке обменов. Откат по
+	// билету повторно ничего не меняет, так что повтор возврата безопасен
+	bonusKeys := []string{ticketUid}
+	if !held {
+		previous, err := gh.TicketClient.ExchangedChain(ctx, userName, ticketUid)
+		if err != nil {
+			gh.writeClientError(w, err, "can`t get exchanged tickets")
+			return
+		}
+		bonusKeys = append(bonusKeys, previous...)
+	}
+
+	returnSaga := saga.New("ReturnTicket", gh.Logger).
+		AddStep(saga.Step{
+			Name: "cancel ticket",
+			Action: func() error {
+				return gh.TicketClient.CancelTicket(ctx, userName, ticketUid)
+			},
+			Compensate: func() error {
+				// Откатить статус билета Ticket Service даёт только gateway
+				serviceCtx, err := gh.serviceContext(compensateCtx, userName)
+				if err != nil {
+					return err
+				}
+				return gh.TicketClient.RevertTicketStatus(serviceCtx, userName, ticketUid, ticketResponse.Status)
+			},
+		}).
+		AddStep(saga.Step{
+			Name: "release seat",
+			Action: func() error {
+				return gh.FlightClient.ReleaseSeat(ctx, ticketUid)
+			},
+			Compensate: func() error {
+				return gh.FlightClient.ReserveSeat(compensateCtx, flightResponse.ID, ticketResponse.FareClass, ticketUid)
+			},
+		})
+	if !held {
+		returnSaga.AddStep(saga.Step{
+			Name: "revert privilege history",
+			Action: func() error {
+				for _, bonusKey := range bonusKeys {
+					_, err := gh.BonusClient.RevertHistory(ctx, userName, bonusKey)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		})
+	}
+
+	err = returnSaga.Run()
+	if err != nil {
+		gh.Logger.Errorw("can`t return ticket", "err:", err.Error())
+		writeUnavailable(w, err, "can`t return ticket: return was rolled back")
 		return
 	}
-	ticketReq.Header["X-User-Name"] = userName
-	ticketReq.Header["X-Ticket-Uid"] = []string{ticketUid}
 
-	ticketResp, err := gh.Client.Do(ticketReq)
-	if err != nil {
-		gh.Logger.Errorw("can`t get tickets", "err:", err.Error())
-		http.Error(w, "can`t get tickets", http.StatusBadRequest)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (gh *GatewayHandler) GetPrivilege(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
 		return
 	}
 
-	ticketResponse := models.TicketResponse{}
-	body, err := io.ReadAll(ticketResp.Body)
+	privilegeResponse, err := gh.BonusClient.GetPrivilege(r.Context(), userName)
 	if err != nil {
-		gh.Logger.Errorw("can`t read body of request",
-			"err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+		gh.writeClientError(w, err, "can`t get privilege")
 		return
 	}
 
-	err = ticketResp.Body.Close()
+	privilegeHistoryResponse, err := gh.BonusClient.GetHistory(r.Context(), userName)
 	if err != nil {
-		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
-		http.Error(w, "close error", http.StatusInternalServerError)
+		gh.writeClientError(w, err, "can`t get privilege history")
 		return
 	}
 
-	err = json.Unmarshal(body, &ticketResponse)
+	gh.writeJSON(w, http.StatusOK, makePrivilegeFullResponse(*privilegeResponse, privilegeHistoryResponse))
+}
+
+// GetFlightFares — классы обслуживания рейса с ценами и правилами возврата и обмена.
+func (gh *GatewayHandler) GetFlightFares(w http.ResponseWriter, r *http.Request) {
+	flightResponse, err := gh.FlightClient.GetFlightByNumber(r.Context(), r.PathValue("flightNumber"))
 	if err != nil {
-		gh.Logger.Infow("can`t unmarshal form",
-			"err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+		gh.writeClientError(w, err, "can`t get flight")
 		return
 	}
 
-	ticketResponse.Status = "CANCELED"
-
-	//TODO: Сделать запрос в PrivilegeHistory, чтобы отменить эту операцию
-
-	jsonTicketResponse, err := json.Marshal(ticketResponse)
+	fares, err := gh.FlightClient.GetFares(r.Context(), flightResponse.ID)
 	if err != nil {
-		gh.Logger.Errorw("can`t marshal ticketResponse",
-			"err:", err.Error())
-		http.Error(w, "can`t make ticketResponse", http.StatusInternalServerError)
+		gh.writeClientError(w, err, "can`t get fares")
 		return
 	}
 
-	ticketReq, err = http.NewRequest("PATCH", ticketHost+"/api/v1/tickets", bytes.NewBuffer(jsonTicketResponse))
+	gh.writeJSON(w, http.StatusOK, fares)
+}
+
+// GetFlightSeats — схема салона рейса со свободными креслами.
+func (gh *GatewayHandler) GetFlightSeats(w http.ResponseWriter, r *http.Request) {
+	flightResponse, err := gh.FlightClient.GetFlightByNumber(r.Context(), r.PathValue("flightNumber"))
 	if err != nil {
-		gh.Logger.Errorw("can`t create ticket request")
-		http.Error(w, "can`t create ticket request", http.StatusInternalServerError)
+		gh.writeClientError(w, err, "can`t get flight")
 		return
 	}
 
-	_, err = gh.Client.Do(ticketReq)
+	seats, err := gh.FlightClient.GetSeatMap(r.Context(), flightResponse.ID)
 	if err != nil {
-		gh.Logger.Errorw("can`t cancel ticket", "err:", err.Error())
-		http.Error(w, "can`t cancel ticket", http.StatusBadRequest)
+		gh.writeClientError(w, err, "can`t get seat map")
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	gh.writeJSON(w, http.StatusOK, seats)
 }
 
-func (gh *GatewayHandler) GetPrivilege(w http.ResponseWriter, r *http.Request) {
-	userName := r.Header["X-User-Name"]
-	if len(userName) == 0 {
-		gh.Logger.Errorw("no X-User-Name header found")
-		http.Error(w, "unknown error", http.StatusBadRequest)
+// SelectSeat выбирает или меняет кресло в билете пользователя. Кресло сначала
+// закрепляется во Flight Service (там же решается гонка за одно место), затем
+// записывается в билет; при ошибке возвращается прежнее кресло.
+func (gh *GatewayHandler) SelectSeat(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
 		return
 	}
 
-	privilegeReq, err := http.NewRequest("GET", bonusHost+"/api/v1/privileges", nil)
+	seatRequest := models.SeatSelectionRequest{}
+
+	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		gh.Logger.Errorw("can`t create privilege request", "err:", err.Error())
-		http.Error(w, "can`t create privilege request", http.StatusInternalServerError)
+		gh.Logger.Errorw("can`t read body of request",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
 		return
 	}
-	privilegeReq.Header["X-User-Name"] = userName
 
-	privilegeResp, err := gh.Client.Do(privilegeReq)
+	err = r.Body.Close()
 	if err != nil {
-		gh.Logger.Errorw("can`t get privilege", "err:", err.Error())
-		http.Error(w, "can`t get privilege", http.StatusInternalServerError)
+		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
+		http.Error(w, "close error", http.StatusInternalServerError)
 		return
 	}
 
-	privilegeResponse := &models.PrivilegeResponse{}
-	body, err := io.ReadAll(privilegeResp.Body)
-	if err != nil {
-		gh.Logger.Errorw("can`t read body of request", "err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+	err = json.Unmarshal(body, &seatRequest)
+	if err != nil || seatRequest.SeatNumber == "" {
+		gh.Logger.Infow("can`t unmarshal form")
+		http.Error(w, "bad data: seatNumber is required", http.StatusBadRequest)
 		return
 	}
 
-	err = privilegeResp.Body.Close()
+	ctx := r.Context()
+	compensateCtx := context.WithoutCancel(ctx)
+	ticketUid := r.PathValue("ticketUid")
+
+	ticketResponse, err := gh.TicketClient.GetTicket(ctx, userName, ticketUid)
 	if err != nil {
-		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
-		http.Error(w, "close error", http.StatusInternalServerError)
+		gh.writeClientError(w, err, "can`t get ticket")
 		return
 	}
 
-	err = json.Unmarshal(body, privilegeResponse)
-	if err != nil {
-		gh.Logger.Infow("can`t unmarshal form", "err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+	if ticketResponse.Status != "PAID" {
+		gh.Logger.Infow("seat selection for inactive ticket", "ticketUid", ticketUid, "status", ticketResponse.Status)
+		http.Error(w, "seat can be selected only for a paid ticket", http.StatusConflict)
 		return
 	}
 
-	privilegeHistoryReq, err := http.NewRequest("GET", bonusHost+"/api/v1/privilegeHistory", nil)
+	flightResponse, err := gh.FlightClient.GetFlightByNumber(ctx, ticketResponse.FlightNumber)
 	if err != nil {
-		gh.Logger.Errorw("can`t create privilege request", "err:", err.Error())
-		http.Error(w, "can`t create privilege request", http.StatusInternalServerError)
+		gh.writeClientError(w, err, "can`t get flight")
 		return
 	}
-	privilegeHistoryReq.Header["X-User-Name"] = userName
 
-	privilegeHistoryResp, err := gh.Client.Do(privilegeHistoryReq)
+	seatSaga := saga.New("SelectSeat", gh.Logger).
+		AddStep(saga.Step{
+			Name: "assign seat",
+			Action: func() error {
+				return gh.FlightClient.AssignSeat(ctx, ticketUid, seatRequest.SeatNumber)
+			},
+			Compensate: func() error {
+				return gh.FlightClient.AssignSeat(compensateCtx, ticketUid, ticketResponse.Seat)
+			},
+		}).
+		AddStep(saga.Step{
+			Name: "update ticket seat",
+			Action: func() error {
+				return gh.TicketClient.UpdateTicketSeat(ctx, ticketUid, seatRequest.SeatNumber)
+			},
+		})
+
+	err = seatSaga.Run()
 	if err != nil {
-		gh.Logger.Errorw("can`t get privilege", "err:", err.Error())
-		http.Error(w, "can`t get privilege", http.StatusInternalServerError)
+		var apiErr *apiclient.Error
+		switch {
+		case errors.Is(err, apiclient.ErrConflict):
+			gh.Logger.Infow("can`t select seat", "err:", err.Error())
+			http.Error(w, "seat is already taken", http.StatusConflict)
+		case errors.Is(err, apiclient.ErrBadRequest) && errors.As(err, &apiErr):
+			gh.Logger.Infow("can`t select seat", "err:", err.Error())
+			http.Error(w, apiErr.Message, http.StatusBadRequest)
+		default:
+			gh.writeClientError(w, err, "can`t select seat")
+		}
 		return
 	}
 
-	privilegeHistoryResponse := make([]models.PrivilegeHistoryResponse, 0)
-	body, err = io.ReadAll(privilegeHistoryResp.Body)
-	if err != nil {
-		gh.Logger.Errorw("can`t read body of request", "err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+	ticketResponse.Seat = seatRequest.SeatNumber
+	ticketInfoResponse := makeTicketInfoResponse([]*models.TicketResponse{ticketResponse}, []*models.FlightResponse{flightResponse})
+
+	gh.writeJSON(w, http.StatusOK, ticketInfoResponse[0])
+}
+
+// CheckIn регистрирует пассажира на рейс по оплаченному билету.
+func (gh *GatewayHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
 		return
 	}
 
-	err = privilegeHistoryResp.Body.Close()
+	ticketResponse, err := gh.TicketClient.CheckInTicket(r.Context(), userName, r.PathValue("ticketUid"))
 	if err != nil {
-		gh.Logger.Errorw("can`t close body of request", "err:", err.Error())
-		http.Error(w, "close error", http.StatusInternalServerError)
+		if errors.Is(err, apiclient.ErrConflict) {
+			gh.Logger.Infow("can`t check in", "err:", err.Error())
+			http.Error(w, "only a paid ticket can be checked in", http.StatusConflict)
+			return
+		}
+		gh.writeClientError(w, err, "can`t check in")
 		return
 	}
 
-	err = json.Unmarshal(body, &privilegeHistoryResponse)
+	flightResponse, err := gh.FlightClient.GetFlightByNumber(r.Context(), ticketResponse.FlightNumber)
 	if err != nil {
-		gh.Logger.Infow("can`t unmarshal form", "err:", err.Error())
-		http.Error(w, "bad data", http.StatusBadRequest)
+		gh.writeClientError(w, err, "can`t get flight")
 		return
 	}
 
-	privilegeFullResponse := makePrivilegeFullResponse(*privilegeResponse, privilegeHistoryResponse)
+	ticketInfoResponse := makeTicketInfoResponse([]*models.TicketResponse{ticketResponse}, []*models.FlightResponse{flightResponse})
 
-	resp, err := json.Marshal(privilegeFullResponse)
+	gh.writeJSON(w, http.StatusOK, ticketInfoResponse[0])
+}
 
-	if err != nil {
-		gh.Logger.Errorw("can`t marshal privilegeFullResponse",
-			"err:", err.Error())
-		http.Error(w, "can`t make privilegeFullResponse", http.StatusInternalServerError)
-		return
+// FindRoutes ищет маршруты с пересадками, см. routeQueryParams.
+func (gh *GatewayHandler) FindRoutes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := url.Values{}
+	for _, name := range routeQueryParams {
+		if value := q.Get(name); value != "" {
+			query.Set(name, value)
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
-	w.WriteHeader(http.StatusOK)
-
-	_, err = w.Write(resp)
+	routes, err := gh.FlightClient.FindRoutes(r.Context(), query)
 	if err != nil {
-		gh.Logger.Errorw("can`t write response",
-			"err:", err.Error())
-		http.Error(w, "can`t write response", http.StatusInternalServerError)
+		var apiErr *apiclient.Error
+		if errors.Is(err, apiclient.ErrBadRequest) && errors.As(err, &apiErr) {
+			gh.Logger.Infow("can`t find routes", "err:", err.Error())
+			http.Error(w, apiErr.Message, http.StatusBadRequest)
+			return
+		}
+		gh.Logger.Errorw("can`t find routes", "err:", err.Error())
+		writeUnavailable(w, err, "flight service is unavailable")
 		return
 	}
+
+	gh.writeJSON(w, http.StatusOK, routes)
 }
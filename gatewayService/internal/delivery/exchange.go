@@ -0,0 +1,188 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+
+	"flight_booking_system/gatewayService/internal/saga"
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"github.com/pkg/errors"
+)
+
+// ExchangeTicket обменивает оплаченный билет на билет на другой рейс или в
+// другом классе. Разница тарифов вместе со сбором за обмен доплачивается
+// деньгами или бонусами, а если новый билет дешевле — возвращается на
+// бонусный счёт. Движения бонусов записываются на новый билет.
+func (gh *GatewayHandler) ExchangeTicket(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
+		return
+	}
+
+	exchangeRequest := models.ExchangeTicketRequest{}
+	if !gh.readBody(w, r, &exchangeRequest) {
+		return
+	}
+	if exchangeRequest.FlightNumber == "" {
+		gh.Logger.Infow("no flight number in exchange request")
+		http.Error(w, "bad data: flightNumber is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	compensateCtx := context.WithoutCancel(ctx)
+	ticketUid := r.PathValue("ticketUid")
+
+	oldTicket, err := gh.TicketClient.GetTicket(ctx, userName, ticketUid)
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get ticket")
+		return
+	}
+
+	if oldTicket.Status != models.TicketPaid {
+		gh.Logger.Infow("exchange of inactive ticket", "ticketUid", ticketUid, "status", oldTicket.Status)
+		http.Error(w, "only a paid ticket can be exchanged", http.StatusConflict)
+		return
+	}
+
+	if oldTicket.ItineraryUID != "" {
+		gh.Logger.Infow("exchange of itinerary leg", "ticketUid", ticketUid, "itineraryUid", oldTicket.ItineraryUID)
+		http.Error(w, "a leg of itinerary can`t be exchanged", http.StatusConflict)
+		return
+	}
+
+	oldFlight, err := gh.FlightClient.GetFlightByNumber(ctx, oldTicket.FlightNumber)
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get flight")
+		return
+	}
+	if oldFlight.Closed() {
+		http.Error(w, "can`t exchange ticket: "+errFlightClosed.Error(), http.StatusConflict)
+		return
+	}
+
+	fares, err := gh.FlightClient.GetFares(ctx, oldFlight.ID)
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get fares")
+		return
+	}
+
+	// Как и при возврате: если класс с рейса уже убрали, билет меняется без сбора
+	exchangeFee := 0
+	oldFare, err := findFare(fares, oldTicket.FareClass)
+	if err == nil {
+		if !oldFare.Exchangeable {
+			gh.Logger.Infow("ticket fare is not exchangeable", "ticketUid", ticketUid, "fareClass", oldFare.FareClass)
+			http.Error(w, "ticket fare is not exchangeable", http.StatusConflict)
+			return
+		}
+		exchangeFee = oldFare.ExchangeFee
+	}
+
+	p := newPurchase(userName, exchangeRequest.FlightNumber, exchangeRequest.FareClass, exchangeRequest.Price, models.TicketPaid)
+	if p.flightNumber == oldTicket.FlightNumber && p.fareClass == oldTicket.FareClass {
+		gh.Logger.Infow("exchange for the same flight and fare", "ticketUid", ticketUid)
+		http.Error(w, "ticket is already for this flight and fare class", http.StatusBadRequest)
+		return
+	}
+
+	difference := 0
+	var operation *models.PrivilegeOperationResponse
+
+	exchangeSaga := gh.addReservationSteps(saga.New("ExchangeTicket", gh.Logger), ctx, compensateCtx, p).
+		AddStep(saga.Step{
+			Name: "settle fare difference",
+			Action: func() (err error) {
+				difference = p.fare.Price + exchangeFee - oldTicket.Price
+
+				op := models.PrivilegeOperationRequest{
+					Username:      userName,
+					TicketUID:     p.ticketUID,
+					OperationType: "FILL_IN_BALANCE",
+					Amount:        difference,
+				}
+				opCtx := ctx
+				switch {
+				case difference == 0:
+					return nil
+				case difference < 0:
+					// Зачисление без оплаты Bonus Service принимает только от gateway
+					op.OperationType = "REFUND"
+					op.Amount = -difference
+					opCtx, err = gh.serviceContext(ctx, userName)
+					if err != nil {
+						return err
+					}
+				case exchangeRequest.PaidFromBalance:
+					op.OperationType = "DEBIT_THE_ACCOUNT"
+				}
+
+				operation, err = gh.BonusClient.ApplyOperation(opCtx, op)
+				return err
+			},
+			Compensate: func() error {
+				_, err := gh.BonusClient.RevertHistory(compensateCtx, userName, p.ticketUID)
+				return err
+			},
+		}).
+		AddStep(saga.Step{
+			Name: "release old seat",
+			Action: func() error {
+				return gh.FlightClient.ReleaseSeat(ctx, ticketUid)
+			},
+			Compensate: func() error {
+				return gh.FlightClient.ReserveSeat(compensateCtx, oldFlight.ID, oldTicket.FareClass, ticketUid)
+			},
+		}).
+		AddStep(saga.Step{
+			Name: "mark ticket exchanged",
+			Action: func() error {
+				return gh.TicketClient.ExchangeTicket(ctx, userName, ticketUid, p.ticketUID)
+			},
+		})
+
+	err = exchangeSaga.Run()
+	if err != nil {
+		gh.Logger.Errorw("can`t exchange ticket", "err:", err.Error())
+		var stepErr *saga.StepError
+		if errors.As(err, &stepErr) && stepErr.Step == "mark ticket exchanged" && errors.Is(err, apiclient.ErrConflict) {
+			http.Error(w, "can`t exchange ticket: ticket status has changed", http.StatusConflict)
+			return
+		}
+		gh.writeReservationError(w, err, p, "can`t exchange ticket")
+		return
+	}
+
+	gh.writeJSON(w, http.StatusOK, makeExchangeTicketResponse(ticketUid, p, exchangeFee, difference, operation))
+}
+
+func makeExchangeTicketResponse(oldTicketUID string, p *purchase, exchangeFee, difference int, operation *models.PrivilegeOperationResponse) models.ExchangeTicketResponse {
+	res := models.ExchangeTicketResponse{
+		ExchangedTicketUID: oldTicketUID,
+		Ticket:             makeTicketInfoResponse([]*models.TicketResponse{p.ticket}, []*models.FlightResponse{p.flight})[0],
+		ExchangeFee:        exchangeFee,
+		FareDifference:     difference,
+	}
+
+	if difference < 0 {
+		res.RefundedToBalance = -difference
+	}
+	if operation != nil {
+		res.Privilege = &models.PrivilegeInfo{
+			ID:      operation.ID,
+			Balance: operation.Balance,
+			Status:  operation.Status,
+		}
+		if operation.OperationType == "DEBIT_THE_ACCOUNT" {
+			res.PaidByBonuses = operation.BalanceDiff
+		}
+	}
+	if difference > 0 {
+		res.PaidByMoney = difference - res.PaidByBonuses
+	}
+
+	return res
+}
@@ -0,0 +1,314 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+
+	"flight_booking_system/gatewayService/internal/saga"
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// errLegsOrder — перелёты маршрута идут не в порядке вылета.
+var errLegsOrder = errors.New("itinerary legs must be in departure order")
+
+// failedLeg выбирает перелёт, на котором упали шаги тарифа: с изменившейся
+// ценой, а если такого нет — последний, до которого дошла сага.
+func failedLeg(legs []*purchase) *purchase {
+	last := legs[0]
+	for _, p := range legs {
+		if p.fare != nil && p.quotedPrice != 0 && p.quotedPrice != p.fare.Price {
+			return p
+		}
+		if p.flight != nil {
+			last = p
+		}
+	}
+
+	return last
+}
+
+func itineraryPrice(legs []*purchase) int {
+	price := 0
+	for _, p := range legs {
+		price += p.fare.Price
+	}
+
+	return price
+}
+
+// BuyItinerary покупает маршрут из нескольких перелётов одной покупкой:
+// места на всех перелётах резервируются вместе, а бонусы считаются один раз
+// от цены всего маршрута и записываются на его UID.
+func (gh *GatewayHandler) BuyItinerary(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
+		return
+	}
+
+	itineraryRequest := models.ItineraryRequest{}
+	if !gh.readBody(w, r, &itineraryRequest) {
+		return
+	}
+	if len(itineraryRequest.Legs) < 2 {
+		gh.Logger.Infow("itinerary with less than two legs", "legs", len(itineraryRequest.Legs))
+		http.Error(w, "bad data: itinerary must have at least two legs", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	compensateCtx := context.WithoutCancel(ctx)
+
+	itineraryUID := uuid.New().String()
+	legs := make([]*purchase, len(itineraryRequest.Legs))
+	itinerarySaga := saga.New("BuyItinerary", gh.Logger)
+	for i, leg := range itineraryRequest.Legs {
+		legs[i] = newPurchase(userName, leg.FlightNumber, leg.FareClass, leg.Price, models.TicketPaid)
+		gh.addFareSteps(itinerarySaga, ctx, legs[i])
+	}
+
+	itinerarySaga.AddStep(saga.Step{
+		Name: "check legs order",
+		Action: func() error {
+			for i := 1; i < len(legs); i++ {
+				if !legs[i].flight.Date.After(legs[i-1].flight.Date) {
+					return errors.Wrapf(errLegsOrder, "%s departs before %s", legs[i].flightNumber, legs[i-1].flightNumber)
+				}
+			}
+			return nil
+		},
+	})
+	for _, p := range legs {
+		itinerarySaga.AddStep(saga.Step{
+			Name: "reserve seat",
+			Action: func() error {
+				return gh.FlightClient.ReserveSeat(ctx, p.flight.ID, p.fare.FareClass, p.ticketUID)
+			},
+			Compensate: func() error {
+				return gh.FlightClient.ReleaseSeat(compensateCtx, p.ticketUID)
+			},
+		})
+	}
+
+	var itinerary *models.ItineraryServiceResponse
+	var operationResponse *models.PrivilegeOperationResponse
+
+	itinerarySaga.
+		AddStep(saga.Step{
+			Name: "create itinerary",
+			Action: func() (err error) {
+				tickets := make([]models.ItineraryTicketRequest, len(legs))
+				for i, p := range legs {
+					tickets[i] = models.ItineraryTicketRequest{
+						UID:          p.ticketUID,
+						FlightNumber: p.flightNumber,
+						Price:        p.fare.Price,
+						FareClass:    p.fare.FareClass,
+					}
+				}
+
+				itinerary, err = gh.TicketClient.CreateItinerary(ctx, userName, models.ItineraryCreateRequest{
+					ItineraryUID: itineraryUID,
+					Tickets:      tickets,
+				})
+				return err
+			},
+			Compensate: func() error {
+				return gh.TicketClient.CancelItinerary(compensateCtx, userName, itineraryUID)
+			},
+		}).
+		AddStep(gh.privilegeStep(ctx, compensateCtx, userName, itineraryUID, itineraryRequest.PaidFromBalance,
+			func() int { return itineraryPrice(legs) }, &operationResponse))
+
+	err := itinerarySaga.Run()
+	if err != nil {
+		gh.Logger.Errorw("can`t buy itinerary", "err:", err.Error())
+		var stepErr *saga.StepError
+		switch {
+		case errors.Is(err, errLegsOrder):
+			http.Error(w, "can`t buy itinerary: "+errLegsOrder.Error(), http.StatusBadRequest)
+		case errors.As(err, &stepErr) && stepErr.Step == "create itinerary" && errors.Is(err, apiclient.ErrBadRequest):
+			http.Error(w, "can`t buy itinerary: invalid itinerary", http.StatusBadRequest)
+		default:
+			gh.writeReservationError(w, err, failedLeg(legs), "can`t buy itinerary")
+		}
+		return
+	}
+
+	flights := make([]*models.FlightResponse, len(legs))
+	for i, p := range legs {
+		flights[i] = p.flight
+	}
+
+	w.Header().Set("Location", "/api/v1/itineraries/"+itineraryUID)
+	gh.writeJSON(w, http.StatusCreated, makeItineraryResponse(itinerary, flights, operationResponse))
+}
+
+// makeItineraryResponse собирает ответ о покупке; flights идут в порядке
+// билетов маршрута.
+func makeItineraryResponse(itinerary *models.ItineraryServiceResponse, flights []*models.FlightResponse, operation *models.PrivilegeOperationResponse) models.ItineraryResponse {
+	res := models.ItineraryResponse{
+		ItineraryUID: itinerary.ItineraryUID,
+		Tickets:      makeTicketInfoResponse(itinerary.Tickets, flights),
+		Privilege: models.PrivilegeResponse{
+			ID:      operation.ID,
+			Balance: operation.Balance,
+			Status:  operation.Status,
+		},
+	}
+
+	for _, ticket := range itinerary.Tickets {
+		res.TotalPrice += ticket.Price
+	}
+	if operation.OperationType == "DEBIT_THE_ACCOUNT" {
+		res.PaidByBonuses = operation.BalanceDiff
+	}
+	res.PaidByMoney = res.TotalPrice - res.PaidByBonuses
+
+	return res
+}
+
+func (gh *GatewayHandler) GetItinerary(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
+		return
+	}
+
+	itinerary, err := gh.TicketClient.GetItinerary(r.Context(), userName, r.PathValue("itineraryUid"))
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get itinerary")
+		return
+	}
+
+	flights, err := gh.getFlightsForTickets(r.Context(), itinerary.Tickets)
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get flights")
+		return
+	}
+
+	res := models.ItineraryInfo{
+		ItineraryUID: itinerary.ItineraryUID,
+		CreatedAt:    itinerary.CreatedAt,
+		Tickets:      makeTicketInfoResponse(itinerary.Tickets, flights),
+	}
+	for _, ticket := range itinerary.Tickets {
+		res.TotalPrice += ticket.Price
+	}
+
+	gh.writeJSON(w, http.StatusOK, res)
+}
+
+// ReturnItinerary возвращает маршрут целиком: отменяет все перелёты,
+// освобождает места и откатывает бонусы за маршрут. Вернуть можно только
+// маршрут, все перелёты которого оплачены и возвратны.
+func (gh *GatewayHandler) ReturnItinerary(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := r.Context()
+	compensateCtx := context.WithoutCancel(ctx)
+	itineraryUid := r.PathValue("itineraryUid")
+
+	itinerary, err := gh.TicketClient.GetItinerary(ctx, userName, itineraryUid)
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get itinerary")
+		return
+	}
+
+	for _, ticket := range itinerary.Tickets {
+		if ticket.Status != models.TicketPaid {
+			gh.Logger.Infow("itinerary leg can`t be returned", "itineraryUid", itineraryUid,
+				"ticketUid", ticket.TicketUID, "status", ticket.Status)
+			http.Error(w, "itinerary can`t be returned: leg "+ticket.FlightNumber+" is "+ticket.Status, http.StatusConflict)
+			return
+		}
+	}
+
+	flights, err := gh.getFlightsForTickets(ctx, itinerary.Tickets)
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get flights")
+		return
+	}
+
+	for i, ticket := range itinerary.Tickets {
+		fares, err := gh.FlightClient.GetFares(ctx, flights[i].ID)
+		if err != nil {
+			gh.writeClientError(w, err, "can`t get fares")
+			return
+		}
+
+		// Как и при возврате билета: если класса на рейсе уже нет, перелёт возвращается
+		fare, err := findFare(fares, ticket.FareClass)
+		if err == nil && !fare.Refundable {
+			gh.Logger.Infow("itinerary leg fare is not refundable", "itineraryUid", itineraryUid,
+				"ticketUid", ticket.TicketUID, "fareClass", fare.FareClass)
+			http.Error(w, "itinerary can`t be returned: leg "+ticket.FlightNumber+" fare is not refundable", http.StatusConflict)
+			return
+		}
+	}
+
+	returnSaga := saga.New("ReturnItinerary", gh.Logger).
+		AddStep(saga.Step{
+			Name: "cancel itinerary",
+			Action: func() error {
+				return gh.TicketClient.CancelItinerary(ctx, userName, itineraryUid)
+			},
+			Compensate: func() error {
+				serviceCtx, err := gh.serviceContext(compensateCtx, userName)
+				if err != nil {
+					return err
+				}
+
+				var revertErr error
+				for _, ticket := range itinerary.Tickets {
+					err := gh.TicketClient.RevertTicketStatus(serviceCtx, userName, ticket.TicketUID, models.TicketPaid)
+					if err != nil {
+						revertErr = err
+					}
+				}
+				return revertErr
+			},
+		})
+	for i, ticket := range itinerary.Tickets {
+		returnSaga.AddStep(saga.Step{
+			Name: "release seat",
+			Action: func() error {
+				return gh.FlightClient.ReleaseSeat(ctx, ticket.TicketUID)
+			},
+			Compensate: func() error {
+				return gh.FlightClient.ReserveSeat(compensateCtx, flights[i].ID, ticket.FareClass, ticket.TicketUID)
+			},
+		})
+	}
+	returnSaga.AddStep(saga.Step{
+		Name: "revert privilege history",
+		Action: func() error {
+			_, err := gh.BonusClient.RevertHistory(ctx, userName, itineraryUid)
+			return err
+		},
+	})
+
+	err = returnSaga.Run()
+	if err != nil {
+		gh.Logger.Errorw("can`t return itinerary", "err:", err.Error())
+		var stepErr *saga.StepError
+		if errors.As(err, &stepErr) && stepErr.Step == "cancel itinerary" && errors.Is(err, apiclient.ErrConflict) {
+			http.Error(w, "can`t return itinerary: ticket status has changed", http.StatusConflict)
+			return
+		}
+		writeUnavailable(w, err, "can`t return itinerary: return was rolled back")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,226 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"flight_booking_system/gatewayService/internal/saga"
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// documentVisibleChars — сколько последних символов документа видно при поиске без входа.
+const documentVisibleChars = 4
+
+func makePassengerInfo(passenger *models.PassengerResponse) *models.PassengerInfo {
+	info := &models.PassengerInfo{
+		FirstName:      passenger.FirstName,
+		LastName:       passenger.LastName,
+		DocumentNumber: passenger.DocumentNumber,
+	}
+	if passenger.BirthDate != nil {
+		info.BirthDate = passenger.BirthDate.Format(models.BirthDateLayout)
+	}
+
+	return info
+}
+
+// parsePassengers переводит пассажиров запроса в формат Ticket Service.
+// Остальные данные пассажиров проверяет Ticket Service.
+func parsePassengers(passengers []models.PassengerInfo) ([]models.PassengerResponse, error) {
+	if len(passengers) == 0 {
+		return nil, errors.New("passengers are required")
+	}
+
+	res := make([]models.PassengerResponse, len(passengers))
+	for i, passenger := range passengers {
+		birthDate, err := time.Parse(models.BirthDateLayout, passenger.BirthDate)
+		if err != nil {
+			return nil, errors.Errorf("passenger %d: birthDate must be in %s format", i+1, models.BirthDateLayout)
+		}
+
+		res[i] = models.PassengerResponse{
+			FirstName:      passenger.FirstName,
+			LastName:       passenger.LastName,
+			DocumentNumber: passenger.DocumentNumber,
+			BirthDate:      &birthDate,
+		}
+	}
+
+	return res, nil
+}
+
+// BuyPNR покупает билеты на один рейс для нескольких пассажиров под одним
+// кодом бронирования. Места резервируются на всех пассажиров сразу: если
+// хоть одно не удалось, бронирование не создаётся.
+func (gh *GatewayHandler) BuyPNR(w http.ResponseWriter, r *http.Request) {
+	userName := userNameFromRequest(r)
+	if userName == "" {
+		gh.Logger.Errorw("no authenticated user found")
+		http.Error(w, "no auth", http.StatusUnauthorized)
+		return
+	}
+
+	pnrRequest := models.PNRRequest{}
+	if !gh.readBody(w, r, &pnrRequest) {
+		return
+	}
+
+	passengers, err := parsePassengers(pnrRequest.Passengers)
+	if err != nil {
+		gh.Logger.Infow("invalid passengers in pnr request", "err:", err.Error())
+		http.Error(w, "bad data: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	compensateCtx := context.WithoutCancel(ctx)
+
+	p := newPurchase(userName, pnrRequest.FlightNumber, pnrRequest.FareClass, pnrRequest.Price, models.TicketPaid)
+
+	ticketUIDs := make([]string, len(passengers))
+	for i := range ticketUIDs {
+		ticketUIDs[i] = uuid.New().String()
+	}
+
+	var pnr *models.PNRServiceResponse
+	operations := make([]*models.PrivilegeOperationResponse, len(passengers))
+
+	pnrSaga := gh.addFareSteps(saga.New("BuyPNR", gh.Logger), ctx, p)
+	for _, ticketUID := range ticketUIDs {
+		pnrSaga.AddStep(saga.Step{
+			Name: "reserve seat",
+			Action: func() error {
+				return gh.FlightClient.ReserveSeat(ctx, p.flight.ID, p.fare.FareClass, ticketUID)
+			},
+			Compensate: func() error {
+				return gh.FlightClient.ReleaseSeat(compensateCtx, ticketUID)
+			},
+		})
+	}
+	pnrSaga.AddStep(saga.Step{
+		Name: "create booking",
+		Action: func() (err error) {
+			tickets := make([]models.PNRTicketRequest, len(passengers))
+			for i, passenger := range passengers {
+				tickets[i] = models.PNRTicketRequest{
+					UID:          ticketUIDs[i],
+					FlightNumber: p.flightNumber,
+					Price:        p.fare.Price,
+					FareClass:    p.fare.FareClass,
+					Passenger:    passenger,
+				}
+			}
+
+			pnr, err = gh.TicketClient.CreatePNR(ctx, userName, models.PNRCreateRequest{Tickets: tickets})
+			return err
+		},
+		Compensate: func() error {
+			return gh.TicketClient.CancelPNR(compensateCtx, userName, pnr.Reference)
+		},
+	})
+	// Бонусы считаются по каждому билету, как при покупке по одному
+	for i, ticketUID := range ticketUIDs {
+		pnrSaga.AddStep(gh.privilegeStep(ctx, compensateCtx, userName, ticketUID, pnrRequest.PaidFromBalance,
+			func() int { return p.fare.Price }, &operations[i]))
+	}
+
+	err = pnrSaga.Run()
+	if err != nil {
+		gh.Logger.Errorw("can`t buy pnr", "err:", err.Error())
+		var stepErr *saga.StepError
+		if errors.As(err, &stepErr) && stepErr.Step == "create booking" && errors.Is(err, apiclient.ErrBadRequest) {
+			http.Error(w, "can`t buy pnr: invalid passengers", http.StatusBadRequest)
+			return
+		}
+		gh.writeReservationError(w, err, p, "can`t buy pnr")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/pnr/"+pnr.Reference)
+	gh.writeJSON(w, http.StatusCreated, makePNRResponse(pnr, p.flight, operations))
+}
+
+func makePNRResponse(pnr *models.PNRServiceResponse, flight *models.FlightResponse, operations []*models.PrivilegeOperationResponse) models.PNRResponse {
+	flights := make([]*models.FlightResponse, len(pnr.Tickets))
+	for i := range flights {
+		flights[i] = flight
+	}
+
+	res := models.PNRResponse{
+		Reference: pnr.Reference,
+		Tickets:   makeTicketInfoResponse(pnr.Tickets, flights),
+	}
+
+	for _, ticket := range pnr.Tickets {
+		res.TotalPrice += ticket.Price
+	}
+	for _, operation := range operations {
+		if operation.OperationType == "DEBIT_THE_ACCOUNT" {
+			res.PaidByBonuses += operation.BalanceDiff
+		}
+	}
+	res.PaidByMoney = res.TotalPrice - res.PaidByBonuses
+
+	// Баланс и статус берутся из последней операции — она отражает итог покупки
+	if len(operations) > 0 {
+		last := operations[len(operations)-1]
+		res.Privilege = &models.PrivilegeInfo{
+			ID:      last.ID,
+			Balance: last.Balance,
+			Status:  last.Status,
+		}
+	}
+
+	return res
+}
+
+// GetPNR ищет бронирование по коду и фамилии любого из пассажиров без входа.
+// Номера документов в ответе скрыты, а даты рождения не показываются.
+func (gh *GatewayHandler) GetPNR(w http.ResponseWriter, r *http.Request) {
+	lastName := r.URL.Query().Get("lastName")
+	if strings.TrimSpace(lastName) == "" {
+		gh.Logger.Infow("no last name in pnr lookup")
+		http.Error(w, "bad data: lastName is required", http.StatusBadRequest)
+		return
+	}
+
+	pnr, err := gh.TicketClient.GetPNR(r.Context(), r.PathValue("reference"), lastName)
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get pnr")
+		return
+	}
+
+	flights, err := gh.getFlightsForTickets(r.Context(), pnr.Tickets)
+	if err != nil {
+		gh.writeClientError(w, err, "can`t get flights")
+		return
+	}
+
+	res := models.PNRInfo{
+		Reference: pnr.Reference,
+		CreatedAt: pnr.CreatedAt,
+		Tickets:   makeTicketInfoResponse(pnr.Tickets, flights),
+	}
+	for _, ticket := range res.Tickets {
+		if ticket.Passenger != nil {
+			ticket.Passenger.DocumentNumber = maskDocument(ticket.Passenger.DocumentNumber)
+			ticket.Passenger.BirthDate = ""
+		}
+	}
+
+	gh.writeJSON(w, http.StatusOK, res)
+}
+
+func maskDocument(document string) string {
+	runes := []rune(document)
+	if len(runes) <= documentVisibleChars {
+		return strings.Repeat("*", len(runes))
+	}
+
+	return strings.Repeat("*", len(runes)-documentVisibleChars) + string(runes[len(runes)-documentVisibleChars:])
+}
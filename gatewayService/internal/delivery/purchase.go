@@ -0,0 +1,212 @@
+package delivery
+
+import (
+	"context"
+	"net/http"
+
+	"flight_booking_system/gatewayService/internal/saga"
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var (
+	// errFareNotSold — на рейсе нет запрошенного класса обслуживания.
+	errFareNotSold = errors.New("fare class is not sold on this flight")
+	// errPriceChanged — цена, которую видел клиент, не совпадает с текущим тарифом.
+	errPriceChanged = errors.New("price has changed")
+	// errFlightClosed — рейс отменён или уже вылетел.
+	errFlightClosed = errors.New("flight is cancelled or departed")
+)
+
+// findFare выбирает класс обслуживания рейса; пустой класс — эконом.
+func findFare(fares []*models.FareInfo, fareClass string) (*models.FareInfo, error) {
+	if fareClass == "" {
+		fareClass = models.FareEconomy
+	}
+
+	for _, fare := range fares {
+		if fare.FareClass == fareClass {
+			return fare, nil
+		}
+	}
+
+	return nil, errors.Wrap(errFareNotSold, fareClass)
+}
+
+// purchase — состояние покупки или брони, которое шаги саги заполняют по ходу.
+type purchase struct {
+	userName     string
+	flightNumber string
+	fareClass    string
+	quotedPrice  int
+	// status — в каком статусе создаётся билет: PAID или HELD
+	status    string
+	ticketUID string
+
+	flight *models.FlightResponse
+	fare   *models.FareInfo
+	ticket *models.TicketResponse
+}
+
+func newPurchase(userName, flightNumber, fareClass string, quotedPrice int, status string) *purchase {
+	if fareClass == "" {
+		fareClass = models.FareEconomy
+	}
+
+	return &purchase{
+		userName:     userName,
+		flightNumber: flightNumber,
+		fareClass:    fareClass,
+		quotedPrice:  quotedPrice,
+		status:       status,
+		// UID выдаётся заранее: под него резервируется место до создания билета
+		ticketUID: uuid.New().String(),
+	}
+}
+
+// addFareSteps добавляет шаги получения рейса и тарифа с проверкой цены.
+func (gh *GatewayHandler) addFareSteps(s *saga.Saga, ctx context.Context, p *purchase) *saga.Saga {
+	return s.
+		AddStep(saga.Step{
+			Name: "get flight",
+			Action: func() (err error) {
+				p.flight, err = gh.FlightClient.GetFlightByNumber(ctx, p.flightNumber)
+				if err != nil {
+					return err
+				}
+				if p.flight.Closed() {
+					return errors.Wrapf(errFlightClosed, "%s is %s", p.flightNumber, p.flight.Status)
+				}
+				return nil
+			},
+		}).
+		AddStep(saga.Step{
+			Name: "get fare",
+			Action: func() error {
+				fares, err := gh.FlightClient.GetFares(ctx, p.flight.ID)
+				if err != nil {
+					return err
+				}
+				p.fare, err = findFare(fares, p.fareClass)
+				if err != nil {
+					return err
+				}
+				// Цена билета всегда берётся из тарифа Flight Service; цена клиента
+				// нужна только чтобы не продать дороже, чем он видел
+				if p.quotedPrice != 0 && p.quotedPrice != p.fare.Price {
+					return errors.Wrapf(errPriceChanged, "quoted %d, current %d", p.quotedPrice, p.fare.Price)
+				}
+				return nil
+			},
+		})
+}
+
+// addReservationSteps добавляет шаги, общие для покупки и брони: рейс, тариф
+// с проверкой цены, место на рейсе и билет в статусе p.status.
+func (gh *GatewayHandler) addReservationSteps(s *saga.Saga, ctx, compensateCtx context.Context, p *purchase) *saga.Saga {
+	return gh.addFareSteps(s, ctx, p).
+		AddStep(saga.Step{
+			Name: "reserve seat",
+			Action: func() error {
+				return gh.FlightClient.ReserveSeat(ctx, p.flight.ID, p.fare.FareClass, p.ticketUID)
+			},
+			Compensate: func() error {
+				return gh.FlightClient.ReleaseSeat(compensateCtx, p.ticketUID)
+			},
+		}).
+		AddStep(saga.Step{
+			Name: "create ticket",
+			Action: func() (err error) {
+				p.ticket, err = gh.TicketClient.CreateTicket(ctx, models.TicketInfoRequest{
+					UID:          p.ticketUID,
+					FlightNumber: p.flightNumber,
+					Username:     p.userName,
+					Price:        p.fare.Price,
+					Status:       p.status,
+					FareClass:    p.fare.FareClass,
+				})
+				return err
+			},
+			Compensate: func() error {
+				return gh.TicketClient.CancelTicket(compensateCtx, p.userName, p.ticketUID)
+			},
+		})
+}
+
+// privilegeStep списывает или начисляет бонусы за билет; сумму операции
+// считает Bonus Service от цены.
+func (gh *GatewayHandler) privilegeStep(ctx, compensateCtx context.Context, userName, ticketUID string, paidFromBalance bool, price func() int, res **models.PrivilegeOperationResponse) saga.Step {
+	operationType := "FILL_IN_BALANCE"
+	if paidFromBalance {
+		operationType = "DEBIT_THE_ACCOUNT"
+	}
+
+	return saga.Step{
+		Name: "apply privilege operation",
+		Action: func() (err error) {
+			*res, err = gh.BonusClient.ApplyOperation(ctx, models.PrivilegeOperationRequest{
+				Username:      userName,
+				TicketUID:     ticketUID,
+				OperationType: operationType,
+				Amount:        price(),
+			})
+			return err
+		},
+		Compensate: func() error {
+			_, err := gh.BonusClient.RevertHistory(compensateCtx, userName, ticketUID)
+			return err
+		},
+	}
+}
+
+// writeReservationError отвечает клиенту на ошибку шагов addReservationSteps.
+func (gh *GatewayHandler) writeReservationError(w http.ResponseWriter, err error, p *purchase, msg string) {
+	var stepErr *saga.StepError
+	switch {
+	case errors.As(err, &stepErr) && stepErr.Step == "get flight" && errors.Is(err, apiclient.ErrNotFound):
+		http.Error(w, msg+": flight not found", http.StatusBadRequest)
+	case errors.Is(err, errFareNotSold):
+		http.Error(w, msg+": "+errFareNotSold.Error(), http.StatusBadRequest)
+	case errors.Is(err, errFlightClosed):
+		http.Error(w, msg+": "+errFlightClosed.Error(), http.StatusConflict)
+	case errors.Is(err, errPriceChanged):
+		gh.writeJSON(w, http.StatusConflict, models.PriceChangedResponse{
+			Message:      msg + ": " + errPriceChanged.Error(),
+			FlightNumber: p.flightNumber,
+			FareClass:    p.fare.FareClass,
+			QuotedPrice:  p.quotedPrice,
+			Price:        p.fare.Price,
+		})
+	case errors.As(err, &stepErr) && stepErr.Step == "reserve seat" && errors.Is(err, apiclient.ErrConflict):
+		http.Error(w, msg+": flight is sold out", http.StatusConflict)
+	default:
+		writeUnavailable(w, err, msg+": operation was rolled back")
+	}
+}
+
+func makeBuyTicketResponse(ticket *models.TicketResponse, flight *models.FlightResponse, operation *models.PrivilegeOperationResponse) models.BuyTicketResponse {
+	res := models.BuyTicketResponse{
+		UID:          ticket.TicketUID,
+		FlightNumber: ticket.FlightNumber,
+		FromAirport:  flight.FromAirport,
+		ToAirport:    flight.ToAirport,
+		Date:         flight.Date,
+		Price:        ticket.Price,
+		FareClass:    ticket.FareClass,
+		Status:       models.TicketPaid,
+		Privilege: models.PrivilegeResponse{
+			ID:      operation.ID,
+			Balance: operation.Balance,
+			Status:  operation.Status,
+		},
+	}
+
+	if operation.OperationType == "DEBIT_THE_ACCOUNT" {
+		res.PaidByBonuses = operation.BalanceDiff
+	}
+	res.PaidByMoney = ticket.Price - res.PaidByBonuses
+
+	return res
+}
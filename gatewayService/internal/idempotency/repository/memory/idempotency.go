@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"flight_booking_system/gatewayService/internal/idempotency/repository"
+	"flight_booking_system/gatewayService/models"
+	"github.com/pkg/errors"
+)
+
+type memIdempotencyRepo struct {
+	mu             sync.Mutex
+	records        map[string]*models.IdempotencyRecord
+	PendingTimeout time.Duration
+}
+
+// New создаёт хранилище ключей в памяти процесса. Ключи теряются при
+// перезапуске и не видны другим экземплярам gateway.
+func New(pendingTimeout time.Duration) repository.IdempotencyRepositoryI {
+	return &memIdempotencyRepo{
+		records:        make(map[string]*models.IdempotencyRecord),
+		PendingTimeout: pendingTimeout,
+	}
+}
+
+func (mr *memIdempotencyRepo) Begin(key string, fingerprint string, ttl time.Duration) (*models.IdempotencyRecord, bool, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	now := time.Now()
+
+	if record, ok := mr.records[key]; ok && record.ExpiresAt.After(now) &&
+		(record.Completed() || record.CreatedAt.After(now.Add(-mr.PendingTimeout))) {
+		res := *record
+		return &res, false, nil
+	}
+
+	record := &models.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+	mr.records[key] = record
+
+	res := *record
+	return &res, true, nil
+}
+
+// owned возвращает запись ключа, если её создал тот же Begin и ответ ещё не
+// сохранён.
+func (mr *memIdempotencyRepo) owned(record *models.IdempotencyRecord) (*models.IdempotencyRecord, bool) {
+	stored, ok := mr.records[record.Key]
+	if !ok || !stored.CreatedAt.Equal(record.CreatedAt) || stored.Completed() {
+		return nil, false
+	}
+
+	return stored, true
+}
+
+func (mr *memIdempotencyRepo) Complete(record *models.IdempotencyRecord, statusCode int, contentType string, response []byte) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	stored, ok := mr.owned(record)
+	if !ok {
+		return errors.Wrap(repository.ErrKeyReclaimed, "memIdempotencyRepo.Complete error")
+	}
+
+	stored.StatusCode = statusCode
+	stored.ContentType = contentType
+	stored.Response = response
+
+	return nil
+}
+
+func (mr *memIdempotencyRepo) Release(record *models.IdempotencyRecord) error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if _, ok := mr.owned(record); !ok {
+		return errors.Wrap(repository.ErrKeyReclaimed, "memIdempotencyRepo.Release error")
+	}
+
+	delete(mr.records, record.Key)
+
+	return nil
+}
+
+func (mr *memIdempotencyRepo) DeleteExpired() error {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	now := time.Now()
+	for key, record := range mr.records {
+		if !record.ExpiresAt.After(now) {
+			delete(mr.records, key)
+		}
+	}
+
+	return nil
+}
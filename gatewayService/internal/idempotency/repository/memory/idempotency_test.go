@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"flight_booking_system/gatewayService/internal/idempotency/repository"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+)
+
+type IdempotencyRepoTestSuite struct {
+	suite.Suite
+	repo repository.IdempotencyRepositoryI
+}
+
+func TestIdempotencyRepoSuite(t *testing.T) {
+	suite.RunSuite(t, new(IdempotencyRepoTestSuite))
+}
+
+func (s *IdempotencyRepoTestSuite) BeforeEach(t provider.T) {
+	s.repo = New(time.Minute)
+}
+
+func (s *IdempotencyRepoTestSuite) TestBegin(t provider.T) {
+	record, created, err := s.repo.Begin("key", "fingerprint", time.Hour)
+	t.Require().NoError(err)
+	t.Assert().True(created)
+	t.Assert().Equal("fingerprint", record.Fingerprint)
+	t.Assert().False(record.Completed())
+
+	existing, created, err := s.repo.Begin("key", "other", time.Hour)
+	t.Require().NoError(err)
+	t.Assert().False(created)
+	t.Assert().Equal("fingerprint", existing.Fingerprint)
+	t.Assert().False(existing.Completed())
+}
+
+func (s *IdempotencyRepoTestSuite) TestComplete(t provider.T) {
+	record, _, err := s.repo.Begin("key", "fingerprint", time.Hour)
+	t.Require().NoError(err)
+
+	err = s.repo.Complete(record, 200, "application/json", []byte(`{}`))
+	t.Require().NoError(err)
+
+	existing, created, err := s.repo.Begin("key", "fingerprint", time.Hour)
+	t.Require().NoError(err)
+	t.Assert().False(created)
+	t.Assert().True(existing.Completed())
+	t.Assert().Equal(200, existing.StatusCode)
+	t.Assert().Equal("application/json", existing.ContentType)
+	t.Assert().Equal([]byte(`{}`), existing.Response)
+
+	err = s.repo.Complete(record, 201, "", nil)
+	t.Assert().ErrorIs(err, repository.ErrKeyReclaimed)
+}
+
+func (s *IdempotencyRepoTestSuite) TestRelease(t provider.T) {
+	record, _, err := s.repo.Begin("key", "fingerprint", time.Hour)
+	t.Require().NoError(err)
+
+	err = s.repo.Release(record)
+	t.Require().NoError(err)
+
+	_, created, err := s.repo.Begin("key", "fingerprint", time.Hour)
+	t.Require().NoError(err)
+	t.Assert().True(created)
+}
+
+func (s *IdempotencyRepoTestSuite) TestBeginExpired(t provider.T) {
+	record, _, err := s.repo.Begin("key", "fingerprint", 0)
+	t.Require().NoError(err)
+	t.Require().NoError(s.repo.Complete(record, 200, "", nil))
+
+	record, created, err := s.repo.Begin("key", "other", time.Hour)
+	t.Require().NoError(err)
+	t.Assert().True(created)
+	t.Assert().Equal("other", record.Fingerprint)
+}
+
+func (s *IdempotencyRepoTestSuite) TestReclaimedPending(t provider.T) {
+	// Ключ без ответа сразу считается оборвавшимся
+	s.repo = New(0)
+
+	slow, _, err := s.repo.Begin("key", "fingerprint", time.Hour)
+	t.Require().NoError(err)
+
+	retry, created, err := s.repo.Begin("key", "fingerprint", time.Hour)
+	t.Require().NoError(err)
+	t.Require().True(created)
+
+	err = s.repo.Complete(slow, 200, "", []byte("slow"))
+	t.Assert().ErrorIs(err, repository.ErrKeyReclaimed)
+	err = s.repo.Release(slow)
+	t.Assert().ErrorIs(err, repository.ErrKeyReclaimed)
+
+	t.Require().NoError(s.repo.Complete(retry, 201, "", []byte("retry")))
+
+	existing, created, err := s.repo.Begin("key", "fingerprint", time.Hour)
+	t.Require().NoError(err)
+	t.Assert().False(created)
+	t.Assert().Equal(201, existing.StatusCode)
+	t.Assert().Equal([]byte("retry"), existing.Response)
+}
+
+func (s *IdempotencyRepoTestSuite) TestDeleteExpired(t provider.T) {
+	expired, _, err := s.repo.Begin("expired", "fingerprint", 0)
+	t.Require().NoError(err)
+	active, _, err := s.repo.Begin("active", "fingerprint", time.Hour)
+	t.Require().NoError(err)
+
+	t.Require().NoError(s.repo.DeleteExpired())
+
+	t.Assert().ErrorIs(s.repo.Release(expired), repository.ErrKeyReclaimed)
+	t.Assert().NoError(s.repo.Release(active))
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"flight_booking_system/gatewayService/internal/idempotency/repository"
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+type pgIdempotencyRepo struct {
+	Logger         logger.Logger
+	DB             *sql.DB
+	PendingTimeout time.Duration
+}
+
+// New создаёт хранилище ключей. Запрос, ответ на который не сохранён за
+// pendingTimeout, считается оборвавшимся, и его ключ можно занять заново.
+func New(logger logger.Logger, db *sql.DB, pendingTimeout time.Duration) repository.IdempotencyRepositoryI {
+	return &pgIdempotencyRepo{
+		Logger:         logger,
+		DB:             db,
+		PendingTimeout: pendingTimeout,
+	}
+}
+
+// Истёкшая или зависшая без ответа запись перезаписывается новой, остальные
+// остаются как есть, и тогда RETURNING не возвращает строк.
+const beginQuery = `
+INSERT INTO idempotency_key (key, fingerprint, status_code, content_type, response, created_at, expires_at)
+VALUES ($1, $2, 0, '', NULL, $3, $4)
+ON CONFLICT (key) DO UPDATE
+    SET fingerprint  = EXCLUDED.fingerprint,
+        status_code  = 0,
+        content_type = '',
+        response     = NULL,
+        created_at   = EXCLUDED.created_at,
+        expires_at   = EXCLUDED.expires_at
+    WHERE idempotency_key.expires_at <= EXCLUDED.created_at
+       OR (idempotency_key.status_code = 0 AND idempotency_key.created_at <= $5)
+RETURNING key`
+
+func (pr *pgIdempotencyRepo) Begin(key string, fingerprint string, ttl time.Duration) (*models.IdempotencyRecord, bool, error) {
+	// Postgres хранит время с точностью до микросекунд, а по created_at
+	// Complete и Release узнают свою запись
+	now := time.Now().Truncate(time.Microsecond)
+	record := &models.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	var insertedKey string
+	err := pr.DB.QueryRow(beginQuery, key, fingerprint, record.CreatedAt, record.ExpiresAt, now.Add(-pr.PendingTimeout)).Scan(&insertedKey)
+	if err == nil {
+		return record, true, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, false, errors.Wrap(err, "pgIdempotencyRepo.Begin error while inserting in repo")
+	}
+
+	existing := &models.IdempotencyRecord{}
+	err = pr.DB.QueryRow(
+		`SELECT key, fingerprint, status_code, content_type, response, created_at, expires_at FROM idempotency_key WHERE key = $1`, key).
+		Scan(&existing.Key, &existing.Fingerprint, &existing.StatusCode, &existing.ContentType, &existing.Response, &existing.CreatedAt, &existing.ExpiresAt)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "pgIdempotencyRepo.Begin error")
+	}
+
+	return existing, false, nil
+}
+
+func (pr *pgIdempotencyRepo) Complete(record *models.IdempotencyRecord, statusCode int, contentType string, response []byte) error {
+	res, err := pr.DB.Exec(
+		`UPDATE idempotency_key SET status_code = $1, content_type = $2, response = $3 WHERE key = $4 AND created_at = $5 AND status_code = 0`,
+		statusCode, contentType, response, record.Key, record.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, "pgIdempotencyRepo.Complete error")
+	}
+
+	return checkOwned(res, "pgIdempotencyRepo.Complete error")
+}
+
+func (pr *pgIdempotencyRepo) Release(record *models.IdempotencyRecord) error {
+	res, err := pr.DB.Exec(
+		`DELETE FROM idempotency_key WHERE key = $1 AND created_at = $2 AND status_code = 0`,
+		record.Key, record.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, "pgIdempotencyRepo.Release error")
+	}
+
+	return checkOwned(res, "pgIdempotencyRepo.Release error")
+}
+
+// checkOwned возвращает ErrKeyReclaimed, если запись запроса уже заменена
+// другой.
+func checkOwned(res sql.Result, msg string) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, msg)
+	}
+
+	if affected == 0 {
+		return errors.Wrap(repository.ErrKeyReclaimed, msg)
+	}
+
+	return nil
+}
+
+func (pr *pgIdempotencyRepo) DeleteExpired() error {
+	_, err := pr.DB.Exec(`DELETE FROM idempotency_key WHERE expires_at <= $1`, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "pgIdempotencyRepo.DeleteExpired error")
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"flight_booking_system/gatewayService/internal/idempotency/repository"
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/logger"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+)
+
+const testPendingTimeout = time.Minute
+
+type IdempotencyRepoTestSuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo repository.IdempotencyRepositoryI
+}
+
+func TestIdempotencyRepoSuite(t *testing.T) {
+	suite.RunSuite(t, new(IdempotencyRepoTestSuite))
+}
+
+func (s *IdempotencyRepoTestSuite) BeforeEach(t provider.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("error while creating sql mock")
+	}
+
+	var logger logger.Logger
+
+	s.db = db
+	s.mock = mock
+	s.repo = New(logger, db, testPendingTimeout)
+}
+
+func (s *IdempotencyRepoTestSuite) AfterEach(t provider.T) {
+	err := s.mock.ExpectationsWereMet()
+	t.Assert().NoError(err)
+	s.db.Close()
+}
+
+// pendingCutoff проверяет, что ключ без ответа можно занять, только если он
+// старше testPendingTimeout.
+type pendingCutoff struct{}
+
+func (pendingCutoff) Match(v driver.Value) bool {
+	cutoff, ok := v.(time.Time)
+	if !ok {
+		return false
+	}
+
+	expected := time.Now().Add(-testPendingTimeout)
+	return cutoff.After(expected.Add(-time.Second)) && !cutoff.After(expected)
+}
+
+func (s *IdempotencyRepoTestSuite) TestBegin(t provider.T) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(beginQuery)).
+		WithArgs("key", "fingerprint", sqlmock.AnyArg(), sqlmock.AnyArg(), pendingCutoff{}).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("key"))
+
+	record, created, err := s.repo.Begin("key", "fingerprint", time.Hour)
+	t.Assert().NoError(err)
+	t.Assert().True(created)
+	t.Assert().Equal("fingerprint", record.Fingerprint)
+	t.Assert().False(record.Completed())
+}
+
+func (s *IdempotencyRepoTestSuite) TestBeginInProgress(t provider.T) {
+	createdAt := time.Now().Add(-time.Second)
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(beginQuery)).
+		WithArgs("key", "fingerprint", sqlmock.AnyArg(), sqlmock.AnyArg(), pendingCutoff{}).
+		WillReturnRows(sqlmock.NewRows([]string{"key"}))
+
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT key, fingerprint, status_code, content_type, response, created_at, expires_at FROM idempotency_key WHERE key = $1`)).
+		WithArgs("key").
+		WillReturnRows(sqlmock.NewRows([]string{"key", "fingerprint", "status_code", "content_type", "response", "created_at", "expires_at"}).
+			AddRow("key", "fingerprint", 0, "", nil, createdAt, createdAt.Add(time.Hour)))
+
+	record, created, err := s.repo.Begin("key", "fingerprint", time.Hour)
+	t.Assert().NoError(err)
+	t.Assert().False(created)
+	t.Assert().False(record.Completed())
+}
+
+func (s *IdempotencyRepoTestSuite) TestComplete(t provider.T) {
+	record := &models.IdempotencyRecord{Key: "key", CreatedAt: time.Now().Truncate(time.Microsecond)}
+	query := regexp.QuoteMeta(
+		`UPDATE idempotency_key SET status_code = $1, content_type = $2, response = $3 WHERE key = $4 AND created_at = $5 AND status_code = 0`)
+
+	cases := map[string]struct {
+		Affected int64
+		Error    error
+	}{
+		"success": {
+			Affected: 1,
+			Error:    nil,
+		},
+		"reclaimed": {
+			Affected: 0,
+			Error:    repository.ErrKeyReclaimed,
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			s.mock.ExpectExec(query).
+				WithArgs(200, "application/json", []byte(`{}`), "key", record.CreatedAt).
+				WillReturnResult(sqlmock.NewResult(0, test.Affected))
+
+			err := s.repo.Complete(record, 200, "application/json", []byte(`{}`))
+			t.Assert().ErrorIs(err, test.Error)
+		})
+	}
+}
+
+func (s *IdempotencyRepoTestSuite) TestRelease(t provider.T) {
+	record := &models.IdempotencyRecord{Key: "key", CreatedAt: time.Now().Truncate(time.Microsecond)}
+	query := regexp.QuoteMeta(`DELETE FROM idempotency_key WHERE key = $1 AND created_at = $2 AND status_code = 0`)
+
+	cases := map[string]struct {
+		Affected int64
+		Error    error
+	}{
+		"success": {
+			Affected: 1,
+			Error:    nil,
+		},
+		"reclaimed": {
+			Affected: 0,
+			Error:    repository.ErrKeyReclaimed,
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			s.mock.ExpectExec(query).
+				WithArgs("key", record.CreatedAt).
+				WillReturnResult(sqlmock.NewResult(0, test.Affected))
+
+			err := s.repo.Release(record)
+			t.Assert().ErrorIs(err, test.Error)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"time"
+
+	"flight_booking_system/gatewayService/models"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrKeyReclaimed — ключ, занятый запросом, за время обработки занял
+	// другой запрос: ответ первого больше не сохраняется.
+	ErrKeyReclaimed = errors.New("idempotency key was reclaimed by another request")
+)
+
+type IdempotencyRepositoryI interface {
+	// Begin атомарно резервирует ключ. Если неистёкшая запись уже есть,
+	// возвращается она и created == false.
+	Begin(key string, fingerprint string, ttl time.Duration) (record *models.IdempotencyRecord, created bool, err error)
+	// Complete и Release меняют только запись, созданную тем же Begin.
+	Complete(record *models.IdempotencyRecord, statusCode int, contentType string, response []byte) error
+	Release(record *models.IdempotencyRecord) error
+	DeleteExpired() error
+}
@@ -0,0 +1,88 @@
+package saga
+
+import (
+	"fmt"
+
+	"flight_booking_system/gatewayService/pkg/logger"
+)
+
+type Step struct {
+	Name       string
+	Action     func() error
+	Compensate func() error
+}
+
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("saga step %q failed: %s", e.Step, e.Err.Error())
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+type Saga struct {
+	Name   string
+	Logger logger.Logger
+	steps  []Step
+}
+
+func New(name string, logger logger.Logger) *Saga {
+	return &Saga{
+		Name:   name,
+		Logger: logger,
+	}
+}
+
+func (s *Saga) AddStep(step Step) *Saga {
+	s.steps = append(s.steps, step)
+	return s
+}
+
+// Run выполняет шаги по порядку. Если шаг падает, для всех уже выполненных
+// шагов в обратном порядке вызываются компенсирующие действия.
+func (s *Saga) Run() error {
+	for i, step := range s.steps {
+		err := step.Action()
+		if err == nil {
+			continue
+		}
+
+		s.Logger.Errorw("saga step failed",
+			"saga", s.Name,
+			"step", step.Name,
+			"err:", err.Error())
+
+		s.compensate(i - 1)
+
+		return &StepError{Step: step.Name, Err: err}
+	}
+
+	return nil
+}
+
+func (s *Saga) compensate(from int) {
+	for i := from; i >= 0; i-- {
+		step := s.steps[i]
+		if step.Compensate == nil {
+			continue
+		}
+
+		err := step.Compensate()
+		if err != nil {
+			s.Logger.Errorw("saga compensation failed",
+				"saga", s.Name,
+				"step", step.Name,
+				"err:", err.Error())
+			continue
+		}
+
+		s.Logger.Infow("saga step compensated",
+			"saga", s.Name,
+			"step", step.Name)
+	}
+}
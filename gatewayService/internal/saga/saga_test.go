@@ -0,0 +1,124 @@
+package saga
+
+import (
+	"testing"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type SagaTestSuite struct {
+	suite.Suite
+	saga  *Saga
+	logs  *observer.ObservedLogs
+	calls []string
+}
+
+func TestSagaTestSuite(t *testing.T) {
+	suite.RunSuite(t, new(SagaTestSuite))
+}
+
+func (s *SagaTestSuite) BeforeEach(t provider.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	s.logs = logs
+	s.saga = New("test", zap.New(core).Sugar())
+	s.calls = nil
+}
+
+// step — шаг, который записывает свои вызовы в s.calls и возвращает заданные
+// ошибки.
+func (s *SagaTestSuite) step(name string, actionErr, compensateErr error) Step {
+	return Step{
+		Name: name,
+		Action: func() error {
+			s.calls = append(s.calls, "action "+name)
+			return actionErr
+		},
+		Compensate: func() error {
+			s.calls = append(s.calls, "compensate "+name)
+			return compensateErr
+		},
+	}
+}
+
+func (s *SagaTestSuite) TestRunSuccess(t provider.T) {
+	err := s.saga.
+		AddStep(s.step("first", nil, nil)).
+		AddStep(s.step("second", nil, nil)).
+		AddStep(s.step("third", nil, nil)).
+		Run()
+
+	t.Assert().NoError(err)
+	t.Assert().Equal([]string{"action first", "action second", "action third"}, s.calls)
+}
+
+func (s *SagaTestSuite) TestRunStepFailed(t provider.T) {
+	stepErr := errors.New("step failed")
+
+	err := s.saga.
+		AddStep(s.step("first", nil, nil)).
+		AddStep(s.step("second", nil, nil)).
+		AddStep(s.step("third", stepErr, nil)).
+		AddStep(s.step("fourth", nil, nil)).
+		Run()
+
+	var sagaErr *StepError
+	t.Require().ErrorAs(err, &sagaErr)
+	t.Assert().Equal("third", sagaErr.Step)
+	t.Assert().ErrorIs(err, stepErr)
+	t.Assert().Equal([]string{
+		"action first",
+		"action second",
+		"action third",
+		"compensate second",
+		"compensate first",
+	}, s.calls)
+}
+
+func (s *SagaTestSuite) TestRunFirstStepFailed(t provider.T) {
+	stepErr := errors.New("step failed")
+
+	err := s.saga.
+		AddStep(s.step("first", stepErr, nil)).
+		AddStep(s.step("second", nil, nil)).
+		Run()
+
+	t.Assert().ErrorIs(err, stepErr)
+	t.Assert().Equal([]string{"action first"}, s.calls)
+}
+
+func (s *SagaTestSuite) TestRunCompensationFailed(t provider.T) {
+	stepErr := errors.New("step failed")
+	compensateErr := errors.New("compensation failed")
+
+	err := s.saga.
+		AddStep(s.step("first", nil, nil)).
+		AddStep(s.step("second", nil, compensateErr)).
+		AddStep(Step{
+			Name: "no compensation",
+			Action: func() error {
+				s.calls = append(s.calls, "action no compensation")
+				return nil
+			},
+		}).
+		AddStep(s.step("fourth", stepErr, nil)).
+		Run()
+
+	t.Assert().ErrorIs(err, stepErr)
+	t.Assert().Equal([]string{
+		"action first",
+		"action second",
+		"action no compensation",
+		"action fourth",
+		"compensate second",
+		"compensate first",
+	}, s.calls)
+
+	failed := s.logs.FilterMessage("saga compensation failed").All()
+	t.Require().Len(failed, 1)
+	t.Assert().Equal("second", failed[0].ContextMap()["step"])
+	t.Assert().Equal(1, s.logs.FilterMessage("saga step compensated").Len())
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"database/sql"
+
+	"flight_booking_system/gatewayService/internal/user/repository"
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+type pgUserRepo struct {
+	Logger logger.Logger
+	DB     *sql.DB
+}
+
+func New(logger logger.Logger, db *sql.DB) repository.UserRepositoryI {
+	return &pgUserRepo{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (pr *pgUserRepo) getBy(column string, value any) (*models.User, error) {
+	user := &models.User{}
+	err := pr.DB.QueryRow(
+		`SELECT id, username, password_hash, role FROM users WHERE `+column+` = $1`, value).
+		Scan(&user.ID, &user.Username, &user.PasswordHash, &user.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (pr *pgUserRepo) GetByID(id int) (*models.User, error) {
+	user, err := pr.getBy("id", id)
+	if err != nil {
+		return nil, errors.Wrap(err, "pgUserRepo.GetByID error")
+	}
+
+	return user, nil
+}
+
+func (pr *pgUserRepo) GetByUsername(username string) (*models.User, error) {
+	user, err := pr.getBy("username", username)
+	if err != nil {
+		return nil, errors.Wrap(err, "pgUserRepo.GetByUsername error")
+	}
+
+	return user, nil
+}
+
+// Create добавляет пользователя и заполняет его ID. Если имя уже занято,
+// возвращается ErrUserExists.
+func (pr *pgUserRepo) Create(user *models.User) error {
+	err := pr.DB.QueryRow(
+		`INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3)
+		ON CONFLICT (username) DO NOTHING RETURNING id`,
+		user.Username, user.PasswordHash, user.Role).
+		Scan(&user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.Wrap(repository.ErrUserExists, "pgUserRepo.Create error")
+	}
+	if err != nil {
+		return errors.Wrap(err, "pgUserRepo.Create error")
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"flight_booking_system/gatewayService/models"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("user already exists")
+)
+
+type UserRepositoryI interface {
+	GetByID(id int) (*models.User, error)
+	GetByUsername(username string) (*models.User, error)
+	Create(user *models.User) error
+}
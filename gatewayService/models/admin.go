@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+type AirportRequest struct {
+	Name    string `json:"name"`
+	City    string `json:"city"`
+	Country string `json:"country"`
+}
+
+type AirportResponse struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	City    string `json:"city"`
+	Country string `json:"country"`
+}
+
+type AdminFlightRequest struct {
+	FlightNumber  string    `json:"flightNumber"`
+	DateTime      time.Time `json:"dateTime"`
+	FromAirportID int       `json:"fromAirportId"`
+	ToAirportID   int       `json:"toAirportId"`
+	Price         int       `json:"price"`
+	Capacity      int       `json:"capacity"`
+	// DurationMinutes — время в пути, обязательно при создании рейса
+	DurationMinutes int `json:"durationMinutes"`
+}
+
+type AdminFlightResponse struct {
+	ID              int       `json:"id"`
+	FlightNumber    string    `json:"flightNumber"`
+	DateTime        time.Time `json:"dateTime"`
+	FromAirportID   int       `json:"fromAirportId"`
+	ToAirportID     int       `json:"toAirportId"`
+	Price           int       `json:"price"`
+	Capacity        int       `json:"capacity"`
+	SeatsReserved   int       `json:"seatsReserved"`
+	DurationMinutes int       `json:"durationMinutes"`
+	// Status, расчётное время и выход меняются через AdminFlightStatusRequest
+	Status             string     `json:"status,omitempty"`
+	EstimatedDeparture *time.Time `json:"estimatedDeparture,omitempty"`
+	EstimatedArrival   *time.Time `json:"estimatedArrival,omitempty"`
+	Gate               string     `json:"gate,omitempty"`
+}
+
+// AdminFlightStatusRequest — новый оперативный статус рейса. Пустые расчётное
+// время и выход оставляют прежние значения.
+type AdminFlightStatusRequest struct {
+	Status             string     `json:"status"`
+	EstimatedDeparture *time.Time `json:"estimatedDeparture,omitempty"`
+	EstimatedArrival   *time.Time `json:"estimatedArrival,omitempty"`
+	Gate               string     `json:"gate,omitempty"`
+}
+
+// AdminFlightStatusResponse — рейс после смены статуса. При отмене рейса
+// перечислены отменённые билеты и те, которые отменить не удалось: повторный
+// запрос с CANCELLED обработает их ещё раз.
+type AdminFlightStatusResponse struct {
+	AdminFlightResponse
+	CancelledTickets []string `json:"cancelledTickets,omitempty"`
+	FailedTickets    []string `json:"failedTickets,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type BookingRequest struct {
+	FlightNumber string `json:"flightNumber"`
+	// Price — цена, которую видел клиент; 0 — бронировать по текущему тарифу
+	Price     int    `json:"price"`
+	FareClass string `json:"fareClass"`
+}
+
+type BookingResponse struct {
+	UID           string    `json:"ticketUid"`
+	FlightNumber  string    `json:"flightNumber"`
+	FromAirport   string    `json:"fromAirport"`
+	ToAirport     string    `json:"toAirport"`
+	Date          time.Time `json:"date"`
+	Price         int       `json:"price"`
+	FareClass     string    `json:"fareClass"`
+	Status        string    `json:"status"`
+	HoldExpiresAt time.Time `json:"holdExpiresAt"`
+}
+
+type ConfirmBookingRequest struct {
+	PaidFromBalance bool `json:"paidFromBalance"`
+}
@@ -0,0 +1,24 @@
+package models
+
+type ExchangeTicketRequest struct {
+	FlightNumber string `json:"flightNumber"`
+	// FareClass — класс нового билета, по умолчанию эконом
+	FareClass string `json:"fareClass"`
+	// Price — цена нового билета, которую видел клиент; 0 — по текущему тарифу
+	Price int `json:"price"`
+	// PaidFromBalance — доплату за обмен списать с бонусного счёта
+	PaidFromBalance bool `json:"paidFromBalance"`
+}
+
+type ExchangeTicketResponse struct {
+	ExchangedTicketUID string     `json:"exchangedTicketUid"`
+	Ticket             TicketInfo `json:"ticket"`
+	ExchangeFee        int        `json:"exchangeFee"`
+	// FareDifference — доплата (больше 0) или возврат (меньше 0) с учётом сбора за обмен
+	FareDifference    int `json:"fareDifference"`
+	PaidByMoney       int `json:"paidByMoney"`
+	PaidByBonuses     int `json:"paidByBonuses"`
+	RefundedToBalance int `json:"refundedToBalance"`
+	// Privilege — бонусный счёт после обмена, если по нему было движение
+	Privilege *PrivilegeInfo `json:"privilege,omitempty"`
+}
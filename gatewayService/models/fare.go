@@ -0,0 +1,34 @@
+package models
+
+const (
+	FareEconomy  = "ECONOMY"
+	FareComfort  = "COMFORT"
+	FareBusiness = "BUSINESS"
+)
+
+type FareInfo struct {
+	FareClass      string `json:"fareClass"`
+	Price          int    `json:"price"`
+	AvailableSeats int    `json:"availableSeats"`
+	Refundable     bool   `json:"refundable"`
+	Exchangeable   bool   `json:"exchangeable"`
+	ExchangeFee    int    `json:"exchangeFee"`
+}
+
+type AdminFareRequest struct {
+	Price        int  `json:"price"`
+	Capacity     int  `json:"capacity"`
+	Refundable   bool `json:"refundable"`
+	Exchangeable bool `json:"exchangeable"`
+	ExchangeFee  int  `json:"exchangeFee"`
+}
+
+// PriceChangedResponse — ответ на покупку по устаревшей цене: клиент получает
+// актуальный тариф и может повторить покупку с ним.
+type PriceChangedResponse struct {
+	Message      string `json:"message"`
+	FlightNumber string `json:"flightNumber"`
+	FareClass    string `json:"fareClass"`
+	QuotedPrice  int    `json:"quotedPrice"`
+	Price        int    `json:"price"`
+}
@@ -2,10 +2,36 @@ package models
 
 import "time"
 
+// Оперативные статусы рейса в Flight Service. DEPARTED и CANCELLED — конечные.
+const (
+	FlightScheduled = "SCHEDULED"
+	FlightBoarding  = "BOARDING"
+	FlightDeparted  = "DEPARTED"
+	FlightDelayed   = "DELAYED"
+	FlightCancelled = "CANCELLED"
+)
+
 type FlightResponse struct {
-	FlightNumber string
-	FromAirport  string
-	ToAirport    string
-	Date         time.Time
-	Price        int
+	ID             int
+	FlightNumber   string
+	FromAirport    string
+	ToAirport      string
+	Date           time.Time
+	Price          int
+	AvailableSeats int
+	// Status, расчётное время и выход — оперативные данные рейса
+	Status             string
+	EstimatedDeparture *time.Time
+	EstimatedArrival   *time.Time
+	Gate               string
+}
+
+// Closed — рейс отменён или уже вылетел, билеты на него не продаются.
+func (f *FlightResponse) Closed() bool {
+	return f.Status == FlightCancelled || f.Status == FlightDeparted
+}
+
+type FlightsPage struct {
+	Items []*FlightResponse `json:"items"`
+	Total int               `json:"totalElements"`
 }
@@ -3,11 +3,12 @@ package models
 import "time"
 
 type FlightInfo struct {
-	FlightNumber string    `json:"flightNumber"`
-	FromAirport  string    `json:"fromAirport"`
-	ToAirport    string    `json:"toAirport"`
-	Date         time.Time `json:"date"`
-	Price        int       `json:"price"`
+	FlightNumber   string    `json:"flightNumber"`
+	FromAirport    string    `json:"fromAirport"`
+	ToAirport      string    `json:"toAirport"`
+	Date           time.Time `json:"date"`
+	Price          int       `json:"price"`
+	AvailableSeats int       `json:"availableSeats"`
 }
 type FlightsInfo struct {
 	Page    int          `json:"page"`
@@ -22,6 +23,7 @@ type TicketInfoRequest struct {
 	Username     string `json:"username"`
 	Price        int    `json:"price"`
 	Status       string `json:"status"`
+	FareClass    string `json:"fareClass"`
 }
 
 type TicketInfo struct {
@@ -32,6 +34,22 @@ type TicketInfo struct {
 	Date         time.Time `json:"date"`
 	Price        int       `json:"price"`
 	Status       string    `json:"status"`
+	Seat         string    `json:"seat,omitempty"`
+	FareClass    string    `json:"fareClass,omitempty"`
+	// HoldExpiresAt — до какого момента нужно оплатить бронь
+	HoldExpiresAt *time.Time `json:"holdExpiresAt,omitempty"`
+	// ExchangedFor — на какой билет обменян этот
+	ExchangedFor *string `json:"exchangedFor,omitempty"`
+	// BookingReference — код бронирования, в котором куплен билет
+	BookingReference string         `json:"bookingReference,omitempty"`
+	Passenger        *PassengerInfo `json:"passenger,omitempty"`
+	// ItineraryUID — маршрут, перелётом которого является билет
+	ItineraryUID string `json:"itineraryUid,omitempty"`
+	// FlightStatus, расчётное время и выход — оперативные данные рейса
+	FlightStatus       string     `json:"flightStatus,omitempty"`
+	EstimatedDeparture *time.Time `json:"estimatedDeparture,omitempty"`
+	EstimatedArrival   *time.Time `json:"estimatedArrival,omitempty"`
+	Gate               string     `json:"gate,omitempty"`
 }
 
 type PrivilegeInfo struct {
@@ -40,23 +58,18 @@ type PrivilegeInfo struct {
 	Status  string `json:"status"`
 }
 
-type PrivilegeHistoryInfo struct {
-	PrivilegeID   int       `json:"privilegeId"`
-	TicketUID     string    `json:"ticketUid"`
-	Date          time.Time `json:"date" db:"datetime"`
-	BalanceDiff   int       `json:"balanceDiff"`
-	OperationType string    `json:"operationType"`
-}
-
 type UserInfoResponse struct {
 	Tickets   []TicketInfo  `json:"tickets"`
 	Privilege PrivilegeInfo `json:"privilege"`
 }
 
 type BuyTicketInfo struct {
-	FlightNumber    string `json:"flightNumber"`
-	Price           int    `json:"price"`
-	PaidFromBalance bool   `json:"paidFromBalance"`
+	FlightNumber string `json:"flightNumber"`
+	// Price — цена, которую видел клиент; 0 — купить по текущему тарифу
+	Price           int  `json:"price"`
+	PaidFromBalance bool `json:"paidFromBalance"`
+	// FareClass — класс обслуживания, по умолчанию эконом
+	FareClass string `json:"fareClass"`
 }
 
 type BuyTicketResponse struct {
@@ -66,6 +79,7 @@ type BuyTicketResponse struct {
 	ToAirport     string            `json:"toAirport"`
 	Date          time.Time         `json:"date"`
 	Price         int               `json:"price"`
+	FareClass     string            `json:"fareClass"`
 	PaidByMoney   int               `json:"paidByMoney"`
 	PaidByBonuses int               `json:"paidByBonuses"`
 	Status        string            `json:"status"`
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	StatusCode  int
+	ContentType string
+	Response    []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+// Completed сообщает, что запрос с этим ключом уже обработан и ответ сохранён.
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+type ItineraryLegRequest struct {
+	FlightNumber string `json:"flightNumber"`
+	// Price — цена перелёта, которую видел клиент; 0 — по текущему тарифу
+	Price     int    `json:"price"`
+	FareClass string `json:"fareClass"`
+}
+
+// ItineraryRequest — покупка маршрута: перелёты в порядке вылета.
+type ItineraryRequest struct {
+	Legs            []ItineraryLegRequest `json:"legs"`
+	PaidFromBalance bool                  `json:"paidFromBalance"`
+}
+
+type ItineraryResponse struct {
+	ItineraryUID  string            `json:"itineraryUid"`
+	Tickets       []TicketInfo      `json:"tickets"`
+	TotalPrice    int               `json:"totalPrice"`
+	PaidByMoney   int               `json:"paidByMoney"`
+	PaidByBonuses int               `json:"paidByBonuses"`
+	Privilege     PrivilegeResponse `json:"privilege"`
+}
+
+type ItineraryInfo struct {
+	ItineraryUID string       `json:"itineraryUid"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	Tickets      []TicketInfo `json:"tickets"`
+	TotalPrice   int          `json:"totalPrice"`
+}
+
+type ItineraryTicketRequest struct {
+	UID          string `json:"ticketUid"`
+	FlightNumber string `json:"flightNumber"`
+	Price        int    `json:"price"`
+	FareClass    string `json:"fareClass"`
+}
+
+type ItineraryCreateRequest struct {
+	ItineraryUID string                   `json:"itineraryUid"`
+	Tickets      []ItineraryTicketRequest `json:"tickets"`
+}
+
+// ItineraryServiceResponse — маршрут в ответе Ticket Service.
+type ItineraryServiceResponse struct {
+	ItineraryUID string            `json:"itineraryUid"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	Tickets      []*TicketResponse `json:"tickets"`
+}
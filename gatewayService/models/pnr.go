@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// BirthDateLayout — формат даты рождения пассажира в API gateway.
+const BirthDateLayout = "2006-01-02"
+
+// PassengerInfo — пассажир в запросах и ответах gateway; BirthDate в формате BirthDateLayout.
+type PassengerInfo struct {
+	FirstName      string `json:"firstName"`
+	LastName       string `json:"lastName"`
+	DocumentNumber string `json:"documentNumber"`
+	BirthDate      string `json:"birthDate,omitempty"`
+}
+
+// PassengerResponse — пассажир билета в ответе Ticket Service.
+type PassengerResponse struct {
+	FirstName      string     `json:"firstName"`
+	LastName       string     `json:"lastName"`
+	DocumentNumber string     `json:"documentNumber"`
+	BirthDate      *time.Time `json:"birthDate,omitempty"`
+}
+
+type PNRRequest struct {
+	FlightNumber string `json:"flightNumber"`
+	// Price — цена одного билета, которую видел клиент; 0 — по текущему тарифу
+	Price           int             `json:"price"`
+	FareClass       string          `json:"fareClass"`
+	PaidFromBalance bool            `json:"paidFromBalance"`
+	Passengers      []PassengerInfo `json:"passengers"`
+}
+
+type PNRResponse struct {
+	Reference     string         `json:"reference"`
+	Tickets       []TicketInfo   `json:"tickets"`
+	TotalPrice    int            `json:"totalPrice"`
+	PaidByMoney   int            `json:"paidByMoney"`
+	PaidByBonuses int            `json:"paidByBonuses"`
+	Privilege     *PrivilegeInfo `json:"privilege,omitempty"`
+}
+
+// PNRInfo — бронирование, найденное по коду и фамилии.
+type PNRInfo struct {
+	Reference string       `json:"reference"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Tickets   []TicketInfo `json:"tickets"`
+}
+
+type PNRTicketRequest struct {
+	UID          string            `json:"ticketUid"`
+	FlightNumber string            `json:"flightNumber"`
+	Price        int               `json:"price"`
+	FareClass    string            `json:"fareClass"`
+	Passenger    PassengerResponse `json:"passenger"`
+}
+
+type PNRCreateRequest struct {
+	Tickets []PNRTicketRequest `json:"tickets"`
+}
+
+// PNRServiceResponse — бронирование в ответе Ticket Service.
+type PNRServiceResponse struct {
+	Reference string            `json:"reference"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Tickets   []*TicketResponse `json:"tickets"`
+}
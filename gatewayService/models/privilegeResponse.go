@@ -20,3 +20,18 @@ type PrivilegeFullResponse struct {
 	Status  string                     `json:"status"`
 	History []PrivilegeHistoryResponse `json:"history"`
 }
+
+type PrivilegeOperationRequest struct {
+	Username      string `json:"username"`
+	TicketUID     string `json:"ticketUid"`
+	OperationType string `json:"operationType"`
+	Amount        int    `json:"amount"`
+}
+
+type PrivilegeOperationResponse struct {
+	ID            int    `json:"id"`
+	Balance       int    `json:"balance"`
+	Status        string `json:"status"`
+	BalanceDiff   int    `json:"balanceDiff"`
+	OperationType string `json:"operationType"`
+}
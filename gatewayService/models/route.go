@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type RouteLegInfo struct {
+	FlightID          int       `json:"flightId"`
+	FlightNumber      string    `json:"flightNumber"`
+	FromAirport       string    `json:"fromAirport"`
+	ToAirport         string    `json:"toAirport"`
+	DepartureDate     time.Time `json:"departureDate"`
+	ArrivalDate       time.Time `json:"arrivalDate"`
+	FareClass         string    `json:"fareClass"`
+	Price             int       `json:"price"`
+	AvailableSeats    int       `json:"availableSeats"`
+	ConnectionMinutes int       `json:"connectionMinutes"`
+}
+
+// RouteInfo — маршрут с пересадками; перелёты можно купить одним маршрутом через /api/v1/itineraries.
+type RouteInfo struct {
+	Legs            []*RouteLegInfo `json:"legs"`
+	Connections     int             `json:"connections"`
+	TotalPrice      int             `json:"totalPrice"`
+	DurationMinutes int             `json:"durationMinutes"`
+	DepartureDate   time.Time       `json:"departureDate"`
+	ArrivalDate     time.Time       `json:"arrivalDate"`
+}
@@ -0,0 +1,14 @@
+package models
+
+type SeatInfo struct {
+	SeatNumber string `json:"seatNumber"`
+	Row        int    `json:"row"`
+	Letter     string `json:"letter"`
+	CabinClass string `json:"cabinClass"`
+	ExitRow    bool   `json:"exitRow"`
+	Available  bool   `json:"available"`
+}
+
+type SeatSelectionRequest struct {
+	SeatNumber string `json:"seatNumber"`
+}
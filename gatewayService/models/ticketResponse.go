@@ -1,8 +1,38 @@
 package models
 
+import "time"
+
+const (
+	TicketHeld     = "HELD"
+	TicketPaid     = "PAID"
+	TicketCanceled = "CANCELED"
+	TicketExpired  = "EXPIRED"
+	// TicketCheckedIn — пассажир зарегистрирован на рейс.
+	TicketCheckedIn = "CHECKED_IN"
+	TicketExchanged = "EXCHANGED"
+)
+
 type TicketResponse struct {
 	TicketUID    string `json:"ticketUid"`
 	FlightNumber string `json:"flightNumber"`
 	Price        int    `json:"price"`
 	Status       string `json:"status"`
+	Seat         string `json:"seat"`
+	FareClass    string `json:"fareClass"`
+	// HoldExpiresAt — срок оплаты брони, только для HELD
+	HoldExpiresAt *time.Time `json:"holdExpiresAt,omitempty"`
+	// ExchangedFor — UID билета, на который обменян этот, только для EXCHANGED
+	ExchangedFor *string `json:"exchangedFor,omitempty"`
+	// BookingReference и Passenger есть только у билетов из бронирования
+	BookingReference string             `json:"bookingReference,omitempty"`
+	Passenger        *PassengerResponse `json:"passenger,omitempty"`
+	// ItineraryUID — маршрут, перелётом которого является билет
+	ItineraryUID string `json:"itineraryUid,omitempty"`
+}
+
+// FlightTicketResponse — билет рейса с владельцем, как его отдаёт Ticket
+// Service администратору.
+type FlightTicketResponse struct {
+	Username string `json:"username"`
+	TicketResponse
 }
@@ -0,0 +1,39 @@
+package models
+
+const (
+	RoleUser  = "USER"
+	RoleAdmin = "ADMIN"
+	// RoleService — роль identity-токена, с которым gateway действует от своего
+	// имени над ресурсами пользователя. Пользователям не выдаётся.
+	RoleService = "SERVICE"
+)
+
+type User struct {
+	ID           int
+	Username     string
+	PasswordHash string
+	Role         string
+}
+
+// AuthUser — пользователь, подтверждённый JWT.
+type AuthUser struct {
+	ID       int
+	Username string
+	Role     string
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int    `json:"expiresIn"`
+}
@@ -0,0 +1,145 @@
+package apiclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrBadRequest  = errors.New("bad request")
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrUnavailable = errors.New("service unavailable")
+	ErrUnexpected  = errors.New("unexpected response")
+)
+
+// Error описывает неуспешный ответ сервиса. Kind — один из Err* выше,
+// поэтому ошибку можно проверять через errors.Is.
+type Error struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string
+	Kind       error
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s %s: %s: %s", e.Method, e.URL, e.Kind, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %s: status %d: %s", e.Method, e.URL, e.Kind, e.StatusCode, e.Message)
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func kindByStatus(statusCode int) error {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return ErrNotFound
+	case statusCode == http.StatusConflict:
+		return ErrConflict
+	case statusCode == http.StatusBadGateway, statusCode == http.StatusServiceUnavailable, statusCode == http.StatusGatewayTimeout:
+		return ErrUnavailable
+	case statusCode >= 400 && statusCode < 500:
+		return ErrBadRequest
+	default:
+		return ErrUnexpected
+	}
+}
+
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func New(baseURL string, httpClient *http.Client) Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return Client{
+		BaseURL:    baseURL,
+		HTTPClient: httpClient,
+	}
+}
+
+// IdentityHeader несёт подписанный gateway токен с именем пользователя.
+const IdentityHeader = "X-User-Identity"
+
+type identityKey struct{}
+
+// WithIdentity добавляет токен пользователя ко всем запросам с этим контекстом.
+func WithIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// Do отправляет запрос, кодируя in в JSON, и декодирует тело успешного ответа в out.
+// Тело ответа всегда вычитывается и закрывается; заголовки ответа доступны вызывающему.
+func (c Client) Do(ctx context.Context, method string, path string, header http.Header, in any, out any) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		jsonIn, err := json.Marshal(in)
+		if err != nil {
+			return nil, errors.Wrap(err, "can`t marshal request")
+		}
+		body = bytes.NewReader(jsonIn)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can`t create request")
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if identity, ok := ctx.Value(identityKey{}).(string); ok {
+		req.Header.Set(IdentityHeader, identity)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &Error{Method: method, URL: req.URL.Path, Kind: ErrUnavailable, Err: err}
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, &Error{Method: method, URL: req.URL.Path, Kind: ErrUnavailable, Err: err}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, &Error{
+			Method:     method,
+			URL:        req.URL.Path,
+			StatusCode: resp.StatusCode,
+			Message:    string(bytes.TrimSpace(respBody)),
+			Kind:       kindByStatus(resp.StatusCode),
+		}
+	}
+
+	if out != nil && len(respBody) > 0 {
+		err = json.Unmarshal(respBody, out)
+		if err != nil {
+			return resp, &Error{Method: method, URL: req.URL.Path, StatusCode: resp.StatusCode, Kind: ErrUnexpected, Err: err}
+		}
+	}
+
+	return resp, nil
+}
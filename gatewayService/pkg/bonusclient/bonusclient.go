@@ -0,0 +1,67 @@
+package bonusclient
+
+import (
+	"context"
+	"net/http"
+
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"github.com/pkg/errors"
+)
+
+type Client struct {
+	api apiclient.Client
+}
+
+func New(baseURL string, httpClient *http.Client) *Client {
+	return &Client{
+		api: apiclient.New(baseURL, httpClient),
+	}
+}
+
+func userHeader(userName string) http.Header {
+	header := http.Header{}
+	header.Set("X-User-Name", userName)
+	return header
+}
+
+func (c *Client) GetPrivilege(ctx context.Context, userName string) (*models.PrivilegeResponse, error) {
+	privilege := &models.PrivilegeResponse{}
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/privileges", userHeader(userName), nil, privilege)
+	if err != nil {
+		return nil, errors.Wrap(err, "bonusclient.GetPrivilege error")
+	}
+
+	return privilege, nil
+}
+
+func (c *Client) GetHistory(ctx context.Context, userName string) ([]models.PrivilegeHistoryResponse, error) {
+	history := make([]models.PrivilegeHistoryResponse, 0)
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/privilegeHistory", userHeader(userName), nil, &history)
+	if err != nil {
+		return nil, errors.Wrap(err, "bonusclient.GetHistory error")
+	}
+
+	return history, nil
+}
+
+func (c *Client) ApplyOperation(ctx context.Context, operation models.PrivilegeOperationRequest) (*models.PrivilegeOperationResponse, error) {
+	result := &models.PrivilegeOperationResponse{}
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/privileges/operations", nil, operation, result)
+	if err != nil {
+		return nil, errors.Wrap(err, "bonusclient.ApplyOperation error")
+	}
+
+	return result, nil
+}
+
+// RevertHistory отменяет все движения бонусов пользователя по билету.
+func (c *Client) RevertHistory(ctx context.Context, userName string, ticketUID string) (*models.PrivilegeResponse, error) {
+	privilege := &models.PrivilegeResponse{}
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/privileges/history/"+ticketUID+"/revert", userHeader(userName), nil, privilege)
+	if err != nil {
+		return nil, errors.Wrap(err, "bonusclient.RevertHistory error")
+	}
+
+	return privilege, nil
+}
@@ -0,0 +1,142 @@
+package breaker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type Config struct {
+	// FailureThreshold — сколько неудачных запросов подряд размыкают breaker.
+	FailureThreshold int
+	// OpenTimeout — сколько breaker остаётся разомкнутым перед пробным запросом.
+	OpenTimeout time.Duration
+	// Timeout ограничивает одну попытку запроса, включая чтение тела ответа.
+	Timeout time.Duration
+	// MaxRetries — число повторов для идемпотентных GET-запросов.
+	MaxRetries int
+	// RetryBackoff — базовая задержка перед повтором, растёт экспоненциально.
+	RetryBackoff time.Duration
+}
+
+var DefaultConfig = Config{
+	FailureThreshold: 5,
+	OpenTimeout:      10 * time.Second,
+	Timeout:          3 * time.Second,
+	MaxRetries:       2,
+	RetryBackoff:     100 * time.Millisecond,
+}
+
+type OpenError struct {
+	Name       string
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker %q is open, retry after %s", e.Name, e.RetryAfter)
+}
+
+type Breaker struct {
+	Name string
+	cfg  Config
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	probeSent bool
+}
+
+func New(name string, cfg Config) *Breaker {
+	return &Breaker{
+		Name: name,
+		cfg:  cfg,
+	}
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.currentState(time.Now())
+}
+
+func (b *Breaker) currentState(now time.Time) State {
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.state = StateHalfOpen
+		b.probeSent = false
+	}
+
+	return b.state
+}
+
+// Allow проверяет, можно ли выполнить запрос. В полуоткрытом состоянии
+// пропускается только один пробный запрос.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	switch b.currentState(now) {
+	case StateOpen:
+		return &OpenError{Name: b.Name, RetryAfter: b.cfg.OpenTimeout - now.Sub(b.openedAt)}
+	case StateHalfOpen:
+		if b.probeSent {
+			return &OpenError{Name: b.Name, RetryAfter: b.cfg.OpenTimeout}
+		}
+		b.probeSent = true
+	}
+
+	return nil
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Release возвращает право на пробный запрос, если попытка прервалась не по
+// вине сервиса. Иначе полуоткрытый breaker ждал бы ответа пробы вечно.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.probeSent = false
+	}
+}
@@ -0,0 +1,93 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+)
+
+const testOpenTimeout = 20 * time.Millisecond
+
+type BreakerTestSuite struct {
+	suite.Suite
+	breaker *Breaker
+}
+
+func TestBreakerSuite(t *testing.T) {
+	suite.RunSuite(t, new(BreakerTestSuite))
+}
+
+func (s *BreakerTestSuite) BeforeEach(t provider.T) {
+	s.breaker = New("test", Config{FailureThreshold: 3, OpenTimeout: testOpenTimeout})
+}
+
+// open размыкает breaker серией неудач.
+func (s *BreakerTestSuite) open(t provider.T) {
+	for i := 0; i < 3; i++ {
+		t.Require().NoError(s.breaker.Allow())
+		s.breaker.Failure()
+	}
+	t.Require().Equal(StateOpen, s.breaker.State())
+}
+
+func (s *BreakerTestSuite) TestOpensAfterThreshold(t provider.T) {
+	for i := 0; i < 2; i++ {
+		t.Require().NoError(s.breaker.Allow())
+		s.breaker.Failure()
+	}
+	t.Assert().Equal(StateClosed, s.breaker.State())
+
+	// Успех сбрасывает счётчик неудач подряд
+	s.breaker.Success()
+	s.breaker.Failure()
+	t.Assert().Equal(StateClosed, s.breaker.State())
+
+	s.breaker.Failure()
+	s.breaker.Failure()
+	t.Assert().Equal(StateOpen, s.breaker.State())
+
+	var openErr *OpenError
+	t.Assert().ErrorAs(s.breaker.Allow(), &openErr)
+}
+
+func (s *BreakerTestSuite) TestHalfOpenProbeSuccess(t provider.T) {
+	s.open(t)
+
+	time.Sleep(testOpenTimeout)
+	t.Require().Equal(StateHalfOpen, s.breaker.State())
+
+	t.Require().NoError(s.breaker.Allow())
+	// Пока проба не вернулась, остальные запросы не пропускаются
+	t.Assert().Error(s.breaker.Allow())
+
+	s.breaker.Success()
+	t.Assert().Equal(StateClosed, s.breaker.State())
+	t.Assert().NoError(s.breaker.Allow())
+}
+
+func (s *BreakerTestSuite) TestHalfOpenProbeFailure(t provider.T) {
+	s.open(t)
+
+	time.Sleep(testOpenTimeout)
+	t.Require().NoError(s.breaker.Allow())
+
+	s.breaker.Failure()
+	t.Assert().Equal(StateOpen, s.breaker.State())
+	t.Assert().Error(s.breaker.Allow())
+
+	time.Sleep(testOpenTimeout)
+	t.Assert().Equal(StateHalfOpen, s.breaker.State())
+}
+
+func (s *BreakerTestSuite) TestHalfOpenProbeReleased(t provider.T) {
+	s.open(t)
+
+	time.Sleep(testOpenTimeout)
+	t.Require().NoError(s.breaker.Allow())
+
+	s.breaker.Release()
+	t.Assert().Equal(StateHalfOpen, s.breaker.State())
+	t.Assert().NoError(s.breaker.Allow())
+}
@@ -0,0 +1,156 @@
+package breaker
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"flight_booking_system/gatewayService/pkg/logger"
+)
+
+// Transport — http.RoundTripper, который заводит отдельный breaker на каждый
+// хост, ограничивает время попытки и повторяет идемпотентные GET-запросы.
+type Transport struct {
+	Base   http.RoundTripper
+	Logger logger.Logger
+
+	mu       sync.Mutex
+	config   Config
+	configs  map[string]Config
+	breakers map[string]*Breaker
+}
+
+func NewTransport(base http.RoundTripper, logger logger.Logger, cfg Config) *Transport {
+	return &Transport{
+		Base:     base,
+		Logger:   logger,
+		config:   cfg,
+		configs:  make(map[string]Config),
+		breakers: make(map[string]*Breaker),
+	}
+}
+
+// Configure задаёт настройки для конкретного хоста вместо общих.
+func (t *Transport) Configure(host string, cfg Config) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.configs[host] = cfg
+	delete(t.breakers, host)
+}
+
+func (t *Transport) Breaker(host string) (*Breaker, Config) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cfg, ok := t.configs[host]
+	if !ok {
+		cfg = t.config
+	}
+
+	b, ok := t.breakers[host]
+	if !ok {
+		b = New(host, cfg)
+		t.breakers[host] = b
+	}
+
+	return b, cfg
+}
+
+func isDownstreamFailure(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func backoff(base time.Duration, attempt int) time.Duration {
+	maxDelay := base << (attempt - 1)
+	if maxDelay <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(maxDelay)))
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b, cfg := t.Breaker(req.URL.Host)
+
+	attempts := 1
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		attempts += cfg.MaxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff(cfg.RetryBackoff, attempt)):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+
+		if err := b.Allow(); err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), cfg.Timeout)
+		resp, err := t.Base.RoundTrip(req.Clone(ctx))
+		if err != nil {
+			cancel()
+			// Запрос отменил сам вызывающий: сервис тут ни при чём
+			if req.Context().Err() != nil {
+				b.Release()
+				return nil, err
+			}
+
+			b.Failure()
+			lastErr = err
+			t.Logger.Infow("downstream request failed",
+				"host", req.URL.Host,
+				"url", req.URL.Path,
+				"attempt", attempt+1,
+				"err:", err.Error())
+			continue
+		}
+
+		if isDownstreamFailure(resp) {
+			b.Failure()
+			if attempt+1 < attempts {
+				_, _ = io.Copy(io.Discard, resp.Body)
+				_ = resp.Body.Close()
+				cancel()
+				t.Logger.Infow("downstream request failed",
+					"host", req.URL.Host,
+					"url", req.URL.Path,
+					"attempt", attempt+1,
+					"status", resp.StatusCode)
+				continue
+			}
+		} else {
+			b.Success()
+		}
+
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
+	}
+
+	return nil, lastErr
+}
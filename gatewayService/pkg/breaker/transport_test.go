@@ -0,0 +1,156 @@
+package breaker
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type TransportTestSuite struct {
+	suite.Suite
+	transport *Transport
+	calls     int
+	respond   func(req *http.Request) (*http.Response, error)
+}
+
+func TestTransportSuite(t *testing.T) {
+	suite.RunSuite(t, new(TransportTestSuite))
+}
+
+func (s *TransportTestSuite) BeforeEach(t provider.T) {
+	s.calls = 0
+	s.respond = func(req *http.Request) (*http.Response, error) {
+		return response(http.StatusOK), nil
+	}
+
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		s.calls++
+		return s.respond(req)
+	})
+
+	s.transport = NewTransport(base, zap.NewNop().Sugar(), Config{
+		FailureThreshold: 10,
+		OpenTimeout:      time.Minute,
+		Timeout:          time.Second,
+		MaxRetries:       2,
+		RetryBackoff:     time.Millisecond,
+	})
+}
+
+func response(statusCode int) *http.Response {
+	return &http.Response{StatusCode: statusCode, Body: io.NopCloser(strings.NewReader(""))}
+}
+
+func (s *TransportTestSuite) do(ctx context.Context, method string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, "http://service:8080/api", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.transport.RoundTrip(req)
+}
+
+func (s *TransportTestSuite) TestRetries(t provider.T) {
+	cases := map[string]struct {
+		Method string
+		Calls  int
+	}{
+		"GET is retried": {
+			Method: http.MethodGet,
+			Calls:  3,
+		},
+		"HEAD is retried": {
+			Method: http.MethodHead,
+			Calls:  3,
+		},
+		"POST is not retried": {
+			Method: http.MethodPost,
+			Calls:  1,
+		},
+		"DELETE is not retried": {
+			Method: http.MethodDelete,
+			Calls:  1,
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			s.BeforeEach(t)
+			s.respond = func(req *http.Request) (*http.Response, error) {
+				return response(http.StatusServiceUnavailable), nil
+			}
+
+			resp, err := s.do(context.Background(), test.Method)
+
+			t.Require().NoError(err)
+			t.Assert().Equal(http.StatusServiceUnavailable, resp.StatusCode)
+			t.Assert().Equal(test.Calls, s.calls)
+			_ = resp.Body.Close()
+		})
+	}
+}
+
+func (s *TransportTestSuite) TestRetrySucceeds(t provider.T) {
+	s.respond = func(req *http.Request) (*http.Response, error) {
+		if s.calls == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return response(http.StatusOK), nil
+	}
+
+	resp, err := s.do(context.Background(), http.MethodGet)
+
+	t.Require().NoError(err)
+	t.Assert().Equal(http.StatusOK, resp.StatusCode)
+	t.Assert().Equal(2, s.calls)
+	_ = resp.Body.Close()
+}
+
+func (s *TransportTestSuite) TestOpenAfterFailures(t provider.T) {
+	s.transport.Configure("service:8080", Config{FailureThreshold: 2, OpenTimeout: time.Minute, Timeout: time.Second})
+	s.respond = func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := s.do(context.Background(), http.MethodPost)
+		t.Require().Error(err)
+	}
+
+	_, err := s.do(context.Background(), http.MethodPost)
+
+	var openErr *OpenError
+	t.Assert().ErrorAs(err, &openErr)
+	t.Assert().Equal(2, s.calls)
+}
+
+func (s *TransportTestSuite) TestCallerCanceled(t provider.T) {
+	s.transport.Configure("service:8080", Config{FailureThreshold: 1, OpenTimeout: time.Minute, Timeout: time.Second, MaxRetries: 2})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.respond = func(req *http.Request) (*http.Response, error) {
+		cancel()
+		return nil, req.Context().Err()
+	}
+
+	_, err := s.do(ctx, http.MethodGet)
+
+	t.Assert().ErrorIs(err, context.Canceled)
+	t.Assert().Equal(1, s.calls)
+	b, _ := s.transport.Breaker("service:8080")
+	t.Assert().Equal(StateClosed, b.State())
+}
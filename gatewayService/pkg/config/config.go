@@ -0,0 +1,164 @@
+package config
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"flight_booking_system/gatewayService/pkg/breaker"
+)
+
+type ServerConfig struct {
+	Addr              string        `yaml:"addr" env:"SERVER_ADDR" required:"true"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" env:"SERVER_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" env:"SERVER_WRITE_TIMEOUT"`
+	// ShutdownTimeout ограничивает ожидание текущих запросов при остановке.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay — сколько сервер отвечает not ready перед остановкой.
+	ShutdownDelay time.Duration `yaml:"shutdownDelay" env:"SERVER_SHUTDOWN_DELAY"`
+}
+
+type PostgresConfig struct {
+	DSN string `yaml:"dsn" env:"POSTGRES_DSN" required:"true"`
+}
+
+type SessionConfig struct {
+	TokenKey    string        `yaml:"tokenKey" env:"TOKEN_KEY" required:"true"`
+	AccessTTL   time.Duration `yaml:"accessTTL" env:"ACCESS_TOKEN_TTL" required:"true"`
+	RefreshTTL  time.Duration `yaml:"refreshTTL" env:"REFRESH_TOKEN_TTL" required:"true"`
+	IdentityTTL time.Duration `yaml:"identityTTL" env:"IDENTITY_TOKEN_TTL" required:"true"`
+}
+
+type ServicesConfig struct {
+	BonusHost  string `yaml:"bonusHost" env:"BONUS_HOST" required:"true"`
+	FlightHost string `yaml:"flightHost" env:"FLIGHT_HOST" required:"true"`
+	TicketHost string `yaml:"ticketHost" env:"TICKET_HOST" required:"true"`
+}
+
+type BreakerConfig struct {
+	FailureThreshold int           `yaml:"failureThreshold" env:"BREAKER_FAILURE_THRESHOLD"`
+	OpenTimeout      time.Duration `yaml:"openTimeout" env:"BREAKER_OPEN_TIMEOUT"`
+	Timeout          time.Duration `yaml:"timeout" env:"BREAKER_TIMEOUT"`
+	MaxRetries       int           `yaml:"maxRetries" env:"BREAKER_MAX_RETRIES"`
+	RetryBackoff     time.Duration `yaml:"retryBackoff" env:"BREAKER_RETRY_BACKOFF"`
+
+	Bonus  BreakerOverride `yaml:"bonus"`
+	Flight BreakerOverride `yaml:"flight"`
+	Ticket BreakerOverride `yaml:"ticket"`
+}
+
+// BreakerOverride — настройки breaker отдельного сервиса. Незаданные поля
+// берутся из общих.
+type BreakerOverride struct {
+	FailureThreshold *int           `yaml:"failureThreshold"`
+	OpenTimeout      *time.Duration `yaml:"openTimeout"`
+	Timeout          *time.Duration `yaml:"timeout"`
+	MaxRetries       *int           `yaml:"maxRetries"`
+	RetryBackoff     *time.Duration `yaml:"retryBackoff"`
+}
+
+func (bc BreakerConfig) Breaker() breaker.Config {
+	return breaker.Config{
+		FailureThreshold: bc.FailureThreshold,
+		OpenTimeout:      bc.OpenTimeout,
+		Timeout:          bc.Timeout,
+		MaxRetries:       bc.MaxRetries,
+		RetryBackoff:     bc.RetryBackoff,
+	}
+}
+
+// Service возвращает настройки breaker сервиса с учётом его перекрытий.
+func (bc BreakerConfig) Service(o BreakerOverride) breaker.Config {
+	cfg := bc.Breaker()
+
+	if o.FailureThreshold != nil {
+		cfg.FailureThreshold = *o.FailureThreshold
+	}
+	if o.OpenTimeout != nil {
+		cfg.OpenTimeout = *o.OpenTimeout
+	}
+	if o.Timeout != nil {
+		cfg.Timeout = *o.Timeout
+	}
+	if o.MaxRetries != nil {
+		cfg.MaxRetries = *o.MaxRetries
+	}
+	if o.RetryBackoff != nil {
+		cfg.RetryBackoff = *o.RetryBackoff
+	}
+
+	return cfg
+}
+
+type IdempotencyConfig struct {
+	// Store — хранилище ключей: postgres или memory. memory годится только
+	// для одного экземпляра gateway.
+	Store  string        `yaml:"store" env:"IDEMPOTENCY_STORE"`
+	KeyTTL time.Duration `yaml:"keyTTL" env:"IDEMPOTENCY_KEY_TTL"`
+	// PendingTimeout — сколько ключ считается занятым запросом, который так и
+	// не сохранил ответ. Должен быть больше времени обработки запроса.
+	PendingTimeout time.Duration `yaml:"pendingTimeout" env:"IDEMPOTENCY_PENDING_TIMEOUT"`
+}
+
+// AdminConfig — первый администратор. Он создаётся при запуске, если задано
+// имя и пользователя с таким именем ещё нет.
+type AdminConfig struct {
+	Username string `yaml:"username" env:"ADMIN_USERNAME"`
+	Password string `yaml:"password" env:"ADMIN_PASSWORD"`
+}
+
+type Config struct {
+	Server      ServerConfig      `yaml:"server"`
+	Postgres    PostgresConfig    `yaml:"postgres"`
+	Session     SessionConfig     `yaml:"session"`
+	Services    ServicesConfig    `yaml:"services"`
+	Breaker     BreakerConfig     `yaml:"breaker"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Admin       AdminConfig       `yaml:"admin"`
+}
+
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:              ":8080",
+			ReadTimeout:       10 * time.Second,
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      10 * time.Second,
+			ShutdownTimeout:   15 * time.Second,
+		},
+		Session: SessionConfig{
+			AccessTTL:   15 * time.Minute,
+			RefreshTTL:  7 * 24 * time.Hour,
+			IdentityTTL: time.Minute,
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold: breaker.DefaultConfig.FailureThreshold,
+			OpenTimeout:      breaker.DefaultConfig.OpenTimeout,
+			Timeout:          breaker.DefaultConfig.Timeout,
+			MaxRetries:       breaker.DefaultConfig.MaxRetries,
+			RetryBackoff:     breaker.DefaultConfig.RetryBackoff,
+		},
+		Idempotency: IdempotencyConfig{
+			Store:          "postgres",
+			KeyTTL:         24 * time.Hour,
+			PendingTimeout: time.Minute,
+		},
+	}
+}
+
+// Load читает конфигурацию gateway. Незаданные в файле поля берутся из Default.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	err := load(path, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Admin.Username != "" && cfg.Admin.Password == "" {
+		return nil, errors.New("config field admin.password is required with admin.username")
+	}
+
+	return &cfg, nil
+}
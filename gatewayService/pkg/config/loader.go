@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath — файл конфигурации, если CONFIG_PATH не задан.
+const DefaultPath = "config.yaml"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// load читает YAML-файл в cfg, затем перекрывает значения переменными
+// окружения из тегов env и проверяет поля с тегом required:"true".
+func load(path string, cfg any) error {
+	if path == "" {
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "can`t read config file %q", path)
+	}
+
+	err = yaml.Unmarshal(data, cfg)
+	if err != nil {
+		return errors.Wrapf(err, "can`t parse config file %q", path)
+	}
+
+	v := reflect.ValueOf(cfg).Elem()
+
+	err = applyEnv(v)
+	if err != nil {
+		return err
+	}
+
+	return checkRequired(v, "")
+}
+
+func applyEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+
+		if field.Kind() == reflect.Struct {
+			err := applyEnv(field)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := t.Field(i).Tag.Get("env")
+		if name == "" {
+			continue
+		}
+
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		err := setValue(field, value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value of %s", name)
+		}
+	}
+
+	return nil
+}
+
+func setValue(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	default:
+		return errors.Errorf("unsupported config field type %s", field.Type())
+	}
+
+	return nil
+}
+
+func checkRequired(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if field.Kind() == reflect.Struct {
+			err := checkRequired(field, name)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if t.Field(i).Tag.Get("required") == "true" && field.IsZero() {
+			return errors.Errorf("config field %s is required", name)
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package context
 import (
 	"context"
 
+	"flight_booking_system/gatewayService/models"
 	"github.com/pkg/errors"
 )
 
@@ -12,14 +13,14 @@ const contextUserKey contextKeyType = "contextUserKey"
 
 type Manager struct{}
 
-func (cu Manager) ContextWithUserID(ctx context.Context, userID int) context.Context {
-	return context.WithValue(ctx, contextUserKey, userID)
+func (cu Manager) ContextWithUser(ctx context.Context, user *models.AuthUser) context.Context {
+	return context.WithValue(ctx, contextUserKey, user)
 }
 
-func (cu Manager) UserIDFromContext(ctx context.Context) (int, error) {
-	user, ok := ctx.Value(contextUserKey).(int)
+func (cu Manager) UserFromContext(ctx context.Context) (*models.AuthUser, error) {
+	user, ok := ctx.Value(contextUserKey).(*models.AuthUser)
 	if !ok {
-		return -1, errors.Errorf("can`t get user from context")
+		return nil, errors.Errorf("can`t get user from context")
 	}
 
 	return user, nil
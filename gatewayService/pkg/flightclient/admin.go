@@ -0,0 +1,169 @@
+package flightclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"flight_booking_system/gatewayService/models"
+	"github.com/pkg/errors"
+)
+
+// flight — рейс в формате Flight Service.
+type flight struct {
+	ID              int       `json:"id,omitempty"`
+	FlightNumber    string    `json:"flightNumber,omitempty"`
+	DateTime        time.Time `json:"dateTime"`
+	FromAirportID   int       `json:"from_airport_id,omitempty"`
+	ToAirportID     int       `json:"to_airport_id,omitempty"`
+	Price           int       `json:"price,omitempty"`
+	Capacity        int       `json:"capacity,omitempty"`
+	SeatsReserved   int       `json:"seatsReserved,omitempty"`
+	DurationMinutes int       `json:"durationMinutes,omitempty"`
+
+	Status             string     `json:"status,omitempty"`
+	EstimatedDeparture *time.Time `json:"estimatedDeparture,omitempty"`
+	EstimatedArrival   *time.Time `json:"estimatedArrival,omitempty"`
+	Gate               string     `json:"gate,omitempty"`
+}
+
+func (f *flight) adminResponse() *models.AdminFlightResponse {
+	return &models.AdminFlightResponse{
+		ID:                 f.ID,
+		FlightNumber:       f.FlightNumber,
+		DateTime:           f.DateTime,
+		FromAirportID:      f.FromAirportID,
+		ToAirportID:        f.ToAirportID,
+		Price:              f.Price,
+		Capacity:           f.Capacity,
+		SeatsReserved:      f.SeatsReserved,
+		DurationMinutes:    f.DurationMinutes,
+		Status:             f.Status,
+		EstimatedDeparture: f.EstimatedDeparture,
+		EstimatedArrival:   f.EstimatedArrival,
+		Gate:               f.Gate,
+	}
+}
+
+func flightFromRequest(req models.AdminFlightRequest) flight {
+	return flight{
+		FlightNumber:    req.FlightNumber,
+		DateTime:        req.DateTime,
+		FromAirportID:   req.FromAirportID,
+		ToAirportID:     req.ToAirportID,
+		Price:           req.Price,
+		Capacity:        req.Capacity,
+		DurationMinutes: req.DurationMinutes,
+	}
+}
+
+// createdID достаёт id созданной записи из заголовка Location.
+func createdID(resp *http.Response) (int, error) {
+	id, err := strconv.Atoi(path.Base(resp.Header.Get("Location")))
+	if err != nil {
+		return 0, errors.Wrap(err, "can`t get id from Location header")
+	}
+
+	return id, nil
+}
+
+func (c *Client) ListAirports(ctx context.Context) ([]*models.AirportResponse, error) {
+	airports := make([]*models.AirportResponse, 0)
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/airports", nil, nil, &airports)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.ListAirports error")
+	}
+
+	return airports, nil
+}
+
+func (c *Client) CreateAirport(ctx context.Context, req models.AirportRequest) (int, error) {
+	resp, err := c.api.Do(ctx, http.MethodPost, "/api/v1/airports", nil, req, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "flightclient.CreateAirport error")
+	}
+
+	id, err := createdID(resp)
+	if err != nil {
+		return 0, errors.Wrap(err, "flightclient.CreateAirport error")
+	}
+
+	return id, nil
+}
+
+func (c *Client) UpdateAirport(ctx context.Context, id int, req models.AirportRequest) (*models.AirportResponse, error) {
+	airport := &models.AirportResponse{}
+	_, err := c.api.Do(ctx, http.MethodPatch, "/api/v1/airports/"+strconv.Itoa(id), nil, req, airport)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.UpdateAirport error")
+	}
+
+	return airport, nil
+}
+
+func (c *Client) DeleteAirport(ctx context.Context, id int) error {
+	_, err := c.api.Do(ctx, http.MethodDelete, "/api/v1/airports/"+strconv.Itoa(id), nil, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "flightclient.DeleteAirport error")
+	}
+
+	return nil
+}
+
+func (c *Client) CreateFlight(ctx context.Context, req models.AdminFlightRequest) (int, error) {
+	resp, err := c.api.Do(ctx, http.MethodPost, "/api/v1/flights", nil, flightFromRequest(req), nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "flightclient.CreateFlight error")
+	}
+
+	id, err := createdID(resp)
+	if err != nil {
+		return 0, errors.Wrap(err, "flightclient.CreateFlight error")
+	}
+
+	return id, nil
+}
+
+func (c *Client) UpdateFlight(ctx context.Context, id int, req models.AdminFlightRequest) (*models.AdminFlightResponse, error) {
+	updated := &flight{}
+	_, err := c.api.Do(ctx, http.MethodPatch, "/api/v1/flights/"+strconv.Itoa(id), nil, flightFromRequest(req), updated)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.UpdateFlight error")
+	}
+
+	return updated.adminResponse(), nil
+}
+
+// UpdateFlightStatus меняет оперативный статус рейса. Недопустимая смена
+// статуса — ошибка apiclient.ErrConflict.
+func (c *Client) UpdateFlightStatus(ctx context.Context, id int, req models.AdminFlightStatusRequest) (*models.AdminFlightResponse, error) {
+	updated := &flight{}
+	_, err := c.api.Do(ctx, http.MethodPut, "/api/v1/flights/"+strconv.Itoa(id)+"/status", nil, req, updated)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.UpdateFlightStatus error")
+	}
+
+	return updated.adminResponse(), nil
+}
+
+func (c *Client) SaveFare(ctx context.Context, flightID int, fareClass string, req models.AdminFareRequest) (*models.FareInfo, error) {
+	fare := &models.FareInfo{}
+	_, err := c.api.Do(ctx, http.MethodPut, "/api/v1/flights/"+strconv.Itoa(flightID)+"/fares/"+url.PathEscape(fareClass), nil, req, fare)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.SaveFare error")
+	}
+
+	return fare, nil
+}
+
+func (c *Client) DeleteFlight(ctx context.Context, id int) error {
+	_, err := c.api.Do(ctx, http.MethodDelete, "/api/v1/flights/"+strconv.Itoa(id), nil, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "flightclient.DeleteFlight error")
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package flightclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"github.com/pkg/errors"
+)
+
+type Client struct {
+	api apiclient.Client
+}
+
+func New(baseURL string, httpClient *http.Client) *Client {
+	return &Client{
+		api: apiclient.New(baseURL, httpClient),
+	}
+}
+
+func (c *Client) GetFlightByNumber(ctx context.Context, number string) (*models.FlightResponse, error) {
+	header := http.Header{}
+	header.Set("flightNumber", number)
+
+	flights := make([]*models.FlightResponse, 0)
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/flights", header, nil, &flights)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.GetFlightByNumber error")
+	}
+
+	if len(flights) == 0 {
+		return nil, &apiclient.Error{
+			Method:  http.MethodGet,
+			URL:     "/api/v1/flights",
+			Message: "flight " + number + " not found",
+			Kind:    apiclient.ErrNotFound,
+		}
+	}
+
+	return flights[0], nil
+}
+
+// SearchFlights ищет рейсы по фильтру Flight Service (маршрут, даты, цена,
+// сортировка). Возвращает страницу и общее число найденных рейсов.
+func (c *Client) SearchFlights(ctx context.Context, filter url.Values, page, size int) (*models.FlightsPage, error) {
+	q := url.Values{}
+	for name, values := range filter {
+		q[name] = values
+	}
+	q.Set("page", strconv.Itoa(page))
+	q.Set("size", strconv.Itoa(size))
+
+	flightsPage := &models.FlightsPage{}
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/flightsSearch?"+q.Encode(), nil, nil, flightsPage)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.SearchFlights error")
+	}
+
+	return flightsPage, nil
+}
+
+// FindRoutes ищет маршруты с пересадками по параметрам Flight Service.
+func (c *Client) FindRoutes(ctx context.Context, query url.Values) ([]*models.RouteInfo, error) {
+	routes := make([]*models.RouteInfo, 0)
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/routes?"+query.Encode(), nil, nil, &routes)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.FindRoutes error")
+	}
+
+	return routes, nil
+}
+
+// GetFlightsByNumbers получает рейсы пачкой. Рейсы, которых нет в Flight
+// Service, в ответе просто отсутствуют.
+func (c *Client) GetFlightsByNumbers(ctx context.Context, numbers []string) ([]*models.FlightResponse, error) {
+	q := url.Values{}
+	for _, number := range numbers {
+		q.Add("flightNumber", number)
+	}
+
+	flights := make([]*models.FlightResponse, 0, len(numbers))
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/flightsBatch?"+q.Encode(), nil, nil, &flights)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.GetFlightsByNumbers error")
+	}
+
+	return flights, nil
+}
+
+// GetFares возвращает классы обслуживания рейса с ценами и правилами.
+func (c *Client) GetFares(ctx context.Context, flightID int) ([]*models.FareInfo, error) {
+	fares := make([]*models.FareInfo, 0)
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/flights/"+strconv.Itoa(flightID)+"/fares", nil, nil, &fares)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.GetFares error")
+	}
+
+	return fares, nil
+}
+
+// ReserveSeat занимает место в классе обслуживания рейса под билет. Если мест
+// нет, вернётся ошибка apiclient.ErrConflict.
+func (c *Client) ReserveSeat(ctx context.Context, flightID int, fareClass string, ticketUID string) error {
+	body := struct {
+		TicketUID string `json:"ticketUid"`
+		FareClass string `json:"fareClass"`
+	}{TicketUID: ticketUID, FareClass: fareClass}
+
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/flights/"+strconv.Itoa(flightID)+"/reservations", nil, body, nil)
+	if err != nil {
+		return errors.Wrap(err, "flightclient.ReserveSeat error")
+	}
+
+	return nil
+}
+
+func (c *Client) ReleaseSeat(ctx context.Context, ticketUID string) error {
+	_, err := c.api.Do(ctx, http.MethodDelete, "/api/v1/reservations/"+url.PathEscape(ticketUID), nil, nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "flightclient.ReleaseSeat error")
+	}
+
+	return nil
+}
+
+func (c *Client) GetSeatMap(ctx context.Context, flightID int) ([]*models.SeatInfo, error) {
+	seats := make([]*models.SeatInfo, 0)
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/flights/"+strconv.Itoa(flightID)+"/seats", nil, nil, &seats)
+	if err != nil {
+		return nil, errors.Wrap(err, "flightclient.GetSeatMap error")
+	}
+
+	return seats, nil
+}
+
+// AssignSeat закрепляет кресло за билетом; пустой seatNumber снимает выбор.
+// Занятое кресло — ошибка apiclient.ErrConflict.
+func (c *Client) AssignSeat(ctx context.Context, ticketUID string, seatNumber string) error {
+	body := models.SeatSelectionRequest{SeatNumber: seatNumber}
+
+	_, err := c.api.Do(ctx, http.MethodPut, "/api/v1/reservations/"+url.PathEscape(ticketUID)+"/seat", nil, body, nil)
+	if err != nil {
+		return errors.Wrap(err, "flightclient.AssignSeat error")
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+)
+
+// DefaultTimeout ограничивает время всех проверок одного запроса readiness.
+const DefaultTimeout = 2 * time.Second
+
+// Check проверяет одну зависимость и возвращает ошибку, если она недоступна.
+type Check func(ctx context.Context) error
+
+type DependencyStatus struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status       string             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+type Checker struct {
+	Timeout time.Duration
+
+	mu     sync.Mutex
+	checks []namedCheck
+}
+
+func New(timeout time.Duration) *Checker {
+	return &Checker{
+		Timeout: timeout,
+	}
+}
+
+func (c *Checker) Add(name string, check Check) *Checker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+	return c
+}
+
+// Run выполняет все проверки параллельно с общим дедлайном Timeout.
+func (c *Checker) Run(ctx context.Context) Report {
+	c.mu.Lock()
+	checks := c.checks
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	report := Report{
+		Status:       StatusUp,
+		Dependencies: make([]DependencyStatus, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			start := time.Now()
+			err := nc.check(ctx)
+
+			status := DependencyStatus{
+				Name:    nc.name,
+				Status:  StatusUp,
+				Latency: time.Since(start).String(),
+			}
+			if err != nil {
+				status.Status = StatusDown
+				status.Error = err.Error()
+			}
+
+			report.Dependencies[i] = status
+		}()
+	}
+	wg.Wait()
+
+	for _, dependency := range report.Dependencies {
+		if dependency.Status != StatusUp {
+			report.Status = StatusDown
+			break
+		}
+	}
+
+	return report
+}
+
+// Live отвечает 200, пока процесс способен обрабатывать запросы.
+func (c *Checker) Live(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, http.StatusOK, Report{Status: StatusUp, Dependencies: []DependencyStatus{}})
+}
+
+// Ready отвечает 200, если все зависимости доступны, иначе 503.
+// В обоих случаях в теле отчёт по каждой зависимости.
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	report := c.Run(r.Context())
+
+	statusCode := http.StatusOK
+	if report.Status != StatusUp {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	writeReport(w, statusCode, report)
+}
+
+func writeReport(w http.ResponseWriter, statusCode int, report Report) {
+	resp, err := json.Marshal(report)
+	if err != nil {
+		http.Error(w, "can`t marshal health report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(resp)
+}
+
+// HTTPCheck считает зависимость доступной, если GET url вернул 2xx.
+func HTTPCheck(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+		}
+
+		return nil
+	}
+}
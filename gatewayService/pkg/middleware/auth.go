@@ -3,18 +3,24 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"strings"
 
+	"flight_booking_system/gatewayService/models"
 	"flight_booking_system/gatewayService/pkg/logger"
 )
 
-const sessionHeader = "Authorization"
+const (
+	sessionHeader  = "Authorization"
+	bearerPrefix   = "Bearer "
+	userNameHeader = "X-User-Name"
+)
 
 type AuthSessionsManager interface {
-	GetUser(string) (int, string, error)
+	GetUser(string) (*models.AuthUser, error)
 }
 
 type AuthContextManager interface {
-	ContextWithUserID(context.Context, int) context.Context
+	ContextWithUser(context.Context, *models.AuthUser) context.Context
 }
 
 type AuthManager struct {
@@ -25,8 +31,8 @@ type AuthManager struct {
 
 func (am *AuthManager) Auth(next http.Handler, roles ...string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token := r.Header.Get(sessionHeader)
-		if token == "" {
+		token, ok := strings.CutPrefix(r.Header.Get(sessionHeader), bearerPrefix)
+		if !ok || token == "" {
 			am.Logger.Infow("authorization",
 				"url", r.URL.Path,
 				"method", r.Method,
@@ -37,7 +43,7 @@ func (am *AuthManager) Auth(next http.Handler, roles ...string) http.Handler {
 			return
 		}
 
-		userID, userRole, err := am.SessionManager.GetUser(token)
+		user, err := am.SessionManager.GetUser(token)
 		if err != nil {
 			am.Logger.Infow("authorization",
 				"url", r.URL.Path,
@@ -53,7 +59,7 @@ func (am *AuthManager) Auth(next http.Handler, roles ...string) http.Handler {
 		if len(roles) > 0 {
 			roleMatch := false
 			for _, role := range roles {
-				if userRole == role {
+				if user.Role == role {
 					roleMatch = true
 					break
 				}
@@ -64,8 +70,8 @@ func (am *AuthManager) Auth(next http.Handler, roles ...string) http.Handler {
 					"method", r.Method,
 					"remote_addr", r.RemoteAddr,
 					"auth result", "user role doesn`t match",
-					"userID", userID,
-					"userRole", userRole)
+					"userID", user.ID,
+					"userRole", user.Role)
 				http.Error(w, "Forbidden", http.StatusForbidden)
 				return
 			}
@@ -76,10 +82,13 @@ func (am *AuthManager) Auth(next http.Handler, roles ...string) http.Handler {
 			"method", r.Method,
 			"remote_addr", r.RemoteAddr,
 			"auth result", "success",
-			"userID", userID,
-			"userRole", userRole)
+			"userID", user.ID,
+			"userRole", user.Role)
+
+		// Имя пользователя берётся только из проверенного токена
+		r.Header.Set(userNameHeader, user.Username)
 
-		ctx := am.ContextManager.ContextWithUserID(r.Context(), userID)
+		ctx := am.ContextManager.ContextWithUser(r.Context(), user)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
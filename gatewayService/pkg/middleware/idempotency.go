@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/logger"
+)
+
+const idempotencyHeader = "Idempotency-Key"
+
+type IdempotencyStore interface {
+	Begin(key string, fingerprint string, ttl time.Duration) (*models.IdempotencyRecord, bool, error)
+	Complete(record *models.IdempotencyRecord, statusCode int, contentType string, response []byte) error
+	Release(record *models.IdempotencyRecord) error
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(statusCode int) {
+	if rr.statusCode == 0 {
+		rr.statusCode = statusCode
+	}
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.statusCode == 0 {
+		rr.statusCode = http.StatusOK
+	}
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write([]byte(r.Header.Get("X-User-Name") + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Idempotency повторяет сохранённый ответ для запросов с уже известным
+// Idempotency-Key. Ответы 5xx не сохраняются, чтобы клиент мог повторить запрос.
+func Idempotency(logger logger.Logger, store IdempotencyStore, ttl time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logger.Errorw("can`t read body of request", "err:", err.Error())
+			http.Error(w, "bad data", http.StatusBadRequest)
+			return
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(r, body)
+
+		record, created, err := store.Begin(key, fingerprint, ttl)
+		if err != nil {
+			logger.Errorw("can`t begin idempotent request", "key", key, "err:", err.Error())
+			http.Error(w, "can`t check idempotency key", http.StatusInternalServerError)
+			return
+		}
+
+		if !created {
+			switch {
+			case record.Fingerprint != fingerprint:
+				logger.Infow("idempotency key reused with different request", "key", key)
+				http.Error(w, "idempotency key was already used with a different request", http.StatusUnprocessableEntity)
+			case !record.Completed():
+				logger.Infow("idempotent request is still in progress", "key", key)
+				http.Error(w, "request with this idempotency key is in progress", http.StatusConflict)
+			default:
+				logger.Infow("replaying idempotent response", "key", key)
+				if record.ContentType != "" {
+					w.Header().Set("Content-Type", record.ContentType)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(record.StatusCode)
+				_, _ = w.Write(record.Response)
+			}
+			return
+		}
+
+		defer func() {
+			if p := recover(); p != nil {
+				_ = store.Release(record)
+				panic(p)
+			}
+		}()
+
+		rec := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		if rec.statusCode == 0 || rec.statusCode >= http.StatusInternalServerError {
+			err = store.Release(record)
+		} else {
+			err = store.Complete(record, rec.statusCode, rec.Header().Get("Content-Type"), rec.body.Bytes())
+		}
+
+		if err != nil {
+			logger.Errorw("can`t store idempotent response", "key", key, "err:", err.Error())
+		}
+	})
+}
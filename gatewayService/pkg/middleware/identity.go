@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"flight_booking_system/gatewayService/pkg/logger"
+)
+
+type IdentityIssuer interface {
+	CreateIdentity(user *models.AuthUser) (string, error)
+}
+
+type IdentityContextManager interface {
+	UserFromContext(context.Context) (*models.AuthUser, error)
+}
+
+// ForwardIdentity выпускает для пользователя, прошедшего Auth, токен,
+// который клиенты сервисов передают дальше в заголовке X-User-Identity.
+func ForwardIdentity(logger logger.Logger, issuer IdentityIssuer, contextManager IdentityContextManager, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := contextManager.UserFromContext(r.Context())
+		if err != nil {
+			logger.Errorw("no authenticated user in context", "err:", err.Error())
+			http.Error(w, "no auth", http.StatusUnauthorized)
+			return
+		}
+
+		identity, err := issuer.CreateIdentity(user)
+		if err != nil {
+			logger.Errorw("can`t create identity token", "err:", err.Error())
+			http.Error(w, "can`t create identity", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := apiclient.WithIdentity(r.Context(), identity)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ServiceIdentity выпускает токен от имени самого gateway для публичных
+// маршрутов, которым нужны сервисы, требующие identity.
+func ServiceIdentity(logger logger.Logger, issuer IdentityIssuer, service *models.AuthUser, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := issuer.CreateIdentity(service)
+		if err != nil {
+			logger.Errorw("can`t create service identity token", "err:", err.Error())
+			http.Error(w, "can`t create identity", http.StatusInternalServerError)
+			return
+		}
+
+		ctx := apiclient.WithIdentity(r.Context(), identity)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -1,58 +1,115 @@
 package session
 
 import (
-	"github.com/golang-jwt/jwt/v5"
 	"time"
 
+	"flight_booking_system/gatewayService/models"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
 )
 
-var tokenKey = []byte("fvoNImvpdms023sv0s9vs")
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// TokenTypeIdentity — короткоживущий токен, которым gateway подтверждает
+	// пользователя перед остальными сервисами.
+	TokenTypeIdentity = "identity"
+)
 
 type UserClaims struct {
-	ID   int    `json:"id"`
-	Role string `json:"role"`
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
 }
 
 type Claims struct {
-	User UserClaims `json:"user"`
+	User      UserClaims `json:"user"`
+	TokenType string     `json:"tokenType"`
 	jwt.RegisteredClaims
 }
 
-type JWTSessionsManager struct{}
+type JWTSessionsManager struct {
+	TokenKey    []byte
+	AccessTTL   time.Duration
+	RefreshTTL  time.Duration
+	IdentityTTL time.Duration
+}
 
-func (jsm JWTSessionsManager) GetUser(inToken string) (int, string, error) {
-	token, err := jwt.ParseWithClaims(inToken, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return tokenKey, nil
-	})
+func New(tokenKey string) JWTSessionsManager {
+	return JWTSessionsManager{
+		TokenKey:    []byte(tokenKey),
+		AccessTTL:   15 * time.Minute,
+		RefreshTTL:  7 * 24 * time.Hour,
+		IdentityTTL: time.Minute,
+	}
+}
 
-	claims, ok := token.Claims.(*Claims)
+func (jsm JWTSessionsManager) parse(inToken string, tokenType string) (*models.AuthUser, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(inToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return jsm.TokenKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, errors.Wrap(err, "can`t parse or validate session token")
+	}
 
-	if !ok || !token.Valid {
-		return -1, "", errors.Wrapf(err, "can`t parse or validate session token \"%s\"", inToken)
+	if claims.TokenType != tokenType {
+		return nil, errors.Errorf("expected %s token, got %q", tokenType, claims.TokenType)
 	}
 
-	return claims.User.ID, claims.User.Role, nil
+	return &models.AuthUser{
+		ID:       claims.User.ID,
+		Username: claims.User.Username,
+		Role:     claims.User.Role,
+	}, nil
+}
+
+// GetUser проверяет access-токен.
+func (jsm JWTSessionsManager) GetUser(inToken string) (*models.AuthUser, error) {
+	return jsm.parse(inToken, TokenTypeAccess)
+}
+
+// GetRefreshUser проверяет refresh-токен.
+func (jsm JWTSessionsManager) GetRefreshUser(inToken string) (*models.AuthUser, error) {
+	return jsm.parse(inToken, TokenTypeRefresh)
+}
+
+func (jsm JWTSessionsManager) ttl(tokenType string) time.Duration {
+	switch tokenType {
+	case TokenTypeRefresh:
+		return jsm.RefreshTTL
+	case TokenTypeIdentity:
+		return jsm.IdentityTTL
+	default:
+		return jsm.AccessTTL
+	}
 }
 
-func (jsm JWTSessionsManager) CreateSession(id int, role string) (string, error) {
+func (jsm JWTSessionsManager) CreateSession(user *models.AuthUser, tokenType string) (string, error) {
+	now := time.Now()
 	claims := Claims{
-		UserClaims{
-			ID:   id,
-			Role: role,
+		User: UserClaims{
+			ID:       user.ID,
+			Username: user.Username,
+			Role:     user.Role,
 		},
-		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().AddDate(0, 0, 7)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		TokenType: tokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(jsm.ttl(tokenType))),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	tokenString, err := token.SignedString(tokenKey)
+	tokenString, err := token.SignedString(jsm.TokenKey)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to convert token to string")
 	}
 
 	return tokenString, nil
 }
+
+func (jsm JWTSessionsManager) CreateIdentity(user *models.AuthUser) (string, error) {
+	return jsm.CreateSession(user, TokenTypeIdentity)
+}
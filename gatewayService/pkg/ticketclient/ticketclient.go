@@ -0,0 +1,265 @@
+package ticketclient
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"flight_booking_system/gatewayService/models"
+	"flight_booking_system/gatewayService/pkg/apiclient"
+	"github.com/pkg/errors"
+)
+
+type Client struct {
+	api apiclient.Client
+}
+
+func New(baseURL string, httpClient *http.Client) *Client {
+	return &Client{
+		api: apiclient.New(baseURL, httpClient),
+	}
+}
+
+func userHeader(userName string) http.Header {
+	header := http.Header{}
+	header.Set("X-User-Name", userName)
+	return header
+}
+
+func (c *Client) ListTicketsByUser(ctx context.Context, userName string) ([]*models.TicketResponse, error) {
+	tickets := make([]*models.TicketResponse, 0)
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/tickets", userHeader(userName), nil, &tickets)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.ListTicketsByUser error")
+	}
+
+	return tickets, nil
+}
+
+// ExchangedChain — UID билетов пользователя, которые цепочкой обменов привели
+// к ticketUID, от последнего обменянного к исходному.
+func (c *Client) ExchangedChain(ctx context.Context, userName string, ticketUID string) ([]string, error) {
+	tickets, err := c.ListTicketsByUser(ctx, userName)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.ExchangedChain error")
+	}
+
+	exchangedFrom := make(map[string]string)
+	for _, t := range tickets {
+		if t.Status == models.TicketExchanged && t.ExchangedFor != nil {
+			exchangedFrom[*t.ExchangedFor] = t.TicketUID
+		}
+	}
+
+	var chain []string
+	seen := map[string]bool{ticketUID: true}
+	for uid, ok := exchangedFrom[ticketUID]; ok && !seen[uid]; uid, ok = exchangedFrom[uid] {
+		seen[uid] = true
+		chain = append(chain, uid)
+	}
+
+	return chain, nil
+}
+
+func (c *Client) GetTicket(ctx context.Context, userName string, ticketUID string) (*models.TicketResponse, error) {
+	header := userHeader(userName)
+	header.Set("X-Ticket-Uid", ticketUID)
+
+	ticket := &models.TicketResponse{}
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/ticketsByUID", header, nil, ticket)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.GetTicket error")
+	}
+
+	return ticket, nil
+}
+
+// CreateTicket создаёт билет. В ответе — билет с назначенным сервисом сроком брони.
+func (c *Client) CreateTicket(ctx context.Context, ticket models.TicketInfoRequest) (*models.TicketResponse, error) {
+	created := &models.TicketResponse{}
+	resp, err := c.api.Do(ctx, http.MethodPost, "/api/v1/tickets", nil, ticket, created)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.CreateTicket error")
+	}
+
+	if created.TicketUID == "" {
+		created.TicketUID = resp.Header.Get("X-Ticket-UID")
+	}
+	if created.TicketUID == "" {
+		return nil, errors.New("ticketclient.CreateTicket error: ticket service returned no ticket uid")
+	}
+
+	return created, nil
+}
+
+// ConfirmTicket оплачивает бронь. Истёкшая или уже оплаченная бронь —
+// ошибка apiclient.ErrConflict.
+func (c *Client) ConfirmTicket(ctx context.Context, userName string, ticketUID string) (*models.TicketResponse, error) {
+	ticket := &models.TicketResponse{}
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/tickets/"+url.PathEscape(ticketUID)+"/confirm", userHeader(userName), nil, ticket)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.ConfirmTicket error")
+	}
+
+	return ticket, nil
+}
+
+// CancelTicket отменяет билет. Уже отменённый или истёкший билет — ошибка
+// apiclient.ErrConflict.
+func (c *Client) CancelTicket(ctx context.Context, userName string, ticketUID string) error {
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/tickets/"+url.PathEscape(ticketUID)+"/cancel", userHeader(userName), nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "ticketclient.CancelTicket error")
+	}
+
+	return nil
+}
+
+func (c *Client) CheckInTicket(ctx context.Context, userName string, ticketUID string) (*models.TicketResponse, error) {
+	ticket := &models.TicketResponse{}
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/tickets/"+url.PathEscape(ticketUID)+"/check-in", userHeader(userName), nil, ticket)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.CheckInTicket error")
+	}
+
+	return ticket, nil
+}
+
+// RevertTicketStatus откатывает последний переход билета обратно в status.
+// Нужен для компенсации в сагах; ctx должен нести identity gateway с ролью
+// SERVICE.
+func (c *Client) RevertTicketStatus(ctx context.Context, userName string, ticketUID string, status string) error {
+	req := struct {
+		Status string `json:"status"`
+	}{Status: status}
+
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/tickets/"+url.PathEscape(ticketUID)+"/revert", userHeader(userName), req, nil)
+	if err != nil {
+		return errors.Wrap(err, "ticketclient.RevertTicketStatus error")
+	}
+
+	return nil
+}
+
+// ExchangeTicket помечает билет обменянным на уже купленный newTicketUID.
+func (c *Client) ExchangeTicket(ctx context.Context, userName string, ticketUID string, newTicketUID string) error {
+	req := struct {
+		NewTicketUID string `json:"newTicketUid"`
+	}{NewTicketUID: newTicketUID}
+
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/tickets/"+url.PathEscape(ticketUID)+"/exchange", userHeader(userName), req, nil)
+	if err != nil {
+		return errors.Wrap(err, "ticketclient.ExchangeTicket error")
+	}
+
+	return nil
+}
+
+// CreatePNR оформляет оплаченные билеты пассажиров под одним кодом бронирования.
+func (c *Client) CreatePNR(ctx context.Context, userName string, pnr models.PNRCreateRequest) (*models.PNRServiceResponse, error) {
+	created := &models.PNRServiceResponse{}
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/bookings", userHeader(userName), pnr, created)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.CreatePNR error")
+	}
+
+	return created, nil
+}
+
+// GetPNR ищет бронирование по коду и фамилии любого из пассажиров.
+func (c *Client) GetPNR(ctx context.Context, reference string, lastName string) (*models.PNRServiceResponse, error) {
+	query := url.Values{}
+	query.Set("lastName", lastName)
+
+	pnr := &models.PNRServiceResponse{}
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/bookings/"+url.PathEscape(reference)+"?"+query.Encode(), nil, nil, pnr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.GetPNR error")
+	}
+
+	return pnr, nil
+}
+
+// CancelPNR отменяет все действующие билеты бронирования.
+func (c *Client) CancelPNR(ctx context.Context, userName string, reference string) error {
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/bookings/"+url.PathEscape(reference)+"/cancel", userHeader(userName), nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "ticketclient.CancelPNR error")
+	}
+
+	return nil
+}
+
+// CreateItinerary оформляет оплаченные билеты всех перелётов маршрута.
+func (c *Client) CreateItinerary(ctx context.Context, userName string, itinerary models.ItineraryCreateRequest) (*models.ItineraryServiceResponse, error) {
+	created := &models.ItineraryServiceResponse{}
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/itineraries", userHeader(userName), itinerary, created)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.CreateItinerary error")
+	}
+
+	return created, nil
+}
+
+func (c *Client) GetItinerary(ctx context.Context, userName string, itineraryUID string) (*models.ItineraryServiceResponse, error) {
+	itinerary := &models.ItineraryServiceResponse{}
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/itineraries/"+url.PathEscape(itineraryUID), userHeader(userName), nil, itinerary)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.GetItinerary error")
+	}
+
+	return itinerary, nil
+}
+
+// CancelItinerary отменяет все перелёты маршрута. Если хоть один билет уже
+// нельзя отменить — ошибка apiclient.ErrConflict.
+func (c *Client) CancelItinerary(ctx context.Context, userName string, itineraryUID string) error {
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/itineraries/"+url.PathEscape(itineraryUID)+"/cancel", userHeader(userName), nil, nil)
+	if err != nil {
+		return errors.Wrap(err, "ticketclient.CancelItinerary error")
+	}
+
+	return nil
+}
+
+// ListFlightTickets возвращает все билеты рейса с владельцами. Только для
+// администратора.
+func (c *Client) ListFlightTickets(ctx context.Context, flightNumber string) ([]*models.FlightTicketResponse, error) {
+	tickets := make([]*models.FlightTicketResponse, 0)
+	_, err := c.api.Do(ctx, http.MethodGet, "/api/v1/flights/"+url.PathEscape(flightNumber)+"/tickets", nil, nil, &tickets)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketclient.ListFlightTickets error")
+	}
+
+	return tickets, nil
+}
+
+// CancelTicketForFlight отменяет билет пользователя из-за отмены рейса, в том
+// числе после регистрации. Уже отменённый билет — не ошибка. Только для
+// администратора.
+func (c *Client) CancelTicketForFlight(ctx context.Context, userName string, ticketUID string) error {
+	req := struct {
+		Username string `json:"username"`
+	}{Username: userName}
+
+	_, err := c.api.Do(ctx, http.MethodPost, "/api/v1/tickets/"+url.PathEscape(ticketUID)+"/flight-cancel", nil, req, nil)
+	if err != nil {
+		return errors.Wrap(err, "ticketclient.CancelTicketForFlight error")
+	}
+
+	return nil
+}
+
+func (c *Client) UpdateTicketSeat(ctx context.Context, ticketUID string, seat string) error {
+	ticket := models.TicketResponse{
+		TicketUID: ticketUID,
+		Seat:      seat,
+	}
+
+	_, err := c.api.Do(ctx, http.MethodPatch, "/api/v1/tickets", nil, ticket, nil)
+	if err != nil {
+		return errors.Wrap(err, "ticketclient.UpdateTicketSeat error")
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package ticketclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"flight_booking_system/gatewayService/models"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+)
+
+type TicketClientTestSuite struct {
+	suite.Suite
+}
+
+func TestTicketClientSuite(t *testing.T) {
+	suite.RunSuite(t, new(TicketClientTestSuite))
+}
+
+func exchanged(uid string, exchangedFor string) *models.TicketResponse {
+	return &models.TicketResponse{TicketUID: uid, Status: models.TicketExchanged, ExchangedFor: &exchangedFor}
+}
+
+// serve отдаёт tickets на запрос списка билетов пользователя.
+func serve(t provider.T, tickets ...*models.TicketResponse) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Assert().Equal("/api/v1/tickets", r.URL.Path)
+		t.Assert().Equal("username", r.Header.Get("X-User-Name"))
+		_ = json.NewEncoder(w).Encode(tickets)
+	}))
+	t.Cleanup(server.Close)
+
+	return New(server.URL, server.Client())
+}
+
+func (s *TicketClientTestSuite) TestExchangedChain(t provider.T) {
+	cases := map[string]struct {
+		Tickets []*models.TicketResponse
+		Chain   []string
+	}{
+		"no exchanges": {
+			Tickets: []*models.TicketResponse{{TicketUID: "current", Status: models.TicketPaid}},
+			Chain:   nil,
+		},
+		"chain": {
+			Tickets: []*models.TicketResponse{
+				exchanged("first", "second"),
+				exchanged("second", "current"),
+				{TicketUID: "current", Status: models.TicketPaid},
+				exchanged("other", "another"),
+			},
+			Chain: []string{"second", "first"},
+		},
+		"only exchanged tickets": {
+			Tickets: []*models.TicketResponse{
+				{TicketUID: "first", Status: models.TicketCanceled, ExchangedFor: stringPtr("current")},
+			},
+			Chain: nil,
+		},
+		"cycle": {
+			Tickets: []*models.TicketResponse{
+				exchanged("first", "current"),
+				exchanged("current", "first"),
+			},
+			Chain: []string{"first"},
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			client := serve(t, test.Tickets...)
+
+			chain, err := client.ExchangedChain(context.Background(), "username", "current")
+
+			t.Require().NoError(err)
+			t.Assert().Equal(test.Chain, chain)
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
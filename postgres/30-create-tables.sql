@@ -1,5 +1,23 @@
 \connect tickets program
 
+-- Бронирование (PNR): билеты нескольких пассажиров под одним кодом
+CREATE TABLE IF NOT EXISTS booking
+(
+    id         SERIAL PRIMARY KEY,
+    reference  CHAR(6) UNIQUE NOT NULL,
+    username   VARCHAR(80)    NOT NULL,
+    created_at TIMESTAMP      NOT NULL DEFAULT now()
+);
+
+-- Маршрут из нескольких перелётов (туда-обратно или составной), купленный одной покупкой
+CREATE TABLE IF NOT EXISTS itinerary
+(
+    id            SERIAL PRIMARY KEY,
+    itinerary_uid uuid UNIQUE NOT NULL,
+    username      VARCHAR(80) NOT NULL,
+    created_at    TIMESTAMP   NOT NULL DEFAULT now()
+);
+
 CREATE TABLE IF NOT EXISTS ticket
 (
     id            SERIAL PRIMARY KEY,
@@ -8,9 +26,40 @@ CREATE TABLE IF NOT EXISTS ticket
     flight_number VARCHAR(20) NOT NULL,
     price         INT         NOT NULL,
     status        VARCHAR(20) NOT NULL
-        CHECK (status IN ('PAID', 'CANCELED'))
+        CHECK (status IN ('HELD', 'PAID', 'CANCELED', 'EXPIRED', 'CHECKED_IN', 'EXCHANGED')),
+    seat          VARCHAR(4)  NOT NULL DEFAULT '',
+    fare_class    VARCHAR(20) NOT NULL DEFAULT 'ECONOMY'
+        CHECK (fare_class IN ('ECONOMY', 'COMFORT', 'BUSINESS')),
+    -- до какого момента действует бронь; учитывается только в статусе HELD
+    hold_expires_at TIMESTAMP,
+    -- на какой билет обменян этот; задаётся только в статусе EXCHANGED
+    exchanged_for   uuid REFERENCES ticket (ticket_uid),
+    booking_reference         CHAR(6) REFERENCES booking (reference),
+    -- данные пассажира заполняются у билетов из бронирования
+    passenger_first_name      VARCHAR(80) NOT NULL DEFAULT '',
+    passenger_last_name       VARCHAR(80) NOT NULL DEFAULT '',
+    passenger_document_number VARCHAR(20) NOT NULL DEFAULT '',
+    passenger_birth_date      DATE,
+    itinerary_uid             uuid REFERENCES itinerary (itinerary_uid)
 );
 
+CREATE INDEX ticket_hold_expires_at_idx ON ticket (hold_expires_at) WHERE status = 'HELD';
+CREATE INDEX ticket_booking_reference_idx ON ticket (booking_reference);
+CREATE INDEX ticket_itinerary_uid_idx ON ticket (itinerary_uid);
+
+-- Все переходы статуса билета: кто и когда его менял
+CREATE TABLE IF NOT EXISTS ticket_status_history
+(
+    id          SERIAL PRIMARY KEY,
+    ticket_uid  uuid        NOT NULL REFERENCES ticket (ticket_uid) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status   VARCHAR(20) NOT NULL,
+    actor       VARCHAR(80) NOT NULL,
+    changed_at  TIMESTAMP   NOT NULL DEFAULT now()
+);
+
+CREATE INDEX ticket_status_history_ticket_uid_idx ON ticket_status_history (ticket_uid, id);
+
 \connect flights program
 CREATE TABLE airport
 (
@@ -22,6 +71,42 @@ CREATE TABLE airport
 
 INSERT INTO airport VALUES (1, 'Шереметьево', 'Москва', 'Россия');
 INSERT INTO airport VALUES (2, 'Пулково', 'Санкт-Петербург', 'Россия');
+-- Начальные данные вставлены с явными id: сдвигаем последовательность, иначе
+-- первая вставка без id упрётся в занятый ключ
+SELECT setval(pg_get_serial_sequence('airport', 'id'), (SELECT max(id) FROM airport));
+
+-- Схема салона для типа самолёта
+CREATE TABLE aircraft_type
+(
+    id   SERIAL PRIMARY KEY,
+    code VARCHAR(20)  NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE aircraft_seat
+(
+    aircraft_type_id INT         NOT NULL REFERENCES aircraft_type (id) ON DELETE CASCADE,
+    seat_number      VARCHAR(4)  NOT NULL,
+    seat_row         INT         NOT NULL CHECK (seat_row > 0),
+    letter           CHAR(1)     NOT NULL,
+    cabin_class      VARCHAR(20) NOT NULL
+        CHECK (cabin_class IN ('ECONOMY', 'BUSINESS', 'FIRST')),
+    exit_row         BOOLEAN     NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (aircraft_type_id, seat_number)
+);
+
+INSERT INTO aircraft_type VALUES (1, 'A320', 'Airbus A320');
+SELECT setval(pg_get_serial_sequence('aircraft_type', 'id'), (SELECT max(id) FROM aircraft_type));
+
+-- Бизнес: ряды 1-3 по четыре кресла, эконом: ряды 4-30 по шесть, 12 и 13 — у аварийных выходов
+INSERT INTO aircraft_seat (aircraft_type_id, seat_number, seat_row, letter, cabin_class, exit_row)
+SELECT 1, r || l, r, l, 'BUSINESS', FALSE
+FROM generate_series(1, 3) AS r,
+     unnest(ARRAY ['A', 'C', 'D', 'F']) AS l;
+INSERT INTO aircraft_seat (aircraft_type_id, seat_number, seat_row, letter, cabin_class, exit_row)
+SELECT 1, r || l, r, l, 'ECONOMY', r IN (12, 13)
+FROM generate_series(4, 30) AS r,
+     unnest(ARRAY ['A', 'B', 'C', 'D', 'E', 'F']) AS l;
 
 CREATE TABLE flight
 (
@@ -30,10 +115,63 @@ CREATE TABLE flight
     datetime        TIMESTAMP WITH TIME ZONE NOT NULL,
     from_airport_id INT REFERENCES airport (id),
     to_airport_id   INT REFERENCES airport (id),
-    price           INT                      NOT NULL
+    price           INT                      NOT NULL,
+    capacity        INT                      NOT NULL DEFAULT 100
+        CHECK (capacity > 0),
+    seats_reserved  INT                      NOT NULL DEFAULT 0
+        CHECK (seats_reserved >= 0),
+    aircraft_type_id INT REFERENCES aircraft_type (id),
+    -- время в пути: по нему считаются прилёт и стыковки
+    duration_minutes INT                      NOT NULL DEFAULT 90
+        CHECK (duration_minutes > 0),
+    -- оперативный статус: задержки, отмены и выход на посадку
+    status           VARCHAR(20)              NOT NULL DEFAULT 'SCHEDULED'
+        CHECK (status IN ('SCHEDULED', 'BOARDING', 'DEPARTED', 'DELAYED', 'CANCELLED')),
+    estimated_departure TIMESTAMP WITH TIME ZONE,
+    estimated_arrival   TIMESTAMP WITH TIME ZONE,
+    gate             VARCHAR(10)              NOT NULL DEFAULT '',
+    CHECK (seats_reserved <= capacity)
 );
 
-INSERT INTO flight VALUES (1, 'AFL031', '2021-10-08 20:00', 2, 1, 1500);
+-- Вместимость рейса — сумма квот классов: 12 бизнес-кресел A320 и 162
+-- кресла эконом-салона на эконом и комфорт
+INSERT INTO flight VALUES (1, 'AFL031', '2021-10-08 20:00', 2, 1, 1500, 174, 0, 1);
+SELECT setval(pg_get_serial_sequence('flight', 'id'), (SELECT max(id) FROM flight));
+
+-- Класс обслуживания на рейсе: своя цена, число мест и правила возврата/обмена
+CREATE TABLE flight_fare
+(
+    flight_id      INT         NOT NULL REFERENCES flight (id) ON DELETE CASCADE,
+    fare_class     VARCHAR(20) NOT NULL
+        CHECK (fare_class IN ('ECONOMY', 'COMFORT', 'BUSINESS')),
+    price          INT         NOT NULL CHECK (price > 0),
+    capacity       INT         NOT NULL CHECK (capacity > 0),
+    seats_reserved INT         NOT NULL DEFAULT 0
+        CHECK (seats_reserved >= 0),
+    refundable     BOOLEAN     NOT NULL DEFAULT TRUE,
+    exchangeable   BOOLEAN     NOT NULL DEFAULT FALSE,
+    exchange_fee   INT         NOT NULL DEFAULT 0 CHECK (exchange_fee >= 0),
+    PRIMARY KEY (flight_id, fare_class),
+    CHECK (seats_reserved <= capacity)
+);
+
+INSERT INTO flight_fare (flight_id, fare_class, price, capacity, refundable, exchangeable, exchange_fee)
+VALUES (1, 'ECONOMY', 1500, 132, TRUE, FALSE, 0),
+       (1, 'COMFORT', 2500, 30, TRUE, TRUE, 500),
+       (1, 'BUSINESS', 4500, 12, TRUE, TRUE, 0);
+
+-- Место на рейсе, занятое билетом. По ticket_uid резервирование и его
+-- отмена идемпотентны.
+CREATE TABLE seat_reservation
+(
+    ticket_uid  uuid PRIMARY KEY,
+    flight_id   INT                      NOT NULL REFERENCES flight (id) ON DELETE CASCADE,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    -- Выбранное кресло; уникальность не даёт двум билетам занять одно место
+    seat_number VARCHAR(4),
+    fare_class  VARCHAR(20)              NOT NULL DEFAULT 'ECONOMY',
+    UNIQUE (flight_id, seat_number)
+);
 
 \connect privileges program
 CREATE TABLE privilege
@@ -46,6 +184,7 @@ CREATE TABLE privilege
 );
 
 INSERT INTO privilege VALUES (1, 'Test Max', 'GOLD', 1500);
+SELECT setval(pg_get_serial_sequence('privilege', 'id'), (SELECT max(id) FROM privilege));
 
 CREATE TABLE privilege_history
 (
@@ -55,5 +194,34 @@ CREATE TABLE privilege_history
     datetime       TIMESTAMP   NOT NULL,
     balance_diff   INT         NOT NULL,
     operation_type VARCHAR(20) NOT NULL
-        CHECK (operation_type IN ('FILL_IN_BALANCE', 'DEBIT_THE_ACCOUNT'))
+        CHECK (operation_type IN ('FILL_IN_BALANCE', 'DEBIT_THE_ACCOUNT')),
+    -- reversal отмечает откат операций по билету: билет откатывается один раз
+    reversal       BOOLEAN     NOT NULL DEFAULT FALSE
 );
+
+\connect gateway program
+CREATE TABLE idempotency_key
+(
+    key          VARCHAR(255) PRIMARY KEY,
+    fingerprint  VARCHAR(64)              NOT NULL,
+    status_code  INT                      NOT NULL DEFAULT 0,
+    content_type VARCHAR(255)             NOT NULL DEFAULT '',
+    response     BYTEA,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    expires_at   TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idempotency_key_expires_at_idx ON idempotency_key (expires_at);
+
+CREATE TABLE users
+(
+    id            SERIAL PRIMARY KEY,
+    username      VARCHAR(80)  NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    role          VARCHAR(20)  NOT NULL DEFAULT 'USER'
+        CHECK (role IN ('USER', 'ADMIN'))
+);
+
+-- Пароль: test
+INSERT INTO users (username, password_hash, role)
+VALUES ('Test Max', '$2a$10$kF4LzCDwKMy8efMo3Z.CfeojsKePs11v0p7rLOqwtumr2BT4kiKXS', 'USER');
@@ -1,14 +1,29 @@
 package main
 
 import (
+	"context"
 	"flight_booking_system/ticketService/cmd/server"
+	bookingDel "flight_booking_system/ticketService/internal/booking/delivery"
+	pgBooking "flight_booking_system/ticketService/internal/booking/repository/postgres"
+	bookingUseCase "flight_booking_system/ticketService/internal/booking/usecase"
+	itineraryDel "flight_booking_system/ticketService/internal/itinerary/delivery"
+	pgItinerary "flight_booking_system/ticketService/internal/itinerary/repository/postgres"
+	itineraryUseCase "flight_booking_system/ticketService/internal/itinerary/usecase"
 	ticketDel "flight_booking_system/ticketService/internal/ticket/delivery"
 	pgTicket "flight_booking_system/ticketService/internal/ticket/repository/postgres"
+	"flight_booking_system/ticketService/internal/ticket/sweeper"
 	ticketUseCase "flight_booking_system/ticketService/internal/ticket/usecase"
+	"flight_booking_system/ticketService/models"
+	"flight_booking_system/ticketService/pkg/config"
+	appContext "flight_booking_system/ticketService/pkg/context"
+	"flight_booking_system/ticketService/pkg/flightclient"
+	"flight_booking_system/ticketService/pkg/health"
 	"flight_booking_system/ticketService/pkg/middleware"
-	"fmt"
+	"flight_booking_system/ticketService/pkg/session"
 	"log"
 	"net/http"
+	"os"
+	"time"
 
 	_ "github.com/lib/pq"
 	"go.uber.org/zap"
@@ -16,22 +31,53 @@ import (
 	"gorm.io/gorm"
 )
 
-var prodCfgPg = postgres.Config{DSN: "host=postgres user=program password=test dbname=tickets port=5432"}
-
 func main() {
 	zapLogger := zap.Must(zap.NewDevelopment())
 	logger := zapLogger.Sugar()
 
-	db, err := gorm.Open(postgres.New(prodCfgPg), &gorm.Config{})
+	cfg, err := config.Load(os.Getenv("CONFIG_PATH"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: cfg.Postgres.DSN}), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	// serviceName — от чьего имени Ticket Service ходит в другие сервисы
+	const serviceName = "ticket-service"
+
+	sessions := session.New(cfg.Session.TokenKey)
+	ticketUC := ticketUseCase.New(pgTicket.New(logger, db), cfg.Hold.TTL)
+
 	ticketHandler := ticketDel.TicketHandler{
-		TicketUseCase: ticketUseCase.New(pgTicket.New(logger, db)),
+		TicketUseCase: ticketUC,
 		Logger:        logger,
 	}
 
+	bookingHandler := bookingDel.BookingHandler{
+		BookingUseCase: bookingUseCase.New(pgBooking.New(logger, db), ticketUC),
+		Logger:         logger,
+	}
+
+	itineraryHandler := itineraryDel.ItineraryHandler{
+		ItineraryUseCase: itineraryUseCase.New(pgItinerary.New(logger, db), ticketUC),
+		Logger:           logger,
+	}
+
+	flightClient := flightclient.New(cfg.Services.FlightHost, &http.Client{Timeout: 5 * time.Second}, func() (string, error) {
+		return sessions.CreateIdentity(serviceName, time.Minute)
+	})
+	holdSweeper := sweeper.New(logger, ticketUC, flightClient, cfg.Hold.SweepInterval, cfg.Hold.BatchSize)
+
+	// Операции администратора и gateway проверяют роль из identity-токена
+	authManager := &middleware.AuthManager{
+		SessionManager: sessions,
+		Logger:         logger,
+		ContextManager: appContext.Manager{},
+	}
+
 	r := http.NewServeMux()
 
 	r.Handle("GET /api/v1/tickets/{ticketId}", http.HandlerFunc(ticketHandler.Get))
@@ -40,21 +86,74 @@ func main() {
 	r.Handle("PATCH /api/v1/tickets", http.HandlerFunc(ticketHandler.Update))
 	r.Handle("DELETE /api/v1/tickets/{ticketId}", http.HandlerFunc(ticketHandler.Delete))
 	r.Handle("GET /api/v1/ticketsByUID", http.HandlerFunc(ticketHandler.GetByUID))
+	r.Handle("POST /api/v1/tickets/{ticketUid}/confirm", http.HandlerFunc(ticketHandler.Confirm))
+	r.Handle("POST /api/v1/tickets/{ticketUid}/cancel", http.HandlerFunc(ticketHandler.Cancel))
+	r.Handle("POST /api/v1/tickets/{ticketUid}/check-in", http.HandlerFunc(ticketHandler.CheckIn))
+	// Откат статуса — компенсация саги gateway, пользователю он недоступен
+	r.Handle("POST /api/v1/tickets/{ticketUid}/revert", authManager.Auth(http.HandlerFunc(ticketHandler.Revert), models.RoleService))
+	r.Handle("POST /api/v1/tickets/{ticketUid}/exchange", http.HandlerFunc(ticketHandler.Exchange))
+	r.Handle("GET /api/v1/tickets/{ticketUid}/history", http.HandlerFunc(ticketHandler.History))
+	r.Handle("GET /api/v1/flights/{flightNumber}/tickets", authManager.Auth(http.HandlerFunc(ticketHandler.GetByFlight), models.RoleAdmin))
+	r.Handle("POST /api/v1/tickets/{ticketUid}/flight-cancel", authManager.Auth(http.HandlerFunc(ticketHandler.CancelForFlight), models.RoleAdmin))
+
+	r.Handle("POST /api/v1/bookings", http.HandlerFunc(bookingHandler.Create))
+	r.Handle("GET /api/v1/bookings/{reference}", http.HandlerFunc(bookingHandler.Find))
+	r.Handle("POST /api/v1/bookings/{reference}/cancel", http.HandlerFunc(bookingHandler.Cancel))
 
-	r.Handle("GET /manage/health", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	}))
+	r.Handle("POST /api/v1/itineraries", http.HandlerFunc(itineraryHandler.Create))
+	r.Handle("GET /api/v1/itineraries/{itineraryUid}", http.HandlerFunc(itineraryHandler.Get))
+	r.Handle("POST /api/v1/itineraries/{itineraryUid}/cancel", http.HandlerFunc(itineraryHandler.Cancel))
 
-	router := middleware.AccessLog(logger, r)
+	router := middleware.Identity(logger, sessions, cfg.Session.RequireIdentity, r)
+	router = middleware.AccessLog(logger, router)
 	router = middleware.Panic(logger, router)
 
-	s := server.NewServer(router)
+	s := server.NewServer(cfg.Server, router)
+
+	checker := health.New(health.DefaultTimeout).
+		Add("server", s.Check).
+		Add("postgres", func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		})
+
+	r.Handle("GET /manage/health", http.HandlerFunc(s.Health))
+	r.Handle("GET /manage/health/live", http.HandlerFunc(checker.Live))
+	r.Handle("GET /manage/health/ready", http.HandlerFunc(checker.Ready))
+
+	s.OnShutdown("logger sync", func(ctx context.Context) error {
+		return zapLogger.Sync()
+	})
+	s.OnShutdown("postgres", func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+
+	sweepCtx, stopSweeper := context.WithCancel(context.Background())
+	sweeperDone := make(chan struct{})
+	go func() {
+		defer close(sweeperDone)
+		holdSweeper.Run(sweepCtx)
+	}()
+
+	// Хуки идут в обратном порядке: sweeper остановится раньше, чем закроется база
+	s.OnShutdown("hold sweeper", func(ctx context.Context) error {
+		stopSweeper()
+		select {
+		case <-sweeperDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
 	if err := s.Start(); err != nil {
 		logger.Fatal(err)
 	}
-
-	err = zapLogger.Sync()
-	if err != nil {
-		fmt.Println(err)
-	}
 }
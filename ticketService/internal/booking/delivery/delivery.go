@@ -0,0 +1,112 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+
+	bookingRep "flight_booking_system/ticketService/internal/booking/repository"
+	bookingUseCase "flight_booking_system/ticketService/internal/booking/usecase"
+	ticketUseCase "flight_booking_system/ticketService/internal/ticket/usecase"
+	"flight_booking_system/ticketService/models"
+	"flight_booking_system/ticketService/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+type BookingHandler struct {
+	BookingUseCase bookingUseCase.BookingUseCaseI
+	Logger         logger.Logger
+}
+
+func (bh *BookingHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	bh.Logger.Infow(msg,
+		"err:", err.Error())
+
+	switch {
+	case errors.Is(err, bookingUseCase.ErrInvalidBooking):
+		http.Error(w, "invalid booking", http.StatusBadRequest)
+	case errors.Is(err, bookingRep.ErrBookingNotFound):
+		http.Error(w, "booking not found", http.StatusNotFound)
+	case errors.Is(err, ticketUseCase.ErrIllegalTransition):
+		http.Error(w, "illegal ticket status transition", http.StatusConflict)
+	default:
+		http.Error(w, msg, http.StatusInternalServerError)
+	}
+}
+
+func (bh *BookingHandler) writeBooking(w http.ResponseWriter, statusCode int, booking *models.Booking) {
+	resp, err := json.Marshal(models.BookingToDTO(*booking))
+	if err != nil {
+		bh.Logger.Errorw("can`t marshal bookingDTO",
+			"err:", err.Error())
+		http.Error(w, "can`t make bookingDTO", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		bh.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		return
+	}
+}
+
+// Create оформляет бронирование с билетами на нескольких пассажиров.
+func (bh *BookingHandler) Create(w http.ResponseWriter, r *http.Request) {
+	booking := models.Booking{}
+
+	err := json.NewDecoder(r.Body).Decode(&booking)
+	if err != nil {
+		bh.Logger.Infow("can`t unmarshal booking",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	booking.Username = r.Header.Get("X-User-Name")
+	if booking.Username == "" {
+		bh.Logger.Infow("no user for booking")
+		http.Error(w, "no user", http.StatusUnauthorized)
+		return
+	}
+
+	err = bh.BookingUseCase.Create(&booking)
+	if err != nil {
+		bh.writeError(w, err, "can`t create booking")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/bookings/"+booking.Reference)
+	bh.writeBooking(w, http.StatusCreated, &booking)
+}
+
+// Find ищет бронирование по коду и фамилии пассажира, вход не нужен.
+func (bh *BookingHandler) Find(w http.ResponseWriter, r *http.Request) {
+	lastName := r.URL.Query().Get("lastName")
+	if lastName == "" {
+		bh.Logger.Infow("no last name in booking lookup")
+		http.Error(w, "lastName is required", http.StatusBadRequest)
+		return
+	}
+
+	booking, err := bh.BookingUseCase.Find(r.PathValue("reference"), lastName)
+	if err != nil {
+		bh.writeError(w, err, "can`t find booking")
+		return
+	}
+
+	bh.writeBooking(w, http.StatusOK, booking)
+}
+
+// Cancel отменяет все действующие билеты бронирования.
+func (bh *BookingHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	booking, err := bh.BookingUseCase.Cancel(r.PathValue("reference"), r.Header.Get("X-User-Name"))
+	if err != nil {
+		bh.writeError(w, err, "can`t cancel booking")
+		return
+	}
+
+	bh.writeBooking(w, http.StatusOK, booking)
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"time"
+
+	"flight_booking_system/ticketService/internal/booking/repository"
+	"flight_booking_system/ticketService/models"
+	"flight_booking_system/ticketService/pkg/logger"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type pgBookingRepo struct {
+	Logger logger.Logger
+	DB     *gorm.DB
+}
+
+func New(logger logger.Logger, db *gorm.DB) repository.BookingRepositoryI {
+	return &pgBookingRepo{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// Create в одной транзакции создаёт бронирование, его билеты и записи о
+// начальном статусе билетов.
+func (pr *pgBookingRepo) Create(b *models.Booking) error {
+	err := pr.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Omit("Tickets").Create(b)
+		if errors.Is(res.Error, gorm.ErrDuplicatedKey) {
+			return repository.ErrReferenceTaken
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+
+		for i := range b.Tickets {
+			ticket := &b.Tickets[i]
+			ticket.BookingReference = &b.Reference
+
+			err := tx.Create(ticket).Error
+			if err != nil {
+				return err
+			}
+
+			err = tx.Create(&models.TicketStatusChange{
+				TicketUID: ticket.TicketUID,
+				ToStatus:  ticket.Status,
+				Actor:     b.Username,
+				ChangedAt: time.Now(),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "pgBookingRepo.Create error")
+	}
+
+	return nil
+}
+
+func (pr *pgBookingRepo) GetByReference(reference string) (*models.Booking, error) {
+	var b models.Booking
+	tx := pr.DB.Preload("Tickets", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("reference = ?", reference).Limit(1).Find(&b)
+
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgBookingRepo.GetByReference error")
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.Wrap(repository.ErrBookingNotFound, "pgBookingRepo.GetByReference error")
+	}
+
+	return &b, nil
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"database/sql"
+	bookingRep "flight_booking_system/ticketService/internal/booking/repository"
+	"flight_booking_system/ticketService/models"
+	"flight_booking_system/ticketService/pkg/logger"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type BookingRepoTestSuite struct {
+	suite.Suite
+	db     *sql.DB
+	gormDB *gorm.DB
+	mock   sqlmock.Sqlmock
+	repo   bookingRep.BookingRepositoryI
+}
+
+func TestBookingRepoSuite(t *testing.T) {
+	suite.RunSuite(t, new(BookingRepoTestSuite))
+}
+
+func (s *BookingRepoTestSuite) BeforeEach(t provider.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("error while creating sql mock")
+	}
+
+	dialector := postgres.New(postgres.Config{
+		DSN:                  "sqlmock_db_0",
+		DriverName:           "postgres",
+		Conn:                 db,
+		PreferSimpleProtocol: true,
+	})
+	gormDB, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatal("error gorm open")
+	}
+
+	var logger logger.Logger
+
+	s.db = db
+	s.gormDB = gormDB
+	s.mock = mock
+
+	s.repo = New(logger, gormDB)
+}
+
+func (s *BookingRepoTestSuite) AfterEach(t provider.T) {
+	err := s.mock.ExpectationsWereMet()
+	t.Assert().NoError(err)
+	s.db.Close()
+}
+
+func (s *BookingRepoTestSuite) TestGetByReference(t provider.T) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "booking" WHERE reference = $1 LIMIT $2`)).
+		WithArgs("ABC234", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "reference", "username"}).
+			AddRow(1, "ABC234", "username"))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "ticket" WHERE "ticket"."booking_reference" = $1 ORDER BY id`)).
+		WithArgs("ABC234").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ticket_uid", "booking_reference", "passenger_last_name"}).
+			AddRow(1, "uid-1", "ABC234", "Petrov").
+			AddRow(2, "uid-2", "ABC234", "Petrova"))
+
+	booking, err := s.repo.GetByReference("ABC234")
+	t.Assert().NoError(err)
+	t.Require().Len(booking.Tickets, 2)
+	t.Assert().Equal("Petrova", booking.Tickets[1].Passenger.LastName)
+}
+
+func (s *BookingRepoTestSuite) TestGetByReferenceNotFound(t provider.T) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "booking" WHERE reference = $1 LIMIT $2`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := s.repo.GetByReference("ABC234")
+	t.Assert().ErrorIs(err, bookingRep.ErrBookingNotFound)
+}
+
+func (s *BookingRepoTestSuite) TestCreateReferenceTaken(t provider.T) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "booking" ("reference","username","created_at") VALUES ($1,$2,$3) RETURNING "id"`)).
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	s.mock.ExpectRollback()
+
+	err := s.repo.Create(&models.Booking{
+		Reference: "ABC234",
+		Username:  "username",
+		CreatedAt: time.Now(),
+		Tickets:   []models.Ticket{{TicketUID: "uid-1"}},
+	})
+	t.Assert().ErrorIs(err, bookingRep.ErrReferenceTaken)
+}
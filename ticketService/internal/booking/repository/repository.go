@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"flight_booking_system/ticketService/models"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrBookingNotFound — бронирования с таким кодом нет.
+	ErrBookingNotFound = errors.New("booking not found")
+	// ErrReferenceTaken — код бронирования уже выдан другому бронированию.
+	ErrReferenceTaken = errors.New("booking reference is already taken")
+)
+
+type BookingRepositoryI interface {
+	// Create сохраняет бронирование вместе с его билетами.
+	Create(b *models.Booking) error
+	GetByReference(reference string) (*models.Booking, error)
+}
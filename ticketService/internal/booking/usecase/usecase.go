@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"time"
+
+	bookingRep "flight_booking_system/ticketService/internal/booking/repository"
+	ticketUseCase "flight_booking_system/ticketService/internal/ticket/usecase"
+	"flight_booking_system/ticketService/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidBooking — в бронировании нет билетов или не хватает данных пассажира.
+var ErrInvalidBooking = errors.New("invalid booking")
+
+const (
+	// MaxPassengers — сколько пассажиров можно оформить в одном бронировании.
+	MaxPassengers = 9
+
+	// В коде бронирования нет похожих друг на друга символов: 0/O, 1/I
+	referenceAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	referenceLength   = 6
+	// referenceAttempts — сколько раз выдать новый код, если сгенерированный занят
+	referenceAttempts = 5
+)
+
+type BookingUseCaseI interface {
+	Create(b *models.Booking) error
+	Find(reference string, lastName string) (*models.Booking, error)
+	Cancel(reference string, userName string) (*models.Booking, error)
+}
+
+type bookingUseCase struct {
+	bookingRepository bookingRep.BookingRepositoryI
+	ticketUseCase     ticketUseCase.TicketUseCaseI
+	newReference      func() (string, error)
+}
+
+func New(bRep bookingRep.BookingRepositoryI, tUC ticketUseCase.TicketUseCaseI) BookingUseCaseI {
+	return &bookingUseCase{
+		bookingRepository: bRep,
+		ticketUseCase:     tUC,
+		newReference:      newReference,
+	}
+}
+
+func newReference() (string, error) {
+	reference := make([]byte, referenceLength)
+	for i := range reference {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(referenceAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		reference[i] = referenceAlphabet[n.Int64()]
+	}
+
+	return string(reference), nil
+}
+
+func normalizeReference(reference string) string {
+	return strings.ToUpper(strings.TrimSpace(reference))
+}
+
+func validatePassenger(p models.Passenger) error {
+	switch {
+	case strings.TrimSpace(p.FirstName) == "":
+		return errors.Wrap(ErrInvalidBooking, "passenger first name is required")
+	case strings.TrimSpace(p.LastName) == "":
+		return errors.Wrap(ErrInvalidBooking, "passenger last name is required")
+	case strings.TrimSpace(p.DocumentNumber) == "":
+		return errors.Wrap(ErrInvalidBooking, "passenger document number is required")
+	case p.BirthDate == nil:
+		return errors.Wrap(ErrInvalidBooking, "passenger birth date is required")
+	case !p.BirthDate.Before(time.Now()):
+		return errors.Wrap(ErrInvalidBooking, "passenger birth date must be in the past")
+	}
+
+	return nil
+}
+
+// Create оформляет оплаченные билеты всех пассажиров под новым кодом
+// бронирования. Один документ нельзя указать дважды.
+func (pUC *bookingUseCase) Create(b *models.Booking) error {
+	if len(b.Tickets) == 0 || len(b.Tickets) > MaxPassengers {
+		return errors.Wrapf(ErrInvalidBooking, "booking must have from 1 to %d passengers", MaxPassengers)
+	}
+
+	documents := make(map[string]bool, len(b.Tickets))
+	for i := range b.Tickets {
+		ticket := &b.Tickets[i]
+
+		err := validatePassenger(ticket.Passenger)
+		if err != nil {
+			return err
+		}
+		if ticket.FlightNumber == "" || ticket.Price <= 0 {
+			return errors.Wrap(ErrInvalidBooking, "ticket flight number and price are required")
+		}
+
+		document := strings.TrimSpace(ticket.Passenger.DocumentNumber)
+		if documents[document] {
+			return errors.Wrapf(ErrInvalidBooking, "document %s is used twice", document)
+		}
+		documents[document] = true
+
+		if ticket.TicketUID == "" {
+			ticket.TicketUID = uuid.New().String()
+		}
+		if ticket.FareClass == "" {
+			ticket.FareClass = models.FareEconomy
+		}
+		ticket.Username = b.Username
+		ticket.Status = models.StatusPaid
+		ticket.HoldExpiresAt = nil
+		ticket.ExchangedFor = nil
+		ticket.ItineraryUID = nil
+	}
+
+	b.CreatedAt = time.Now()
+
+	var err error
+	for attempt := 0; attempt < referenceAttempts; attempt++ {
+		b.Reference, err = pUC.newReference()
+		if err != nil {
+			return errors.Wrap(err, "bookingUseCase.Create error")
+		}
+
+		err = pUC.bookingRepository.Create(b)
+		if !errors.Is(err, bookingRep.ErrReferenceTaken) {
+			break
+		}
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "bookingUseCase.Create error")
+	}
+
+	return nil
+}
+
+// Find ищет бронирование по коду и фамилии любого из пассажиров. Если фамилия
+// не подошла, бронирование считается ненайденным.
+func (pUC *bookingUseCase) Find(reference string, lastName string) (*models.Booking, error) {
+	b, err := pUC.bookingRepository.GetByReference(normalizeReference(reference))
+	if err != nil {
+		return nil, errors.Wrap(err, "bookingUseCase.Find error")
+	}
+
+	for _, ticket := range b.Tickets {
+		if ticket.Passenger.HasLastName(lastName) {
+			return b, nil
+		}
+	}
+
+	return nil, errors.Wrap(bookingRep.ErrBookingNotFound, "bookingUseCase.Find error")
+}
+
+// Cancel отменяет все действующие билеты бронирования пользователя одной
+// транзакцией: если статус какого-то из них успел измениться, не отменяется
+// ни один.
+func (pUC *bookingUseCase) Cancel(reference string, userName string) (*models.Booking, error) {
+	b, err := pUC.bookingRepository.GetByReference(normalizeReference(reference))
+	if err != nil {
+		return nil, errors.Wrap(err, "bookingUseCase.Cancel error")
+	}
+	if b.Username != userName {
+		return nil, errors.Wrap(bookingRep.ErrBookingNotFound, "bookingUseCase.Cancel error")
+	}
+
+	var active []*models.Ticket
+	for i := range b.Tickets {
+		ticket := &b.Tickets[i]
+		if ticket.Status == models.StatusHeld || ticket.Status == models.StatusPaid {
+			active = append(active, ticket)
+		}
+	}
+
+	err = pUC.ticketUseCase.CancelAll(active, userName)
+	if err != nil {
+		return nil, errors.Wrap(err, "bookingUseCase.Cancel error")
+	}
+
+	return b, nil
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	bookingRep "flight_booking_system/ticketService/internal/booking/repository"
+	bookingMocks "flight_booking_system/ticketService/internal/booking/repository/mocks"
+	"flight_booking_system/ticketService/models"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type BookingTestSuite struct {
+	suite.Suite
+	uc              *bookingUseCase
+	bookingRepoMock *bookingMocks.BookingRepositoryI
+}
+
+func TestBookingTestSuite(t *testing.T) {
+	suite.RunSuite(t, new(BookingTestSuite))
+}
+
+func (s *BookingTestSuite) BeforeEach(t provider.T) {
+	s.bookingRepoMock = bookingMocks.NewBookingRepositoryI(t)
+	s.uc = New(s.bookingRepoMock, nil).(*bookingUseCase)
+}
+
+func passenger(lastName, document string) models.Passenger {
+	birthDate := time.Date(1990, time.May, 1, 0, 0, 0, 0, time.UTC)
+	return models.Passenger{
+		FirstName:      "Ivan",
+		LastName:       lastName,
+		DocumentNumber: document,
+		BirthDate:      &birthDate,
+	}
+}
+
+func bookingTicket(p models.Passenger) models.Ticket {
+	return models.Ticket{FlightNumber: "AFL031", Price: 1500, Passenger: p}
+}
+
+func (s *BookingTestSuite) TestCreate(t provider.T) {
+	references := []string{"TAKEN1", "FREE22"}
+	s.uc.newReference = func() (string, error) {
+		reference := references[0]
+		references = references[1:]
+		return reference, nil
+	}
+
+	s.bookingRepoMock.On("Create", mock.MatchedBy(func(b *models.Booking) bool {
+		return b.Reference == "TAKEN1"
+	})).Return(bookingRep.ErrReferenceTaken).Once()
+	s.bookingRepoMock.On("Create", mock.Anything).Return(nil).Once()
+
+	booking := models.Booking{
+		Username: "username",
+		Tickets: []models.Ticket{
+			bookingTicket(passenger("Petrov", "4510 000001")),
+			bookingTicket(passenger("Petrova", "4510 000002")),
+		},
+	}
+
+	err := s.uc.Create(&booking)
+	t.Assert().NoError(err)
+	t.Assert().Equal("FREE22", booking.Reference)
+	for _, ticket := range booking.Tickets {
+		t.Assert().NotEmpty(ticket.TicketUID)
+		t.Assert().Equal("username", ticket.Username)
+		t.Assert().Equal(models.StatusPaid, ticket.Status)
+		t.Assert().Equal(models.FareEconomy, ticket.FareClass)
+	}
+}
+
+func (s *BookingTestSuite) TestCreateInvalid(t provider.T) {
+	future := time.Now().Add(24 * time.Hour)
+	unborn := passenger("Petrov", "4510 000001")
+	unborn.BirthDate = &future
+
+	tooMany := make([]models.Ticket, MaxPassengers+1)
+	for i := range tooMany {
+		tooMany[i] = bookingTicket(passenger("Petrov", string(rune('A'+i))))
+	}
+
+	cases := map[string][]models.Ticket{
+		"no passengers":      nil,
+		"too many":           tooMany,
+		"no last name":       {bookingTicket(passenger("", "4510 000001"))},
+		"no document":        {bookingTicket(passenger("Petrov", ""))},
+		"born in the future": {bookingTicket(unborn)},
+		"same document": {
+			bookingTicket(passenger("Petrov", "4510 000001")),
+			bookingTicket(passenger("Petrova", "4510 000001")),
+		},
+	}
+
+	for name, tickets := range cases {
+		t.Run(name, func(t provider.T) {
+			err := s.uc.Create(&models.Booking{Username: "username", Tickets: tickets})
+			t.Assert().ErrorIs(err, ErrInvalidBooking)
+		})
+	}
+}
+
+func (s *BookingTestSuite) TestFind(t provider.T) {
+	booking := &models.Booking{
+		Reference: "ABC234",
+		Tickets: []models.Ticket{
+			bookingTicket(passenger("Petrov", "4510 000001")),
+			bookingTicket(passenger("Ivanova", "4510 000002")),
+		},
+	}
+	s.bookingRepoMock.On("GetByReference", "ABC234").Return(booking, nil)
+
+	found, err := s.uc.Find(" abc234 ", "ivanova")
+	t.Assert().NoError(err)
+	t.Assert().Equal(booking, found)
+
+	_, err = s.uc.Find("ABC234", "Sidorov")
+	t.Assert().ErrorIs(err, bookingRep.ErrBookingNotFound)
+}
@@ -0,0 +1,106 @@
+package delivery
+
+import (
+	"encoding/json"
+	"net/http"
+
+	itineraryRep "flight_booking_system/ticketService/internal/itinerary/repository"
+	itineraryUseCase "flight_booking_system/ticketService/internal/itinerary/usecase"
+	ticketUseCase "flight_booking_system/ticketService/internal/ticket/usecase"
+	"flight_booking_system/ticketService/models"
+	"flight_booking_system/ticketService/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+type ItineraryHandler struct {
+	ItineraryUseCase itineraryUseCase.ItineraryUseCaseI
+	Logger           logger.Logger
+}
+
+func (ih *ItineraryHandler) writeError(w http.ResponseWriter, err error, msg string) {
+	ih.Logger.Infow(msg,
+		"err:", err.Error())
+
+	switch {
+	case errors.Is(err, itineraryUseCase.ErrInvalidItinerary):
+		http.Error(w, "invalid itinerary", http.StatusBadRequest)
+	case errors.Is(err, itineraryRep.ErrItineraryNotFound):
+		http.Error(w, "itinerary not found", http.StatusNotFound)
+	case errors.Is(err, itineraryRep.ErrItineraryExists):
+		http.Error(w, "itinerary already exists", http.StatusConflict)
+	case errors.Is(err, ticketUseCase.ErrIllegalTransition):
+		http.Error(w, "illegal ticket status transition", http.StatusConflict)
+	default:
+		http.Error(w, msg, http.StatusInternalServerError)
+	}
+}
+
+func (ih *ItineraryHandler) writeItinerary(w http.ResponseWriter, statusCode int, it *models.Itinerary) {
+	resp, err := json.Marshal(models.ItineraryToDTO(*it))
+	if err != nil {
+		ih.Logger.Errorw("can`t marshal itineraryDTO",
+			"err:", err.Error())
+		http.Error(w, "can`t make itineraryDTO", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		ih.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		return
+	}
+}
+
+// Create оформляет маршрут из нескольких перелётов.
+func (ih *ItineraryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	it := models.Itinerary{}
+
+	err := json.NewDecoder(r.Body).Decode(&it)
+	if err != nil {
+		ih.Logger.Infow("can`t unmarshal itinerary",
+			"err:", err.Error())
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	it.Username = r.Header.Get("X-User-Name")
+	if it.Username == "" {
+		ih.Logger.Infow("no user for itinerary")
+		http.Error(w, "no user", http.StatusUnauthorized)
+		return
+	}
+
+	err = ih.ItineraryUseCase.Create(&it)
+	if err != nil {
+		ih.writeError(w, err, "can`t create itinerary")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/itineraries/"+it.ItineraryUID)
+	ih.writeItinerary(w, http.StatusCreated, &it)
+}
+
+func (ih *ItineraryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	it, err := ih.ItineraryUseCase.Get(r.PathValue("itineraryUid"), r.Header.Get("X-User-Name"))
+	if err != nil {
+		ih.writeError(w, err, "can`t get itinerary")
+		return
+	}
+
+	ih.writeItinerary(w, http.StatusOK, it)
+}
+
+// Cancel отменяет все перелёты маршрута.
+func (ih *ItineraryHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	it, err := ih.ItineraryUseCase.Cancel(r.PathValue("itineraryUid"), r.Header.Get("X-User-Name"))
+	if err != nil {
+		ih.writeError(w, err, "can`t cancel itinerary")
+		return
+	}
+
+	ih.writeItinerary(w, http.StatusOK, it)
+}
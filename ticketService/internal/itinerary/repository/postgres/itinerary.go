@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"time"
+
+	"flight_booking_system/ticketService/internal/itinerary/repository"
+	"flight_booking_system/ticketService/models"
+	"flight_booking_system/ticketService/pkg/logger"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type pgItineraryRepo struct {
+	Logger logger.Logger
+	DB     *gorm.DB
+}
+
+func New(logger logger.Logger, db *gorm.DB) repository.ItineraryRepositoryI {
+	return &pgItineraryRepo{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// Create в одной транзакции создаёт маршрут, билеты всех перелётов и записи
+// о начальном статусе билетов.
+func (pr *pgItineraryRepo) Create(it *models.Itinerary) error {
+	err := pr.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Omit("Tickets").Create(it)
+		if errors.Is(res.Error, gorm.ErrDuplicatedKey) {
+			return repository.ErrItineraryExists
+		}
+		if res.Error != nil {
+			return res.Error
+		}
+
+		for i := range it.Tickets {
+			ticket := &it.Tickets[i]
+			ticket.ItineraryUID = &it.ItineraryUID
+
+			err := tx.Create(ticket).Error
+			if err != nil {
+				return err
+			}
+
+			err = tx.Create(&models.TicketStatusChange{
+				TicketUID: ticket.TicketUID,
+				ToStatus:  ticket.Status,
+				Actor:     it.Username,
+				ChangedAt: time.Now(),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "pgItineraryRepo.Create error")
+	}
+
+	return nil
+}
+
+func (pr *pgItineraryRepo) GetByUID(itineraryUID string) (*models.Itinerary, error) {
+	var it models.Itinerary
+	tx := pr.DB.Preload("Tickets", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("itinerary_uid = ?", itineraryUID).Limit(1).Find(&it)
+
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgItineraryRepo.GetByUID error")
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.Wrap(repository.ErrItineraryNotFound, "pgItineraryRepo.GetByUID error")
+	}
+
+	return &it, nil
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"database/sql"
+	itineraryRep "flight_booking_system/ticketService/internal/itinerary/repository"
+	"flight_booking_system/ticketService/pkg/logger"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+type ItineraryRepoTestSuite struct {
+	suite.Suite
+	db     *sql.DB
+	gormDB *gorm.DB
+	mock   sqlmock.Sqlmock
+	repo   itineraryRep.ItineraryRepositoryI
+}
+
+func TestItineraryRepoSuite(t *testing.T) {
+	suite.RunSuite(t, new(ItineraryRepoTestSuite))
+}
+
+func (s *ItineraryRepoTestSuite) BeforeEach(t provider.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal("error while creating sql mock")
+	}
+
+	dialector := postgres.New(postgres.Config{
+		DSN:                  "sqlmock_db_0",
+		DriverName:           "postgres",
+		Conn:                 db,
+		PreferSimpleProtocol: true,
+	})
+	gormDB, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatal("error gorm open")
+	}
+
+	var logger logger.Logger
+
+	s.db = db
+	s.gormDB = gormDB
+	s.mock = mock
+
+	s.repo = New(logger, gormDB)
+}
+
+func (s *ItineraryRepoTestSuite) AfterEach(t provider.T) {
+	err := s.mock.ExpectationsWereMet()
+	t.Assert().NoError(err)
+	s.db.Close()
+}
+
+func (s *ItineraryRepoTestSuite) TestGetByUID(t provider.T) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "itinerary" WHERE itinerary_uid = $1 LIMIT $2`)).
+		WithArgs("it-uid", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "itinerary_uid", "username"}).
+			AddRow(1, "it-uid", "username"))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "ticket" WHERE "ticket"."itinerary_uid" = $1 ORDER BY id`)).
+		WithArgs("it-uid").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ticket_uid", "flight_number", "itinerary_uid"}).
+			AddRow(1, "uid-1", "AFL031", "it-uid").
+			AddRow(2, "uid-2", "AFL032", "it-uid"))
+
+	it, err := s.repo.GetByUID("it-uid")
+	t.Assert().NoError(err)
+	t.Require().Len(it.Tickets, 2)
+	t.Assert().Equal("AFL032", it.Tickets[1].FlightNumber)
+}
+
+func (s *ItineraryRepoTestSuite) TestGetByUIDNotFound(t provider.T) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "itinerary" WHERE itinerary_uid = $1 LIMIT $2`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := s.repo.GetByUID("it-uid")
+	t.Assert().ErrorIs(err, itineraryRep.ErrItineraryNotFound)
+}
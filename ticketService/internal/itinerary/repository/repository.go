@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"flight_booking_system/ticketService/models"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrItineraryNotFound — маршрута с таким UID нет.
+	ErrItineraryNotFound = errors.New("itinerary not found")
+	// ErrItineraryExists — маршрут с таким UID уже создан.
+	ErrItineraryExists = errors.New("itinerary already exists")
+)
+
+type ItineraryRepositoryI interface {
+	// Create сохраняет маршрут вместе с билетами перелётов.
+	Create(it *models.Itinerary) error
+	GetByUID(itineraryUID string) (*models.Itinerary, error)
+}
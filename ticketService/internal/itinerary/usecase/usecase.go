@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"time"
+
+	itineraryRep "flight_booking_system/ticketService/internal/itinerary/repository"
+	ticketUseCase "flight_booking_system/ticketService/internal/ticket/usecase"
+	"flight_booking_system/ticketService/models"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidItinerary — в маршруте меньше двух перелётов или у перелёта нет рейса и цены.
+var ErrInvalidItinerary = errors.New("invalid itinerary")
+
+const (
+	MinLegs = 2
+	// MaxLegs — сколько перелётов можно купить одним маршрутом.
+	MaxLegs = 6
+)
+
+type ItineraryUseCaseI interface {
+	Create(it *models.Itinerary) error
+	Get(itineraryUID string, userName string) (*models.Itinerary, error)
+	Cancel(itineraryUID string, userName string) (*models.Itinerary, error)
+}
+
+type itineraryUseCase struct {
+	itineraryRepository itineraryRep.ItineraryRepositoryI
+	ticketUseCase       ticketUseCase.TicketUseCaseI
+}
+
+func New(iRep itineraryRep.ItineraryRepositoryI, tUC ticketUseCase.TicketUseCaseI) ItineraryUseCaseI {
+	return &itineraryUseCase{
+		itineraryRepository: iRep,
+		ticketUseCase:       tUC,
+	}
+}
+
+// Create оформляет оплаченные билеты всех перелётов маршрута. UID маршрута
+// может назначить клиент, иначе он выдаётся здесь.
+func (pUC *itineraryUseCase) Create(it *models.Itinerary) error {
+	if len(it.Tickets) < MinLegs || len(it.Tickets) > MaxLegs {
+		return errors.Wrapf(ErrInvalidItinerary, "itinerary must have from %d to %d legs", MinLegs, MaxLegs)
+	}
+
+	if it.ItineraryUID == "" {
+		it.ItineraryUID = uuid.New().String()
+	}
+	if _, err := uuid.Parse(it.ItineraryUID); err != nil {
+		return errors.Wrap(ErrInvalidItinerary, "itinerary uid must be a uuid")
+	}
+
+	for i := range it.Tickets {
+		ticket := &it.Tickets[i]
+		if ticket.FlightNumber == "" || ticket.Price <= 0 {
+			return errors.Wrapf(ErrInvalidItinerary, "leg %d: flight number and price are required", i+1)
+		}
+
+		if ticket.TicketUID == "" {
+			ticket.TicketUID = uuid.New().String()
+		}
+		if ticket.FareClass == "" {
+			ticket.FareClass = models.FareEconomy
+		}
+		ticket.Username = it.Username
+		ticket.Status = models.StatusPaid
+		ticket.HoldExpiresAt = nil
+		ticket.ExchangedFor = nil
+		ticket.BookingReference = nil
+		ticket.Passenger = models.Passenger{}
+	}
+
+	it.CreatedAt = time.Now()
+
+	err := pUC.itineraryRepository.Create(it)
+	if err != nil {
+		return errors.Wrap(err, "itineraryUseCase.Create error")
+	}
+
+	return nil
+}
+
+// Get возвращает маршрут пользователя; чужой маршрут считается ненайденным.
+func (pUC *itineraryUseCase) Get(itineraryUID string, userName string) (*models.Itinerary, error) {
+	it, err := pUC.itineraryRepository.GetByUID(itineraryUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "itineraryUseCase.Get error")
+	}
+	if it.Username != userName {
+		return nil, errors.Wrap(itineraryRep.ErrItineraryNotFound, "itineraryUseCase.Get error")
+	}
+
+	return it, nil
+}
+
+// Cancel отменяет все перелёты маршрута. Маршрут отменяется только целиком:
+// билеты отменяются одной транзакцией, и если хоть один уже нельзя отменить,
+// не отменяется ни один.
+func (pUC *itineraryUseCase) Cancel(itineraryUID string, userName string) (*models.Itinerary, error) {
+	it, err := pUC.Get(itineraryUID, userName)
+	if err != nil {
+		return nil, errors.Wrap(err, "itineraryUseCase.Cancel error")
+	}
+
+	tickets := make([]*models.Ticket, len(it.Tickets))
+	for i := range it.Tickets {
+		tickets[i] = &it.Tickets[i]
+	}
+
+	err = pUC.ticketUseCase.CancelAll(tickets, userName)
+	if err != nil {
+		return nil, errors.Wrap(err, "itineraryUseCase.Cancel error")
+	}
+
+	return it, nil
+}
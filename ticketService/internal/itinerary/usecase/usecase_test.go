@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	itineraryRep "flight_booking_system/ticketService/internal/itinerary/repository"
+	itineraryMocks "flight_booking_system/ticketService/internal/itinerary/repository/mocks"
+	ticketMocks "flight_booking_system/ticketService/internal/ticket/repository/mocks"
+	ticketUseCase "flight_booking_system/ticketService/internal/ticket/usecase"
+	"flight_booking_system/ticketService/models"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type ItineraryTestSuite struct {
+	suite.Suite
+	uc                ItineraryUseCaseI
+	itineraryRepoMock *itineraryMocks.ItineraryRepositoryI
+	ticketRepoMock    *ticketMocks.TicketRepositoryI
+}
+
+func TestItineraryTestSuite(t *testing.T) {
+	suite.RunSuite(t, new(ItineraryTestSuite))
+}
+
+func (s *ItineraryTestSuite) BeforeEach(t provider.T) {
+	s.itineraryRepoMock = itineraryMocks.NewItineraryRepositoryI(t)
+	s.ticketRepoMock = ticketMocks.NewTicketRepositoryI(t)
+	s.uc = New(s.itineraryRepoMock, ticketUseCase.New(s.ticketRepoMock, time.Minute))
+}
+
+func leg(flightNumber string, price int) models.Ticket {
+	return models.Ticket{FlightNumber: flightNumber, Price: price}
+}
+
+func (s *ItineraryTestSuite) TestCreate(t provider.T) {
+	s.itineraryRepoMock.On("Create", mock.Anything).Return(nil)
+
+	it := models.Itinerary{
+		Username: "username",
+		Tickets:  []models.Ticket{leg("AFL031", 1500), leg("AFL032", 1700)},
+	}
+
+	err := s.uc.Create(&it)
+	t.Assert().NoError(err)
+	t.Assert().NotEmpty(it.ItineraryUID)
+	for _, ticket := range it.Tickets {
+		t.Assert().NotEmpty(ticket.TicketUID)
+		t.Assert().Equal("username", ticket.Username)
+		t.Assert().Equal(models.StatusPaid, ticket.Status)
+	}
+}
+
+func (s *ItineraryTestSuite) TestCreateInvalid(t provider.T) {
+	cases := map[string]models.Itinerary{
+		"one leg":     {Tickets: []models.Ticket{leg("AFL031", 1500)}},
+		"no price":    {Tickets: []models.Ticket{leg("AFL031", 1500), leg("AFL032", 0)}},
+		"no flight":   {Tickets: []models.Ticket{leg("", 1500), leg("AFL032", 1700)}},
+		"invalid uid": {ItineraryUID: "itinerary", Tickets: []models.Ticket{leg("AFL031", 1500), leg("AFL032", 1700)}},
+	}
+
+	for name, it := range cases {
+		t.Run(name, func(t provider.T) {
+			it.Username = "username"
+			err := s.uc.Create(&it)
+			t.Assert().ErrorIs(err, ErrInvalidItinerary)
+		})
+	}
+}
+
+func (s *ItineraryTestSuite) TestCancel(t provider.T) {
+	outbound := &models.Ticket{TicketUID: "uid-1", Username: "username", Status: models.StatusPaid}
+	inbound := &models.Ticket{TicketUID: "uid-2", Username: "username", Status: models.StatusPaid}
+
+	s.itineraryRepoMock.On("GetByUID", "it-uid").Return(&models.Itinerary{
+		ItineraryUID: "it-uid",
+		Username:     "username",
+		Tickets:      []models.Ticket{*outbound, *inbound},
+	}, nil)
+	s.ticketRepoMock.On("ChangeStatuses", mock.MatchedBy(func(changes []models.TicketStatusChange) bool {
+		return len(changes) == 2 && changes[0].TicketUID == "uid-1" && changes[1].TicketUID == "uid-2"
+	})).Return(true, nil)
+
+	it, err := s.uc.Cancel("it-uid", "username")
+	t.Assert().NoError(err)
+	for _, ticket := range it.Tickets {
+		t.Assert().Equal(models.StatusCanceled, ticket.Status)
+	}
+}
+
+func (s *ItineraryTestSuite) TestCancelChangedConcurrently(t provider.T) {
+	s.itineraryRepoMock.On("GetByUID", "it-uid").Return(&models.Itinerary{
+		ItineraryUID: "it-uid",
+		Username:     "username",
+		Tickets: []models.Ticket{
+			{TicketUID: "uid-1", Status: models.StatusPaid},
+			{TicketUID: "uid-2", Status: models.StatusPaid},
+		},
+	}, nil)
+	s.ticketRepoMock.On("ChangeStatuses", mock.Anything).Return(false, nil)
+
+	_, err := s.uc.Cancel("it-uid", "username")
+	t.Assert().ErrorIs(err, ticketUseCase.ErrIllegalTransition)
+}
+
+func (s *ItineraryTestSuite) TestCancelPartlyUsed(t provider.T) {
+	s.itineraryRepoMock.On("GetByUID", "it-uid").Return(&models.Itinerary{
+		ItineraryUID: "it-uid",
+		Username:     "username",
+		Tickets: []models.Ticket{
+			{TicketUID: "uid-1", Status: models.StatusCheckedIn},
+			{TicketUID: "uid-2", Status: models.StatusPaid},
+		},
+	}, nil)
+
+	_, err := s.uc.Cancel("it-uid", "username")
+	t.Assert().ErrorIs(err, ticketUseCase.ErrIllegalTransition)
+}
+
+func (s *ItineraryTestSuite) TestGetForeign(t provider.T) {
+	s.itineraryRepoMock.On("GetByUID", "it-uid").Return(&models.Itinerary{
+		ItineraryUID: "it-uid",
+		Username:     "another",
+	}, nil)
+
+	_, err := s.uc.Get("it-uid", "username")
+	t.Assert().ErrorIs(err, itineraryRep.ErrItineraryNotFound)
+}
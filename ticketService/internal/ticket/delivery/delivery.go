@@ -5,6 +5,7 @@ import (
 	ticketUseCase "flight_booking_system/ticketService/internal/ticket/usecase"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"io"
 	"net/http"
 	"strconv"
@@ -52,6 +53,11 @@ func (th *TicketHandler) Create(w http.ResponseWriter, r *http.Request) {
 	//	return
 	//}
 
+	// Пользователя, подтверждённого gateway, нельзя подменить в теле запроса
+	if userName := r.Header.Get("X-User-Name"); userName != "" {
+		ticket.Username = userName
+	}
+
 	if ticket.TicketUID == "" {
 		ticket.TicketUID = uuid.New().String()
 	}
@@ -64,26 +70,26 @@ func (th *TicketHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//resp, err := json.Marshal(ticket)
-	//
-	//if err != nil {
-	//	th.Logger.Errorw("can`t marshal ticket",
-	//		"err:", err.Error())
-	//	http.Error(w, "can`t make ticket", http.StatusInternalServerError)
-	//	return
-	//}
+	// В ответе срок брони, который назначил сервис
+	resp, err := json.Marshal(models.TicketToDTO(ticket))
+	if err != nil {
+		th.Logger.Errorw("can`t marshal ticketDTO",
+			"err:", err.Error())
+		http.Error(w, "can`t make ticketDTO", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Location", fmt.Sprintf("/api/v1/tickets/%d", ticket.ID))
 	w.Header().Set("X-Ticket-UID", ticket.TicketUID)
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 
-	//_, err = w.Write(resp)
-	//if err != nil {
-	//	th.Logger.Errorw("can`t write response",
-	//		"err:", err.Error())
-	//	http.Error(w, "can`t write response", http.StatusInternalServerError)
-	//	return
-	//}
+	_, err = w.Write(resp)
+	if err != nil {
+		th.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		return
+	}
 }
 
 func (th *TicketHandler) Get(w http.ResponseWriter, r *http.Request) {
@@ -168,6 +174,10 @@ func (th *TicketHandler) Update(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		th.Logger.Infow("can`t update ticket",
 			"err:", err.Error())
+		if errors.Is(err, ticketUseCase.ErrIllegalTransition) {
+			http.Error(w, "ticket status is changed by transition endpoints", http.StatusConflict)
+			return
+		}
 		http.Error(w, "can`t update ticket", http.StatusNotFound)
 		return
 	}
@@ -266,6 +276,10 @@ func (th *TicketHandler) GetByUID(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		th.Logger.Infow("can`t get ticket by uid",
 			"err:", err.Error())
+		if errors.Is(err, ticketUseCase.ErrTicketNotFound) {
+			http.Error(w, "ticket not found", http.StatusNotFound)
+			return
+		}
 		http.Error(w, "can`t get ticket by uid", http.StatusInternalServerError)
 		return
 	}
@@ -291,3 +305,261 @@ func (th *TicketHandler) GetByUID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// Confirm оплачивает бронь билета. 409 — билет не забронирован или срок
+// брони истёк.
+func (th *TicketHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userName := r.Header.Get("X-User-Name")
+
+	ticket, err := th.TicketUseCase.Confirm(r.PathValue("ticketUid"), userName)
+	if err != nil {
+		th.Logger.Infow("can`t confirm ticket",
+			"err:", err.Error())
+		switch {
+		case errors.Is(err, ticketUseCase.ErrTicketNotFound):
+			http.Error(w, "ticket not found", http.StatusNotFound)
+		case errors.Is(err, ticketUseCase.ErrHoldExpired):
+			http.Error(w, "ticket hold has expired", http.StatusConflict)
+		case errors.Is(err, ticketUseCase.ErrNotHeld):
+			http.Error(w, "ticket is not held", http.StatusConflict)
+		default:
+			http.Error(w, "can`t confirm ticket", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resp, err := json.Marshal(models.TicketToDTO(*ticket))
+	if err != nil {
+		th.Logger.Errorw("can`t marshal ticketDTO",
+			"err:", err.Error())
+		http.Error(w, "can`t make ticketDTO", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		th.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		http.Error(w, "can`t write response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// Cancel отменяет забронированный или оплаченный билет.
+func (th *TicketHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	th.changeStatus(w, r, models.StatusCanceled)
+}
+
+// CheckIn регистрирует пассажира на рейс по оплаченному билету.
+func (th *TicketHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	th.changeStatus(w, r, models.StatusCheckedIn)
+}
+
+func (th *TicketHandler) changeStatus(w http.ResponseWriter, r *http.Request, status string) {
+	userName := r.Header.Get("X-User-Name")
+
+	ticket, err := th.TicketUseCase.ChangeStatus(r.PathValue("ticketUid"), userName, status)
+	if err != nil {
+		th.Logger.Infow("can`t change ticket status",
+			"status", status,
+			"err:", err.Error())
+		th.writeTransitionError(w, err)
+		return
+	}
+
+	th.writeTicket(w, ticket)
+}
+
+type revertRequest struct {
+	Status string `json:"status"`
+}
+
+// Revert возвращает билет в статус, из которого он перешёл последним
+// переходом. 409 — последний переход был другим. Вызывается только gateway
+// при компенсации саги.
+func (th *TicketHandler) Revert(w http.ResponseWriter, r *http.Request) {
+	req := revertRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil || req.Status == "" {
+		th.Logger.Infow("can`t decode revert request")
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	userName := r.Header.Get("X-User-Name")
+
+	ticket, err := th.TicketUseCase.RevertStatus(r.PathValue("ticketUid"), userName, req.Status)
+	if err != nil {
+		th.Logger.Infow("can`t revert ticket status",
+			"status", req.Status,
+			"err:", err.Error())
+		th.writeTransitionError(w, err)
+		return
+	}
+
+	th.writeTicket(w, ticket)
+}
+
+type exchangeRequest struct {
+	NewTicketUID string `json:"newTicketUid"`
+}
+
+// Exchange помечает билет обменянным на уже купленный новый билет.
+// 400 — новый билет не подходит, 409 — билет нельзя обменять в текущем статусе.
+func (th *TicketHandler) Exchange(w http.ResponseWriter, r *http.Request) {
+	req := exchangeRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil || req.NewTicketUID == "" {
+		th.Logger.Infow("can`t decode exchange request")
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	userName := r.Header.Get("X-User-Name")
+
+	ticket, err := th.TicketUseCase.Exchange(r.PathValue("ticketUid"), userName, req.NewTicketUID)
+	if err != nil {
+		th.Logger.Infow("can`t exchange ticket",
+			"err:", err.Error())
+		if errors.Is(err, ticketUseCase.ErrInvalidExchange) {
+			http.Error(w, "invalid ticket exchange", http.StatusBadRequest)
+			return
+		}
+		th.writeTransitionError(w, err)
+		return
+	}
+
+	th.writeTicket(w, ticket)
+}
+
+func (th *TicketHandler) History(w http.ResponseWriter, r *http.Request) {
+	userName := r.Header.Get("X-User-Name")
+
+	history, err := th.TicketUseCase.GetHistory(r.PathValue("ticketUid"), userName)
+	if err != nil {
+		th.Logger.Infow("can`t get ticket history",
+			"err:", err.Error())
+		th.writeTransitionError(w, err)
+		return
+	}
+
+	resp, err := json.Marshal(history)
+	if err != nil {
+		th.Logger.Errorw("can`t marshal ticket history",
+			"err:", err.Error())
+		http.Error(w, "can`t make ticket history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		th.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		return
+	}
+}
+
+// GetByFlight отдаёт администратору все билеты рейса с их владельцами.
+func (th *TicketHandler) GetByFlight(w http.ResponseWriter, r *http.Request) {
+	tickets, err := th.TicketUseCase.GetByFlight(r.PathValue("flightNumber"))
+	if err != nil {
+		th.Logger.Errorw("can`t get flight tickets",
+			"err:", err.Error())
+		http.Error(w, "can`t get flight tickets", http.StatusInternalServerError)
+		return
+	}
+
+	ticketsDTO := make([]*models.FlightTicketDTO, 0, len(tickets))
+	for _, ticket := range tickets {
+		ticketsDTO = append(ticketsDTO, &models.FlightTicketDTO{
+			Username:  ticket.Username,
+			TicketDTO: models.TicketToDTO(*ticket),
+		})
+	}
+
+	resp, err := json.Marshal(ticketsDTO)
+	if err != nil {
+		th.Logger.Errorw("can`t marshal tickets",
+			"err:", err.Error())
+		http.Error(w, "can`t make tickets", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		th.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		return
+	}
+}
+
+type flightCancelRequest struct {
+	Username string `json:"username"`
+}
+
+// CancelForFlight отменяет билет пользователя из-за отмены рейса. Вызывает
+// администратор, в истории переход записывается от его имени.
+func (th *TicketHandler) CancelForFlight(w http.ResponseWriter, r *http.Request) {
+	req := flightCancelRequest{}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil || req.Username == "" {
+		th.Logger.Infow("can`t decode flight cancel request")
+		http.Error(w, "bad data", http.StatusBadRequest)
+		return
+	}
+
+	actor := r.Header.Get("X-User-Name")
+
+	ticket, err := th.TicketUseCase.CancelForFlight(r.PathValue("ticketUid"), req.Username, actor)
+	if err != nil {
+		th.Logger.Infow("can`t cancel ticket for flight",
+			"err:", err.Error())
+		th.writeTransitionError(w, err)
+		return
+	}
+
+	th.writeTicket(w, ticket)
+}
+
+func (th *TicketHandler) writeTransitionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ticketUseCase.ErrTicketNotFound):
+		http.Error(w, "ticket not found", http.StatusNotFound)
+	case errors.Is(err, ticketUseCase.ErrIllegalTransition):
+		http.Error(w, "illegal ticket status transition", http.StatusConflict)
+	default:
+		http.Error(w, "can`t change ticket status", http.StatusInternalServerError)
+	}
+}
+
+func (th *TicketHandler) writeTicket(w http.ResponseWriter, ticket *models.Ticket) {
+	resp, err := json.Marshal(models.TicketToDTO(*ticket))
+	if err != nil {
+		th.Logger.Errorw("can`t marshal ticketDTO",
+			"err:", err.Error())
+		http.Error(w, "can`t make ticketDTO", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(resp)
+	if err != nil {
+		th.Logger.Errorw("can`t write response",
+			"err:", err.Error())
+		return
+	}
+}
@@ -6,6 +6,8 @@ import (
 	models "flight_booking_system/ticketService/models"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // TicketRepositoryI is an autogenerated mock type for the TicketRepositoryI type
@@ -13,6 +15,78 @@ type TicketRepositoryI struct {
 	mock.Mock
 }
 
+// ChangeStatus provides a mock function with given fields: ticketUID, from, to, actor, now
+func (_m *TicketRepositoryI) ChangeStatus(ticketUID string, from string, to string, actor string, now time.Time) (bool, error) {
+	ret := _m.Called(ticketUID, from, to, actor, now)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string, time.Time) (bool, error)); ok {
+		return rf(ticketUID, from, to, actor, now)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string, time.Time) bool); ok {
+		r0 = rf(ticketUID, from, to, actor, now)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string, time.Time) error); ok {
+		r1 = rf(ticketUID, from, to, actor, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChangeStatuses provides a mock function with given fields: changes
+func (_m *TicketRepositoryI) ChangeStatuses(changes []models.TicketStatusChange) (bool, error) {
+	ret := _m.Called(changes)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]models.TicketStatusChange) (bool, error)); ok {
+		return rf(changes)
+	}
+	if rf, ok := ret.Get(0).(func([]models.TicketStatusChange) bool); ok {
+		r0 = rf(changes)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func([]models.TicketStatusChange) error); ok {
+		r1 = rf(changes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConfirmHold provides a mock function with given fields: ticketUID, userName, now
+func (_m *TicketRepositoryI) ConfirmHold(ticketUID string, userName string, now time.Time) (bool, error) {
+	ret := _m.Called(ticketUID, userName, now)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) (bool, error)); ok {
+		return rf(ticketUID, userName, now)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) bool); ok {
+		r0 = rf(ticketUID, userName, now)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, time.Time) error); ok {
+		r1 = rf(ticketUID, userName, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: p
 func (_m *TicketRepositoryI) Create(p *models.Ticket) error {
 	ret := _m.Called(p)
@@ -41,6 +115,30 @@ func (_m *TicketRepositoryI) Delete(id int) error {
 	return r0
 }
 
+// ExpireHold provides a mock function with given fields: ticketUID, actor, now
+func (_m *TicketRepositoryI) ExpireHold(ticketUID string, actor string, now time.Time) (bool, error) {
+	ret := _m.Called(ticketUID, actor, now)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) (bool, error)); ok {
+		return rf(ticketUID, actor, now)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) bool); ok {
+		r0 = rf(ticketUID, actor, now)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, time.Time) error); ok {
+		r1 = rf(ticketUID, actor, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Get provides a mock function with given fields: id
 func (_m *TicketRepositoryI) Get(id int) (*models.Ticket, error) {
 	ret := _m.Called(id)
@@ -93,6 +191,32 @@ func (_m *TicketRepositoryI) GetAll() ([]*models.Ticket, error) {
 	return r0, r1
 }
 
+// GetAllByFlightNumber provides a mock function with given fields: flightNumber
+func (_m *TicketRepositoryI) GetAllByFlightNumber(flightNumber string) ([]*models.Ticket, error) {
+	ret := _m.Called(flightNumber)
+
+	var r0 []*models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.Ticket, error)); ok {
+		return rf(flightNumber)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.Ticket); ok {
+		r0 = rf(flightNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(flightNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllByUserName provides a mock function with given fields: userName
 func (_m *TicketRepositoryI) GetAllByUserName(userName string) ([]*models.Ticket, error) {
 	ret := _m.Called(userName)
@@ -119,6 +243,82 @@ func (_m *TicketRepositoryI) GetAllByUserName(userName string) ([]*models.Ticket
 	return r0, r1
 }
 
+// GetExpiredHolds provides a mock function with given fields: now, limit
+func (_m *TicketRepositoryI) GetExpiredHolds(now time.Time, limit int) ([]*models.Ticket, error) {
+	ret := _m.Called(now, limit)
+
+	var r0 []*models.Ticket
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, int) ([]*models.Ticket, error)); ok {
+		return rf(now, limit)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, int) []*models.Ticket); ok {
+		r0 = rf(now, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Ticket)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStatusHistory provides a mock function with given fields: ticketUID
+func (_m *TicketRepositoryI) GetStatusHistory(ticketUID string) ([]*models.TicketStatusChange, error) {
+	ret := _m.Called(ticketUID)
+
+	var r0 []*models.TicketStatusChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.TicketStatusChange, error)); ok {
+		return rf(ticketUID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.TicketStatusChange); ok {
+		r0 = rf(ticketUID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.TicketStatusChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(ticketUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkExchanged provides a mock function with given fields: ticketUID, newTicketUID, actor, now
+func (_m *TicketRepositoryI) MarkExchanged(ticketUID string, newTicketUID string, actor string, now time.Time) (bool, error) {
+	ret := _m.Called(ticketUID, newTicketUID, actor, now)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Time) (bool, error)); ok {
+		return rf(ticketUID, newTicketUID, actor, now)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, time.Time) bool); ok {
+		r0 = rf(ticketUID, newTicketUID, actor, now)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, time.Time) error); ok {
+		r1 = rf(ticketUID, newTicketUID, actor, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: p
 func (_m *TicketRepositoryI) Update(p *models.Ticket) error {
 	ret := _m.Called(p)
@@ -1,6 +1,8 @@
 package postgres
 
 import (
+	"time"
+
 	"flight_booking_system/ticketService/internal/ticket/repository"
 	"flight_booking_system/ticketService/models"
 	"flight_booking_system/ticketService/pkg/logger"
@@ -20,11 +22,24 @@ func New(logger logger.Logger, db *gorm.DB) repository.TicketRepositoryI {
 	}
 }
 
+// Create вместе с билетом записывает в историю его начальный статус.
 func (pr *pgTicketRepo) Create(p *models.Ticket) error {
-	tx := pr.DB.Create(p)
+	err := pr.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(p).Error
+		if err != nil {
+			return err
+		}
 
-	if tx.Error != nil {
-		return errors.Wrap(tx.Error, "pgTicketRepo.Create error while inserting in repo")
+		return tx.Create(&models.TicketStatusChange{
+			TicketUID: p.TicketUID,
+			ToStatus:  p.Status,
+			Actor:     p.Username,
+			ChangedAt: time.Now(),
+		}).Error
+	})
+
+	if err != nil {
+		return errors.Wrap(err, "pgTicketRepo.Create error while inserting in repo")
 	}
 
 	return nil
@@ -84,3 +99,178 @@ func (pr *pgTicketRepo) GetAllByUserName(userName string) ([]*models.Ticket, err
 
 	return tickets, nil
 }
+
+func (pr *pgTicketRepo) GetAllByFlightNumber(flightNumber string) ([]*models.Ticket, error) {
+	var tickets []*models.Ticket
+
+	tx := pr.DB.Where("flight_number = ?", flightNumber).Order("id").Find(&tickets)
+
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgTicketRepo.GetAllByFlightNumber error")
+	}
+
+	return tickets, nil
+}
+
+// errStatusChanged откатывает транзакцию ChangeStatuses, если хоть один
+// билет уже в другом статусе.
+var errStatusChanged = errors.New("ticket status has changed")
+
+// updateStatus переводит билет из change.FromStatus в change.ToStatus и
+// записывает переход в историю в транзакции tx. updates — поля, которые
+// меняются вместе со статусом, scopes — дополнительные условия перехода.
+// Возвращает false, если билет уже в другом статусе или не подошёл под условия.
+func updateStatus(tx *gorm.DB, change models.TicketStatusChange, updates map[string]any, scopes ...func(*gorm.DB) *gorm.DB) (bool, error) {
+	values := map[string]any{"status": change.ToStatus}
+	for column, value := range updates {
+		values[column] = value
+	}
+
+	res := tx.Model(&models.Ticket{}).
+		Where("ticket_uid = ? AND status = ?", change.TicketUID, *change.FromStatus).
+		Scopes(scopes...).
+		Updates(values)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+
+	return true, tx.Create(&change).Error
+}
+
+// changeStatus выполняет updateStatus в отдельной транзакции.
+func (pr *pgTicketRepo) changeStatus(change models.TicketStatusChange, updates map[string]any, scopes ...func(*gorm.DB) *gorm.DB) (bool, error) {
+	changed := false
+	err := pr.DB.Transaction(func(tx *gorm.DB) (err error) {
+		changed, err = updateStatus(tx, change, updates, scopes...)
+		return err
+	})
+
+	return changed, err
+}
+
+func (pr *pgTicketRepo) ChangeStatus(ticketUID string, from string, to string, actor string, now time.Time) (bool, error) {
+	changed, err := pr.changeStatus(models.TicketStatusChange{
+		TicketUID:  ticketUID,
+		FromStatus: &from,
+		ToStatus:   to,
+		Actor:      actor,
+		ChangedAt:  now,
+	}, nil)
+
+	if err != nil {
+		return false, errors.Wrap(err, "pgTicketRepo.ChangeStatus error")
+	}
+
+	return changed, nil
+}
+
+func (pr *pgTicketRepo) ChangeStatuses(changes []models.TicketStatusChange) (bool, error) {
+	err := pr.DB.Transaction(func(tx *gorm.DB) error {
+		for _, change := range changes {
+			changed, err := updateStatus(tx, change, nil)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				return errStatusChanged
+			}
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, errStatusChanged) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "pgTicketRepo.ChangeStatuses error")
+	}
+
+	return true, nil
+}
+
+// ConfirmHold переводит непросроченную бронь пользователя в PAID. Возвращает
+// false, если такой брони нет или срок её оплаты уже истёк.
+func (pr *pgTicketRepo) ConfirmHold(ticketUID string, userName string, now time.Time) (bool, error) {
+	from := models.StatusHeld
+	confirmed, err := pr.changeStatus(models.TicketStatusChange{
+		TicketUID:  ticketUID,
+		FromStatus: &from,
+		ToStatus:   models.StatusPaid,
+		Actor:      userName,
+		ChangedAt:  now,
+	}, map[string]any{"hold_expires_at": nil}, func(db *gorm.DB) *gorm.DB {
+		return db.Where("username = ? AND hold_expires_at > ?", userName, now)
+	})
+
+	if err != nil {
+		return false, errors.Wrap(err, "pgTicketRepo.ConfirmHold error")
+	}
+
+	return confirmed, nil
+}
+
+func (pr *pgTicketRepo) GetExpiredHolds(now time.Time, limit int) ([]*models.Ticket, error) {
+	var tickets []*models.Ticket
+
+	tx := pr.DB.Where("status = ? AND hold_expires_at <= ?", models.StatusHeld, now).
+		Order("hold_expires_at").Limit(limit).Find(&tickets)
+
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgTicketRepo.GetExpiredHolds error")
+	}
+
+	return tickets, nil
+}
+
+// ExpireHold переводит просроченную бронь в EXPIRED. Возвращает false, если
+// бронь успели оплатить или отменить.
+func (pr *pgTicketRepo) ExpireHold(ticketUID string, actor string, now time.Time) (bool, error) {
+	from := models.StatusHeld
+	expired, err := pr.changeStatus(models.TicketStatusChange{
+		TicketUID:  ticketUID,
+		FromStatus: &from,
+		ToStatus:   models.StatusExpired,
+		Actor:      actor,
+		ChangedAt:  now,
+	}, map[string]any{"hold_expires_at": nil}, func(db *gorm.DB) *gorm.DB {
+		return db.Where("hold_expires_at <= ?", now)
+	})
+
+	if err != nil {
+		return false, errors.Wrap(err, "pgTicketRepo.ExpireHold error")
+	}
+
+	return expired, nil
+}
+
+// MarkExchanged переводит оплаченный билет в EXCHANGED и запоминает билет,
+// на который его обменяли.
+func (pr *pgTicketRepo) MarkExchanged(ticketUID string, newTicketUID string, actor string, now time.Time) (bool, error) {
+	from := models.StatusPaid
+	exchanged, err := pr.changeStatus(models.TicketStatusChange{
+		TicketUID:  ticketUID,
+		FromStatus: &from,
+		ToStatus:   models.StatusExchanged,
+		Actor:      actor,
+		ChangedAt:  now,
+	}, map[string]any{"exchanged_for": newTicketUID})
+
+	if err != nil {
+		return false, errors.Wrap(err, "pgTicketRepo.MarkExchanged error")
+	}
+
+	return exchanged, nil
+}
+
+func (pr *pgTicketRepo) GetStatusHistory(ticketUID string) ([]*models.TicketStatusChange, error) {
+	var history []*models.TicketStatusChange
+
+	tx := pr.DB.Where("ticket_uid = ?", ticketUID).Order("id").Find(&history)
+
+	if tx.Error != nil {
+		return nil, errors.Wrap(tx.Error, "pgTicketRepo.GetStatusHistory error")
+	}
+
+	return history, nil
+}
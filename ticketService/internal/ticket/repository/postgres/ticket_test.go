@@ -14,6 +14,7 @@ import (
 	"gorm.io/gorm"
 	"regexp"
 	"testing"
+	"time"
 )
 
 type TicketRepoTestSuite struct {
@@ -79,6 +80,11 @@ func (s *TicketRepoTestSuite) TestCreateTicket(t provider.T) {
 		WithArgs(ticket.TicketUID, ticket.Username, ticket.FlightNumber, ticket.Price, ticket.Status, ticket.ID).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "ticket_status_history" ("ticket_uid","from_status","to_status","actor","changed_at") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs(ticket.TicketUID, nil, ticket.Status, ticket.Username, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
 	s.mock.ExpectCommit()
 
 	err := s.repo.Create(&ticket)
@@ -227,3 +233,125 @@ func (s *TicketRepoTestSuite) TestGetAllByUserName(t provider.T) {
 	t.Assert().NoError(err)
 	t.Assert().Equal(ticketsPtr, resTickets)
 }
+
+func (s *TicketRepoTestSuite) TestGetAllByFlightNumber(t provider.T) {
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "ticket" WHERE flight_number = $1 ORDER BY id`)).
+		WithArgs("AFL031").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ticket_uid", "username", "flight_number", "status"}).
+			AddRow(1, "uid1", "user1", "AFL031", models.StatusPaid).
+			AddRow(2, "uid2", "user2", "AFL031", models.StatusCheckedIn))
+
+	tickets, err := s.repo.GetAllByFlightNumber("AFL031")
+	t.Assert().NoError(err)
+	t.Require().Len(tickets, 2)
+	t.Assert().Equal("user2", tickets[1].Username)
+	t.Assert().Equal(models.StatusCheckedIn, tickets[1].Status)
+}
+
+func (s *TicketRepoTestSuite) TestChangeStatus(t provider.T) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "ticket" SET "status"=$1 WHERE ticket_uid = $2 AND status = $3`)).
+		WithArgs(models.StatusCanceled, "uid", models.StatusPaid).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "ticket_status_history" ("ticket_uid","from_status","to_status","actor","changed_at") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs("uid", models.StatusPaid, models.StatusCanceled, "username", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectCommit()
+
+	changed, err := s.repo.ChangeStatus("uid", models.StatusPaid, models.StatusCanceled, "username", time.Now())
+	t.Assert().NoError(err)
+	t.Assert().True(changed)
+}
+
+func (s *TicketRepoTestSuite) TestChangeStatusFromAnotherStatus(t provider.T) {
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "ticket" SET "status"=$1 WHERE ticket_uid = $2 AND status = $3`)).
+		WithArgs(models.StatusCanceled, "uid", models.StatusPaid).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectCommit()
+
+	changed, err := s.repo.ChangeStatus("uid", models.StatusPaid, models.StatusCanceled, "username", time.Now())
+	t.Assert().NoError(err)
+	t.Assert().False(changed)
+}
+
+func (s *TicketRepoTestSuite) TestChangeStatuses(t provider.T) {
+	now := time.Now()
+	held, paid := models.StatusHeld, models.StatusPaid
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "ticket" SET "status"=$1 WHERE ticket_uid = $2 AND status = $3`)).
+		WithArgs(models.StatusCanceled, "uid-1", models.StatusHeld).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "ticket_status_history" ("ticket_uid","from_status","to_status","actor","changed_at") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs("uid-1", models.StatusHeld, models.StatusCanceled, "username", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "ticket" SET "status"=$1 WHERE ticket_uid = $2 AND status = $3`)).
+		WithArgs(models.StatusCanceled, "uid-2", models.StatusPaid).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "ticket_status_history" ("ticket_uid","from_status","to_status","actor","changed_at") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs("uid-2", models.StatusPaid, models.StatusCanceled, "username", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	s.mock.ExpectCommit()
+
+	changed, err := s.repo.ChangeStatuses([]models.TicketStatusChange{
+		{TicketUID: "uid-1", FromStatus: &held, ToStatus: models.StatusCanceled, Actor: "username", ChangedAt: now},
+		{TicketUID: "uid-2", FromStatus: &paid, ToStatus: models.StatusCanceled, Actor: "username", ChangedAt: now},
+	})
+	t.Assert().NoError(err)
+	t.Assert().True(changed)
+}
+
+func (s *TicketRepoTestSuite) TestChangeStatusesRollback(t provider.T) {
+	now := time.Now()
+	paid := models.StatusPaid
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "ticket" SET "status"=$1 WHERE ticket_uid = $2 AND status = $3`)).
+		WithArgs(models.StatusCanceled, "uid-1", models.StatusPaid).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "ticket_status_history" ("ticket_uid","from_status","to_status","actor","changed_at") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs("uid-1", models.StatusPaid, models.StatusCanceled, "username", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "ticket" SET "status"=$1 WHERE ticket_uid = $2 AND status = $3`)).
+		WithArgs(models.StatusCanceled, "uid-2", models.StatusPaid).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.mock.ExpectRollback()
+
+	changed, err := s.repo.ChangeStatuses([]models.TicketStatusChange{
+		{TicketUID: "uid-1", FromStatus: &paid, ToStatus: models.StatusCanceled, Actor: "username", ChangedAt: now},
+		{TicketUID: "uid-2", FromStatus: &paid, ToStatus: models.StatusCanceled, Actor: "username", ChangedAt: now},
+	})
+	t.Assert().NoError(err)
+	t.Assert().False(changed)
+}
+
+func (s *TicketRepoTestSuite) TestExpireHold(t provider.T) {
+	now := time.Now()
+
+	s.mock.ExpectBegin()
+	s.mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE "ticket" SET "hold_expires_at"=$1,"status"=$2 WHERE (ticket_uid = $3 AND status = $4) AND hold_expires_at <= $5`)).
+		WithArgs(nil, models.StatusExpired, "uid", models.StatusHeld, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO "ticket_status_history" ("ticket_uid","from_status","to_status","actor","changed_at") VALUES ($1,$2,$3,$4,$5) RETURNING "id"`)).
+		WithArgs("uid", models.StatusHeld, models.StatusExpired, "hold-sweeper", now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	s.mock.ExpectCommit()
+
+	expired, err := s.repo.ExpireHold("uid", "hold-sweeper", now)
+	t.Assert().NoError(err)
+	t.Assert().True(expired)
+}
@@ -1,6 +1,10 @@
 package repository
 
-import "flight_booking_system/ticketService/models"
+import (
+	"time"
+
+	"flight_booking_system/ticketService/models"
+)
 
 type TicketRepositoryI interface {
 	Create(p *models.Ticket) error
@@ -9,4 +13,16 @@ type TicketRepositoryI interface {
 	Delete(id int) error
 	GetAll() ([]*models.Ticket, error)
 	GetAllByUserName(userName string) ([]*models.Ticket, error)
+	GetAllByFlightNumber(flightNumber string) ([]*models.Ticket, error)
+	ConfirmHold(ticketUID string, userName string, now time.Time) (bool, error)
+	GetExpiredHolds(now time.Time, limit int) ([]*models.Ticket, error)
+	ExpireHold(ticketUID string, actor string, now time.Time) (bool, error)
+	// ChangeStatus переводит билет из from в to и пишет переход в историю;
+	// false — билет уже не в статусе from.
+	ChangeStatus(ticketUID string, from string, to string, actor string, now time.Time) (bool, error)
+	// ChangeStatuses выполняет переходы одной транзакцией: если хоть один
+	// билет уже не в FromStatus, не меняется ни один и возвращается false.
+	ChangeStatuses(changes []models.TicketStatusChange) (bool, error)
+	MarkExchanged(ticketUID string, newTicketUID string, actor string, now time.Time) (bool, error)
+	GetStatusHistory(ticketUID string) ([]*models.TicketStatusChange, error)
 }
@@ -0,0 +1,90 @@
+package sweeper
+
+import (
+	"context"
+	"time"
+
+	ticketUseCase "flight_booking_system/ticketService/internal/ticket/usecase"
+	"flight_booking_system/ticketService/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+type SeatReleaser interface {
+	ReleaseSeat(ctx context.Context, ticketUID string) error
+}
+
+// Sweeper периодически снимает просроченные брони: освобождает место во
+// Flight Service и переводит билет в EXPIRED.
+type Sweeper struct {
+	TicketUseCase ticketUseCase.TicketUseCaseI
+	Seats         SeatReleaser
+	Logger        logger.Logger
+	Interval      time.Duration
+	BatchSize     int
+}
+
+func New(logger logger.Logger, uc ticketUseCase.TicketUseCaseI, seats SeatReleaser, interval time.Duration, batchSize int) *Sweeper {
+	return &Sweeper{
+		TicketUseCase: uc,
+		Seats:         seats,
+		Logger:        logger,
+		Interval:      interval,
+		BatchSize:     batchSize,
+	}
+}
+
+// Run снимает брони каждые Interval, пока не отменят ctx.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.Sweep(ctx)
+			if err != nil {
+				s.Logger.Errorw("can`t sweep expired holds", "err:", err.Error())
+			}
+			if expired > 0 {
+				s.Logger.Infow("expired holds released", "count", expired)
+			}
+		}
+	}
+}
+
+// Sweep обрабатывает одну пачку просроченных броней. Место освобождается до
+// смены статуса: если Flight Service недоступен, бронь останется HELD и
+// будет снята на следующем проходе. Оплатить просроченную бронь уже нельзя,
+// так что гонки с подтверждением нет.
+func (s *Sweeper) Sweep(ctx context.Context) (int, error) {
+	tickets, err := s.TicketUseCase.GetExpiredHolds(s.BatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "sweeper.Sweep error")
+	}
+
+	expired := 0
+	for _, ticket := range tickets {
+		err = s.Seats.ReleaseSeat(ctx, ticket.TicketUID)
+		if err != nil {
+			s.Logger.Errorw("can`t release seat of expired hold",
+				"ticketUid", ticket.TicketUID,
+				"err:", err.Error())
+			continue
+		}
+
+		ok, err := s.TicketUseCase.ExpireHold(ticket.TicketUID)
+		if err != nil {
+			s.Logger.Errorw("can`t expire hold",
+				"ticketUid", ticket.TicketUID,
+				"err:", err.Error())
+			continue
+		}
+		if ok {
+			expired++
+		}
+	}
+
+	return expired, nil
+}
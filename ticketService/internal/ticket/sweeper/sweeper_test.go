@@ -0,0 +1,129 @@
+package sweeper
+
+import (
+	"context"
+	ticketMocks "flight_booking_system/ticketService/internal/ticket/repository/mocks"
+	ticketUseCase "flight_booking_system/ticketService/internal/ticket/usecase"
+	"flight_booking_system/ticketService/models"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+type SweeperTestSuite struct {
+	suite.Suite
+	sweeper        *Sweeper
+	ticketRepoMock *ticketMocks.TicketRepositoryI
+	seats          *seatReleaser
+	logs           *observer.ObservedLogs
+	calls          []string
+}
+
+func TestSweeperSuite(t *testing.T) {
+	suite.RunSuite(t, new(SweeperTestSuite))
+}
+
+func (s *SweeperTestSuite) BeforeEach(t provider.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	s.logs = logs
+	s.calls = nil
+	s.ticketRepoMock = ticketMocks.NewTicketRepositoryI(t)
+	s.seats = &seatReleaser{suite: s, errs: make(map[string]error)}
+	s.sweeper = New(zap.New(core).Sugar(), ticketUseCase.New(s.ticketRepoMock, time.Minute), s.seats, time.Minute, 10)
+}
+
+// seatReleaser записывает освобождения мест в s.calls и возвращает заданные
+// для билетов ошибки.
+type seatReleaser struct {
+	suite *SweeperTestSuite
+	errs  map[string]error
+}
+
+func (r *seatReleaser) ReleaseSeat(ctx context.Context, ticketUID string) error {
+	r.suite.calls = append(r.suite.calls, "release "+ticketUID)
+	return r.errs[ticketUID]
+}
+
+func (s *SweeperTestSuite) expectHolds(uids ...string) {
+	tickets := make([]*models.Ticket, 0, len(uids))
+	for _, uid := range uids {
+		tickets = append(tickets, &models.Ticket{TicketUID: uid, Status: models.StatusHeld})
+	}
+
+	s.ticketRepoMock.On("GetExpiredHolds", mock.Anything, 10).Return(tickets, nil).Once()
+}
+
+func (s *SweeperTestSuite) expectExpire(uid string, expired bool, err error) {
+	s.ticketRepoMock.On("ExpireHold", uid, mock.Anything, mock.Anything).Return(expired, err).Once().
+		Run(func(args mock.Arguments) {
+			s.calls = append(s.calls, "expire "+uid)
+		})
+}
+
+func (s *SweeperTestSuite) TestSweep(t provider.T) {
+	s.expectHolds("first", "second")
+	s.expectExpire("first", true, nil)
+	s.expectExpire("second", true, nil)
+
+	expired, err := s.sweeper.Sweep(context.Background())
+
+	t.Require().NoError(err)
+	t.Assert().Equal(2, expired)
+	// Место освобождается раньше, чем бронь становится EXPIRED
+	t.Assert().Equal([]string{"release first", "expire first", "release second", "expire second"}, s.calls)
+}
+
+func (s *SweeperTestSuite) TestSweepReleaseFailed(t provider.T) {
+	s.seats.errs["first"] = errors.New("flight service unavailable")
+	s.expectHolds("first", "second")
+	s.expectExpire("second", true, nil)
+
+	expired, err := s.sweeper.Sweep(context.Background())
+
+	t.Require().NoError(err)
+	t.Assert().Equal(1, expired)
+	// Бронь без освобождённого места остаётся HELD до следующего прохода
+	t.Assert().Equal([]string{"release first", "release second", "expire second"}, s.calls)
+
+	failed := s.logs.FilterMessage("can`t release seat of expired hold").All()
+	t.Require().Len(failed, 1)
+	t.Assert().Equal("first", failed[0].ContextMap()["ticketUid"])
+}
+
+func (s *SweeperTestSuite) TestSweepExpireFailed(t provider.T) {
+	s.expectHolds("first", "second", "third")
+	s.expectExpire("first", false, errors.New("db error"))
+	// Бронь уже оплатили или сняли другим способом
+	s.expectExpire("second", false, nil)
+	s.expectExpire("third", true, nil)
+
+	expired, err := s.sweeper.Sweep(context.Background())
+
+	t.Require().NoError(err)
+	t.Assert().Equal(1, expired)
+	t.Assert().Equal([]string{
+		"release first", "expire first",
+		"release second", "expire second",
+		"release third", "expire third",
+	}, s.calls)
+
+	failed := s.logs.FilterMessage("can`t expire hold").All()
+	t.Require().Len(failed, 1)
+	t.Assert().Equal("first", failed[0].ContextMap()["ticketUid"])
+}
+
+func (s *SweeperTestSuite) TestSweepGetHoldsFailed(t provider.T) {
+	errRepo := errors.New("db error")
+	s.ticketRepoMock.On("GetExpiredHolds", mock.Anything, 10).Return(nil, errRepo).Once()
+
+	expired, err := s.sweeper.Sweep(context.Background())
+
+	t.Assert().ErrorIs(err, errRepo)
+	t.Assert().Equal(0, expired)
+	t.Assert().Empty(s.calls)
+}
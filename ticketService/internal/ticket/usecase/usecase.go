@@ -1,11 +1,45 @@
 package usecase
 
 import (
+	"time"
+
 	ticketRep "flight_booking_system/ticketService/internal/ticket/repository"
 	"flight_booking_system/ticketService/models"
 	"github.com/pkg/errors"
 )
 
+var (
+	ErrTicketNotFound = errors.New("ticket not found")
+	// ErrNotHeld — билет не забронирован: уже оплачен, отменён или истёк.
+	ErrNotHeld = errors.New("ticket is not held")
+	// ErrHoldExpired — срок оплаты брони истёк.
+	ErrHoldExpired = errors.New("ticket hold has expired")
+	// ErrIllegalTransition — из текущего статуса билета в запрошенный перейти нельзя.
+	ErrIllegalTransition = errors.New("illegal ticket status transition")
+	// ErrInvalidExchange — билет, на который меняют, не подходит для обмена.
+	ErrInvalidExchange = errors.New("invalid ticket exchange")
+)
+
+// sweeperActor — от чьего имени в историю пишется истечение брони.
+const sweeperActor = "hold-sweeper"
+
+// transitions — допустимые переходы статуса билета. CHECKED_IN, CANCELED,
+// EXPIRED и EXCHANGED — конечные статусы.
+var transitions = map[string][]string{
+	models.StatusHeld: {models.StatusPaid, models.StatusCanceled, models.StatusExpired},
+	models.StatusPaid: {models.StatusCheckedIn, models.StatusCanceled, models.StatusExchanged},
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
 type TicketUseCaseI interface {
 	Create(p *models.Ticket) error
 	Get(id int) (*models.Ticket, error)
@@ -13,19 +47,46 @@ type TicketUseCaseI interface {
 	Delete(id int) error
 	GetAll(userName string) ([]*models.Ticket, error)
 	GetByUID(ticketUid string, userName string) (*models.Ticket, error)
+	Confirm(ticketUid string, userName string) (*models.Ticket, error)
+	GetExpiredHolds(limit int) ([]*models.Ticket, error)
+	ExpireHold(ticketUid string) (bool, error)
+	ChangeStatus(ticketUid string, userName string, status string) (*models.Ticket, error)
+	RevertStatus(ticketUid string, userName string, status string) (*models.Ticket, error)
+	Exchange(ticketUid string, userName string, newTicketUid string) (*models.Ticket, error)
+	GetHistory(ticketUid string, userName string) ([]*models.TicketStatusChange, error)
+	GetByFlight(flightNumber string) ([]*models.Ticket, error)
+	CancelForFlight(ticketUid string, userName string, actor string) (*models.Ticket, error)
+	CancelAll(tickets []*models.Ticket, actor string) error
 }
 
 type ticketUseCase struct {
 	ticketRepository ticketRep.TicketRepositoryI
+	holdTTL          time.Duration
 }
 
-func New(aRep ticketRep.TicketRepositoryI) TicketUseCaseI {
+// New создаёт usecase билетов; holdTTL — сколько действует бронь до оплаты.
+func New(aRep ticketRep.TicketRepositoryI, holdTTL time.Duration) TicketUseCaseI {
 	return &ticketUseCase{
 		ticketRepository: aRep,
+		holdTTL:          holdTTL,
 	}
 }
 
 func (pUC *ticketUseCase) Create(p *models.Ticket) error {
+	if p.FareClass == "" {
+		p.FareClass = models.FareEconomy
+	}
+
+	// Срок брони, ссылку на обмен, бронирование и маршрут назначает сервис, а не клиент
+	p.HoldExpiresAt = nil
+	p.ExchangedFor = nil
+	p.BookingReference = nil
+	p.ItineraryUID = nil
+	if p.Status == models.StatusHeld {
+		expiresAt := time.Now().Add(pUC.holdTTL)
+		p.HoldExpiresAt = &expiresAt
+	}
+
 	err := pUC.ticketRepository.Create(p)
 
 	if err != nil {
@@ -45,7 +106,16 @@ func (pUC *ticketUseCase) Get(id int) (*models.Ticket, error) {
 	return resTicket, nil
 }
 
+// Update меняет данные билета, но не статус: статус меняется только
+// переходами, см. ChangeStatus.
 func (pUC *ticketUseCase) Update(p *models.Ticket) error {
+	if p.Status != "" {
+		return errors.Wrap(ErrIllegalTransition, "ticketUseCase.Update error: status is changed by transitions only")
+	}
+	p.ExchangedFor = nil
+	p.BookingReference = nil
+	p.ItineraryUID = nil
+
 	//_, err := pUC.ticketRepository.Get(p.ID)
 	//
 	//if err != nil {
@@ -106,5 +176,232 @@ func (pUC *ticketUseCase) GetByUID(ticketUid string, userName string) (*models.T
 		}
 	}
 
-	return nil, errors.New("ticketUseCase.GetByUID ticket not found")
+	return nil, errors.Wrap(ErrTicketNotFound, "ticketUseCase.GetByUID error")
+}
+
+// Confirm оплачивает бронь. Если бронь не подтвердилась, по текущему
+// состоянию билета объясняет почему.
+func (pUC *ticketUseCase) Confirm(ticketUid string, userName string) (*models.Ticket, error) {
+	confirmed, err := pUC.ticketRepository.ConfirmHold(ticketUid, userName, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.Confirm error")
+	}
+
+	ticket, err := pUC.GetByUID(ticketUid, userName)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.Confirm error")
+	}
+
+	switch {
+	case confirmed:
+		return ticket, nil
+	case ticket.Status == models.StatusHeld || ticket.Status == models.StatusExpired:
+		return nil, errors.Wrap(ErrHoldExpired, "ticketUseCase.Confirm error")
+	default:
+		return nil, errors.Wrapf(ErrNotHeld, "ticketUseCase.Confirm error: ticket is %s", ticket.Status)
+	}
+}
+
+func (pUC *ticketUseCase) GetExpiredHolds(limit int) ([]*models.Ticket, error) {
+	tickets, err := pUC.ticketRepository.GetExpiredHolds(time.Now(), limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.GetExpiredHolds error")
+	}
+
+	return tickets, nil
+}
+
+func (pUC *ticketUseCase) ExpireHold(ticketUid string) (bool, error) {
+	expired, err := pUC.ticketRepository.ExpireHold(ticketUid, sweeperActor, time.Now())
+	if err != nil {
+		return false, errors.Wrap(err, "ticketUseCase.ExpireHold error")
+	}
+
+	return expired, nil
+}
+
+// ChangeStatus переводит билет пользователя в status, если это разрешено
+// переходами. Оплата брони идёт только через Confirm, истечение — только по
+// сроку брони.
+func (pUC *ticketUseCase) ChangeStatus(ticketUid string, userName string, status string) (*models.Ticket, error) {
+	ticket, err := pUC.GetByUID(ticketUid, userName)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.ChangeStatus error")
+	}
+
+	if !canTransition(ticket.Status, status) {
+		return nil, errors.Wrapf(ErrIllegalTransition, "ticketUseCase.ChangeStatus error: %s -> %s", ticket.Status, status)
+	}
+	if ticket.Status == models.StatusHeld && (status == models.StatusPaid || status == models.StatusExpired) {
+		return nil, errors.Wrapf(ErrIllegalTransition, "ticketUseCase.ChangeStatus error: hold can't be moved to %s directly", status)
+	}
+	if status == models.StatusExchanged {
+		return nil, errors.Wrap(ErrIllegalTransition, "ticketUseCase.ChangeStatus error: ticket is exchanged only for another ticket")
+	}
+
+	return pUC.applyStatus(ticket, status, userName)
+}
+
+// RevertStatus откатывает последний переход билета, если он вёл из status в
+// текущий статус. Нужен для компенсации, когда операция, ради которой
+// менялся статус, не удалась.
+func (pUC *ticketUseCase) RevertStatus(ticketUid string, userName string, status string) (*models.Ticket, error) {
+	ticket, err := pUC.GetByUID(ticketUid, userName)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.RevertStatus error")
+	}
+
+	if ticket.Status == models.StatusExchanged {
+		return nil, errors.Wrap(ErrIllegalTransition, "ticketUseCase.RevertStatus error: exchange can't be reverted")
+	}
+
+	history, err := pUC.ticketRepository.GetStatusHistory(ticketUid)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.RevertStatus error")
+	}
+
+	if len(history) == 0 {
+		return nil, errors.Wrap(ErrIllegalTransition, "ticketUseCase.RevertStatus error: ticket has no transitions")
+	}
+	last := history[len(history)-1]
+	if last.ToStatus != ticket.Status || last.FromStatus == nil || *last.FromStatus != status {
+		return nil, errors.Wrapf(ErrIllegalTransition, "ticketUseCase.RevertStatus error: last transition is not %s -> %s", status, ticket.Status)
+	}
+
+	return pUC.applyStatus(ticket, status, userName)
+}
+
+// Exchange помечает оплаченный билет обменянным на newTicketUid. Новый билет
+// должен быть уже оплачен и принадлежать тому же пользователю.
+func (pUC *ticketUseCase) Exchange(ticketUid string, userName string, newTicketUid string) (*models.Ticket, error) {
+	if newTicketUid == ticketUid {
+		return nil, errors.Wrap(ErrInvalidExchange, "ticketUseCase.Exchange error: ticket can't be exchanged for itself")
+	}
+
+	ticket, err := pUC.GetByUID(ticketUid, userName)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.Exchange error")
+	}
+
+	if !canTransition(ticket.Status, models.StatusExchanged) {
+		return nil, errors.Wrapf(ErrIllegalTransition, "ticketUseCase.Exchange error: %s -> %s", ticket.Status, models.StatusExchanged)
+	}
+
+	newTicket, err := pUC.GetByUID(newTicketUid, userName)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidExchange, "ticketUseCase.Exchange error: new ticket not found")
+	}
+	if newTicket.Status != models.StatusPaid {
+		return nil, errors.Wrapf(ErrInvalidExchange, "ticketUseCase.Exchange error: new ticket is %s", newTicket.Status)
+	}
+
+	exchanged, err := pUC.ticketRepository.MarkExchanged(ticketUid, newTicketUid, userName, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.Exchange error")
+	}
+	if !exchanged {
+		return nil, errors.Wrap(ErrIllegalTransition, "ticketUseCase.Exchange error: ticket status has changed concurrently")
+	}
+
+	ticket.Status = models.StatusExchanged
+	ticket.ExchangedFor = &newTicketUid
+	return ticket, nil
+}
+
+// applyStatus сохраняет переход; если билет успели перевести в другой статус,
+// переход отклоняется.
+func (pUC *ticketUseCase) applyStatus(ticket *models.Ticket, status string, actor string) (*models.Ticket, error) {
+	changed, err := pUC.ticketRepository.ChangeStatus(ticket.TicketUID, ticket.Status, status, actor, time.Now())
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.applyStatus error")
+	}
+	if !changed {
+		return nil, errors.Wrap(ErrIllegalTransition, "ticketUseCase.applyStatus error: ticket status has changed concurrently")
+	}
+
+	ticket.Status = status
+	return ticket, nil
+}
+
+// CancelAll отменяет билеты одной транзакцией: если хоть один билет нельзя
+// отменить или его статус успел измениться, не отменяется ни один.
+func (pUC *ticketUseCase) CancelAll(tickets []*models.Ticket, actor string) error {
+	if len(tickets) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	changes := make([]models.TicketStatusChange, len(tickets))
+	for i, ticket := range tickets {
+		if !canTransition(ticket.Status, models.StatusCanceled) {
+			return errors.Wrapf(ErrIllegalTransition, "ticketUseCase.CancelAll error: ticket %s is %s", ticket.TicketUID, ticket.Status)
+		}
+
+		from := ticket.Status
+		changes[i] = models.TicketStatusChange{
+			TicketUID:  ticket.TicketUID,
+			FromStatus: &from,
+			ToStatus:   models.StatusCanceled,
+			Actor:      actor,
+			ChangedAt:  now,
+		}
+	}
+
+	changed, err := pUC.ticketRepository.ChangeStatuses(changes)
+	if err != nil {
+		return errors.Wrap(err, "ticketUseCase.CancelAll error")
+	}
+	if !changed {
+		return errors.Wrap(ErrIllegalTransition, "ticketUseCase.CancelAll error: ticket status has changed concurrently")
+	}
+
+	for _, ticket := range tickets {
+		ticket.Status = models.StatusCanceled
+	}
+
+	return nil
+}
+
+func (pUC *ticketUseCase) GetHistory(ticketUid string, userName string) ([]*models.TicketStatusChange, error) {
+	_, err := pUC.GetByUID(ticketUid, userName)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.GetHistory error")
+	}
+
+	history, err := pUC.ticketRepository.GetStatusHistory(ticketUid)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.GetHistory error")
+	}
+
+	return history, nil
+}
+
+// GetByFlight возвращает все билеты рейса любых пользователей.
+func (pUC *ticketUseCase) GetByFlight(flightNumber string) ([]*models.Ticket, error) {
+	tickets, err := pUC.ticketRepository.GetAllByFlightNumber(flightNumber)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.GetByFlight error")
+	}
+
+	return tickets, nil
+}
+
+// CancelForFlight отменяет билет пользователя, потому что отменён рейс. В
+// отличие от ChangeStatus отменяется и билет после регистрации, а уже
+// отменённый билет возвращается как есть, чтобы отмену рейса можно было
+// повторить. actor — администратор, отменивший рейс.
+func (pUC *ticketUseCase) CancelForFlight(ticketUid string, userName string, actor string) (*models.Ticket, error) {
+	ticket, err := pUC.GetByUID(ticketUid, userName)
+	if err != nil {
+		return nil, errors.Wrap(err, "ticketUseCase.CancelForFlight error")
+	}
+
+	switch ticket.Status {
+	case models.StatusCanceled:
+		return ticket, nil
+	case models.StatusHeld, models.StatusPaid, models.StatusCheckedIn:
+		return pUC.applyStatus(ticket, models.StatusCanceled, actor)
+	default:
+		return nil, errors.Wrapf(ErrIllegalTransition, "ticketUseCase.CancelForFlight error: ticket is %s", ticket.Status)
+	}
 }
@@ -8,7 +8,9 @@ import (
 	"github.com/ozontech/allure-go/pkg/framework/provider"
 	"github.com/ozontech/allure-go/pkg/framework/suite"
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
 	"testing"
+	"time"
 )
 
 type TicketTestSuite struct {
@@ -24,7 +26,7 @@ func TestTicketTestSuite(t *testing.T) {
 
 func (s *TicketTestSuite) BeforeEach(t provider.T) {
 	s.ticketRepoMock = ticketMocks.NewTicketRepositoryI(t)
-	s.uc = New(s.ticketRepoMock)
+	s.uc = New(s.ticketRepoMock, time.Minute)
 	s.ticketBuilder = testBuilders.NewTicketBuilder()
 }
 
@@ -53,10 +55,10 @@ func (s *TicketTestSuite) TestUpdateTicket(t provider.T) {
 		WithUsername("username").
 		WithFlightNumber("flightNumber").
 		WithPrice(20).
-		WithStatus("status").
 		Build()
 
 	notFoundTicket := s.ticketBuilder.WithID(0).Build()
+	statusTicket := s.ticketBuilder.WithID(2).WithStatus(models.StatusCanceled).Build()
 
 	s.ticketRepoMock.On("Get", ticket.ID).Return(&ticket, nil)
 	s.ticketRepoMock.On("Update", &ticket).Return(nil)
@@ -75,6 +77,10 @@ func (s *TicketTestSuite) TestUpdateTicket(t provider.T) {
 			ArgData: &notFoundTicket,
 			Error:   errors.Wrap(err, "Ticket not found"),
 		},
+		"status change": {
+			ArgData: &statusTicket,
+			Error:   ErrIllegalTransition,
+		},
 	}
 
 	for name, test := range cases {
@@ -191,3 +197,208 @@ func (s *TicketTestSuite) TestGetAll(t provider.T) {
 		})
 	}
 }
+
+func (s *TicketTestSuite) TestCreateHeldTicket(t provider.T) {
+	ticket := s.ticketBuilder.WithUID("uid").
+		WithUsername("username").
+		WithFlightNumber("flightNumber").
+		WithPrice(20).
+		WithStatus(models.StatusHeld).
+		Build()
+
+	s.ticketRepoMock.On("Create", &ticket).Return(nil)
+	err := s.uc.Create(&ticket)
+
+	t.Assert().NoError(err)
+	t.Require().NotNil(ticket.HoldExpiresAt)
+	t.Assert().WithinDuration(time.Now().Add(time.Minute), *ticket.HoldExpiresAt, time.Second)
+}
+
+func (s *TicketTestSuite) TestConfirm(t provider.T) {
+	held := s.ticketBuilder.WithUID("held").WithUsername("username").WithStatus(models.StatusHeld).Build()
+	expired := s.ticketBuilder.WithUID("expired").WithUsername("username").WithStatus(models.StatusExpired).Build()
+	paid := s.ticketBuilder.WithUID("paid").WithUsername("username").WithStatus(models.StatusPaid).Build()
+
+	s.ticketRepoMock.On("ConfirmHold", "paid-now", "username", mock.Anything).Return(true, nil)
+	s.ticketRepoMock.On("ConfirmHold", mock.Anything, "username", mock.Anything).Return(false, nil)
+	s.ticketRepoMock.On("GetAllByUserName", "username").Return([]*models.Ticket{
+		{TicketUID: "paid-now", Username: "username", Status: models.StatusPaid},
+		&held, &expired, &paid,
+	}, nil)
+
+	cases := map[string]struct {
+		TicketUID string
+		Error     error
+	}{
+		"success":        {TicketUID: "paid-now", Error: nil},
+		"hold timed out": {TicketUID: "held", Error: ErrHoldExpired},
+		"hold expired":   {TicketUID: "expired", Error: ErrHoldExpired},
+		"already paid":   {TicketUID: "paid", Error: ErrNotHeld},
+		"not found":      {TicketUID: "unknown", Error: ErrTicketNotFound},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			_, err := s.uc.Confirm(test.TicketUID, "username")
+			t.Assert().ErrorIs(err, test.Error)
+		})
+	}
+}
+
+func (s *TicketTestSuite) TestChangeStatus(t provider.T) {
+	held := s.ticketBuilder.WithUID("held").WithUsername("username").WithStatus(models.StatusHeld).Build()
+	paid := s.ticketBuilder.WithUID("paid").WithUsername("username").WithStatus(models.StatusPaid).Build()
+	canceled := s.ticketBuilder.WithUID("canceled").WithUsername("username").WithStatus(models.StatusCanceled).Build()
+	raced := s.ticketBuilder.WithUID("raced").WithUsername("username").WithStatus(models.StatusPaid).Build()
+
+	s.ticketRepoMock.On("GetAllByUserName", "username").Return([]*models.Ticket{&held, &paid, &canceled, &raced}, nil)
+	s.ticketRepoMock.On("ChangeStatus", "raced", models.StatusPaid, mock.Anything, "username", mock.Anything).Return(false, nil)
+	s.ticketRepoMock.On("ChangeStatus", mock.Anything, mock.Anything, mock.Anything, "username", mock.Anything).Return(true, nil)
+
+	cases := map[string]struct {
+		TicketUID string
+		Status    string
+		Error     error
+	}{
+		"cancel hold":      {TicketUID: "held", Status: models.StatusCanceled, Error: nil},
+		"check in":         {TicketUID: "paid", Status: models.StatusCheckedIn, Error: nil},
+		"pay without hold": {TicketUID: "held", Status: models.StatusPaid, Error: ErrIllegalTransition},
+		"expire by hand":   {TicketUID: "held", Status: models.StatusExpired, Error: ErrIllegalTransition},
+		"cancel twice":     {TicketUID: "canceled", Status: models.StatusCanceled, Error: ErrIllegalTransition},
+		"restore canceled": {TicketUID: "canceled", Status: models.StatusPaid, Error: ErrIllegalTransition},
+		"concurrent":       {TicketUID: "raced", Status: models.StatusCanceled, Error: ErrIllegalTransition},
+		"not found":        {TicketUID: "unknown", Status: models.StatusCanceled, Error: ErrTicketNotFound},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			ticket, err := s.uc.ChangeStatus(test.TicketUID, "username", test.Status)
+			t.Assert().ErrorIs(err, test.Error)
+			if test.Error == nil {
+				t.Assert().Equal(test.Status, ticket.Status)
+			}
+		})
+	}
+}
+
+func (s *TicketTestSuite) TestRevertStatus(t provider.T) {
+	paid := models.StatusPaid
+	canceled := s.ticketBuilder.WithUID("uid").WithUsername("username").WithStatus(models.StatusCanceled).Build()
+
+	s.ticketRepoMock.On("GetAllByUserName", "username").Return([]*models.Ticket{&canceled}, nil)
+	s.ticketRepoMock.On("GetStatusHistory", "uid").Return([]*models.TicketStatusChange{
+		{TicketUID: "uid", ToStatus: models.StatusPaid},
+		{TicketUID: "uid", FromStatus: &paid, ToStatus: models.StatusCanceled},
+	}, nil)
+	s.ticketRepoMock.On("ChangeStatus", "uid", models.StatusCanceled, models.StatusPaid, "username", mock.Anything).Return(true, nil)
+
+	_, err := s.uc.RevertStatus("uid", "username", models.StatusHeld)
+	t.Assert().ErrorIs(err, ErrIllegalTransition)
+
+	ticket, err := s.uc.RevertStatus("uid", "username", models.StatusPaid)
+	t.Assert().NoError(err)
+	t.Assert().Equal(models.StatusPaid, ticket.Status)
+}
+
+func (s *TicketTestSuite) TestExchange(t provider.T) {
+	paid := s.ticketBuilder.WithUID("paid").WithUsername("username").WithStatus(models.StatusPaid).Build()
+	canceled := s.ticketBuilder.WithUID("canceled").WithUsername("username").WithStatus(models.StatusCanceled).Build()
+	held := s.ticketBuilder.WithUID("held").WithUsername("username").WithStatus(models.StatusHeld).Build()
+	newTicket := s.ticketBuilder.WithUID("new").WithUsername("username").WithStatus(models.StatusPaid).Build()
+
+	s.ticketRepoMock.On("GetAllByUserName", "username").Return([]*models.Ticket{&paid, &canceled, &held, &newTicket}, nil)
+	s.ticketRepoMock.On("MarkExchanged", "paid", "new", "username", mock.Anything).Return(true, nil)
+
+	cases := map[string]struct {
+		TicketUID    string
+		NewTicketUID string
+		Error        error
+	}{
+		"not paid":           {TicketUID: "canceled", NewTicketUID: "new", Error: ErrIllegalTransition},
+		"for itself":         {TicketUID: "paid", NewTicketUID: "paid", Error: ErrInvalidExchange},
+		"new not found":      {TicketUID: "paid", NewTicketUID: "unknown", Error: ErrInvalidExchange},
+		"new ticket is held": {TicketUID: "paid", NewTicketUID: "held", Error: ErrInvalidExchange},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			_, err := s.uc.Exchange(test.TicketUID, "username", test.NewTicketUID)
+			t.Assert().ErrorIs(err, test.Error)
+		})
+	}
+
+	ticket, err := s.uc.Exchange("paid", "username", "new")
+	t.Assert().NoError(err)
+	t.Assert().Equal(models.StatusExchanged, ticket.Status)
+	t.Require().NotNil(ticket.ExchangedFor)
+	t.Assert().Equal("new", *ticket.ExchangedFor)
+}
+
+func (s *TicketTestSuite) TestCancelForFlight(t provider.T) {
+	checkedIn := s.ticketBuilder.WithUID("checked-in").WithUsername("username").WithStatus(models.StatusCheckedIn).Build()
+	canceled := s.ticketBuilder.WithUID("canceled").WithUsername("username").WithStatus(models.StatusCanceled).Build()
+	exchanged := s.ticketBuilder.WithUID("exchanged").WithUsername("username").WithStatus(models.StatusExchanged).Build()
+
+	s.ticketRepoMock.On("GetAllByUserName", "username").Return([]*models.Ticket{&checkedIn, &canceled, &exchanged}, nil)
+	s.ticketRepoMock.On("ChangeStatus", "checked-in", models.StatusCheckedIn, models.StatusCanceled, "admin", mock.Anything).Return(true, nil)
+
+	cases := map[string]struct {
+		TicketUID string
+		Error     error
+	}{
+		"cancel after check-in": {TicketUID: "checked-in", Error: nil},
+		"repeat cancellation":   {TicketUID: "canceled", Error: nil},
+		"exchanged":             {TicketUID: "exchanged", Error: ErrIllegalTransition},
+		"not found":             {TicketUID: "unknown", Error: ErrTicketNotFound},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			ticket, err := s.uc.CancelForFlight(test.TicketUID, "username", "admin")
+			t.Assert().ErrorIs(err, test.Error)
+			if test.Error == nil {
+				t.Assert().Equal(models.StatusCanceled, ticket.Status)
+			}
+		})
+	}
+
+	s.ticketRepoMock.AssertNumberOfCalls(t, "ChangeStatus", 1)
+}
+
+func (s *TicketTestSuite) TestCancelAll(t provider.T) {
+	held := s.ticketBuilder.WithUID("held").WithStatus(models.StatusHeld).Build()
+	paid := s.ticketBuilder.WithUID("paid").WithStatus(models.StatusPaid).Build()
+
+	s.ticketRepoMock.On("ChangeStatuses", mock.MatchedBy(func(changes []models.TicketStatusChange) bool {
+		return len(changes) == 2 &&
+			changes[0].TicketUID == "held" && *changes[0].FromStatus == models.StatusHeld &&
+			changes[1].TicketUID == "paid" && *changes[1].FromStatus == models.StatusPaid &&
+			changes[1].ToStatus == models.StatusCanceled && changes[1].Actor == "username"
+	})).Return(true, nil)
+
+	err := s.uc.CancelAll([]*models.Ticket{&held, &paid}, "username")
+	t.Assert().NoError(err)
+	t.Assert().Equal(models.StatusCanceled, held.Status)
+	t.Assert().Equal(models.StatusCanceled, paid.Status)
+}
+
+func (s *TicketTestSuite) TestCancelAllChangedConcurrently(t provider.T) {
+	first := s.ticketBuilder.WithUID("first").WithStatus(models.StatusPaid).Build()
+	second := s.ticketBuilder.WithUID("second").WithStatus(models.StatusPaid).Build()
+
+	s.ticketRepoMock.On("ChangeStatuses", mock.Anything).Return(false, nil)
+
+	err := s.uc.CancelAll([]*models.Ticket{&first, &second}, "username")
+	t.Assert().ErrorIs(err, ErrIllegalTransition)
+	t.Assert().Equal(models.StatusPaid, first.Status)
+	t.Assert().Equal(models.StatusPaid, second.Status)
+}
+
+func (s *TicketTestSuite) TestCancelAllIllegal(t provider.T) {
+	paid := s.ticketBuilder.WithUID("paid").WithStatus(models.StatusPaid).Build()
+	checkedIn := s.ticketBuilder.WithUID("checked-in").WithStatus(models.StatusCheckedIn).Build()
+
+	err := s.uc.CancelAll([]*models.Ticket{&paid, &checkedIn}, "username")
+	t.Assert().ErrorIs(err, ErrIllegalTransition)
+	s.ticketRepoMock.AssertNotCalled(t, "ChangeStatuses", mock.Anything)
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+func (Booking) TableName() string {
+	return "booking"
+}
+
+// Booking — бронирование (PNR): несколько билетов на разных пассажиров под
+// одним шестисимвольным кодом.
+type Booking struct {
+	ID        int       `json:"id" db:"id"`
+	Reference string    `json:"reference" db:"reference"`
+	Username  string    `json:"username" db:"username"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	Tickets   []Ticket  `json:"tickets" gorm:"foreignKey:BookingReference;references:Reference"`
+}
+
+// Passenger — данные пассажира билета. У билетов, купленных не в
+// бронировании, пусты.
+type Passenger struct {
+	FirstName      string     `json:"firstName" db:"passenger_first_name"`
+	LastName       string     `json:"lastName" db:"passenger_last_name"`
+	DocumentNumber string     `json:"documentNumber" db:"passenger_document_number"`
+	BirthDate      *time.Time `json:"birthDate,omitempty" db:"passenger_birth_date"`
+}
+
+// HasLastName сравнивает фамилию без учёта регистра и пробелов по краям.
+func (p Passenger) HasLastName(lastName string) bool {
+	return p.LastName != "" && strings.EqualFold(strings.TrimSpace(p.LastName), strings.TrimSpace(lastName))
+}
+
+type BookingDTO struct {
+	Reference string       `json:"reference"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Tickets   []*TicketDTO `json:"tickets"`
+}
+
+func BookingToDTO(booking Booking) *BookingDTO {
+	tickets := make([]*TicketDTO, len(booking.Tickets))
+	for i, ticket := range booking.Tickets {
+		tickets[i] = TicketToDTO(ticket)
+	}
+
+	return &BookingDTO{
+		Reference: booking.Reference,
+		CreatedAt: booking.CreatedAt,
+		Tickets:   tickets,
+	}
+}
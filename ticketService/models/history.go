@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+func (TicketStatusChange) TableName() string {
+	return "ticket_status_history"
+}
+
+// TicketStatusChange — запись о смене статуса билета. FromStatus пуст у
+// записи о создании билета.
+type TicketStatusChange struct {
+	ID         int       `json:"id" db:"id"`
+	TicketUID  string    `json:"ticketUid" db:"ticket_uid"`
+	FromStatus *string   `json:"fromStatus,omitempty" db:"from_status"`
+	ToStatus   string    `json:"toStatus" db:"to_status"`
+	Actor      string    `json:"actor" db:"actor"`
+	ChangedAt  time.Time `json:"changedAt" db:"changed_at"`
+}
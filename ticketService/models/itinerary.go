@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+func (Itinerary) TableName() string {
+	return "itinerary"
+}
+
+// Itinerary — маршрут из нескольких перелётов одного пользователя, купленный
+// одной покупкой. Билеты перелётов идут в порядке маршрута.
+type Itinerary struct {
+	ID           int       `json:"id" db:"id"`
+	ItineraryUID string    `json:"itineraryUid" db:"itinerary_uid"`
+	Username     string    `json:"username" db:"username"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+	Tickets      []Ticket  `json:"tickets" gorm:"foreignKey:ItineraryUID;references:ItineraryUID"`
+}
+
+type ItineraryDTO struct {
+	ItineraryUID string       `json:"itineraryUid"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	Tickets      []*TicketDTO `json:"tickets"`
+}
+
+func ItineraryToDTO(itinerary Itinerary) *ItineraryDTO {
+	tickets := make([]*TicketDTO, len(itinerary.Tickets))
+	for i, ticket := range itinerary.Tickets {
+		tickets[i] = TicketToDTO(ticket)
+	}
+
+	return &ItineraryDTO{
+		ItineraryUID: itinerary.ItineraryUID,
+		CreatedAt:    itinerary.CreatedAt,
+		Tickets:      tickets,
+	}
+}
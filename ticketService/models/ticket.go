@@ -1,5 +1,28 @@
 package models
 
+import "time"
+
+const RoleAdmin = "ADMIN"
+
+// RoleService — роль identity-токена, которым gateway подписывает собственные
+// операции, например компенсации саг.
+const RoleService = "SERVICE"
+
+// FareEconomy — класс обслуживания билетов, купленных без явного класса.
+const FareEconomy = "ECONOMY"
+
+const (
+	// StatusHeld — место забронировано, но ещё не оплачено.
+	StatusHeld     = "HELD"
+	StatusPaid     = "PAID"
+	StatusCanceled = "CANCELED"
+	// StatusExpired — бронь не оплатили вовремя, место освобождено.
+	StatusExpired   = "EXPIRED"
+	StatusCheckedIn = "CHECKED_IN"
+	// StatusExchanged — билет обменян на другой рейс.
+	StatusExchanged = "EXCHANGED"
+)
+
 type Tabler interface {
 	TableName() string
 }
@@ -9,12 +32,25 @@ func (Ticket) TableName() string {
 }
 
 func TicketToDTO(ticket Ticket) *TicketDTO {
-	return &TicketDTO{
-		TicketUID:    ticket.TicketUID,
-		FlightNumber: ticket.FlightNumber,
-		Price:        ticket.Price,
-		Status:       ticket.Status,
+	dto := &TicketDTO{
+		TicketUID:     ticket.TicketUID,
+		FlightNumber:  ticket.FlightNumber,
+		Price:         ticket.Price,
+		Status:        ticket.Status,
+		Seat:          ticket.Seat,
+		FareClass:     ticket.FareClass,
+		HoldExpiresAt: ticket.HoldExpiresAt,
+		ExchangedFor:  ticket.ExchangedFor,
+		ItineraryUID:  ticket.ItineraryUID,
 	}
+
+	if ticket.BookingReference != nil {
+		dto.BookingReference = *ticket.BookingReference
+		passenger := ticket.Passenger
+		dto.Passenger = &passenger
+	}
+
+	return dto
 }
 
 type Ticket struct {
@@ -24,11 +60,39 @@ type Ticket struct {
 	FlightNumber string `json:"flightNumber" db:"flight_number"`
 	Price        int    `json:"price" db:"price"`
 	Status       string `json:"status" db:"status"`
+	// Seat — выбранное кресло, пусто, пока пассажир его не выбрал
+	Seat string `json:"seat" db:"seat"`
+	// FareClass — класс обслуживания, по которому продан билет
+	FareClass string `json:"fareClass" db:"fare_class"`
+	// HoldExpiresAt — срок оплаты брони, задаётся только для HELD
+	HoldExpiresAt *time.Time `json:"holdExpiresAt,omitempty" db:"hold_expires_at"`
+	// ExchangedFor — UID билета, на который обменян этот; задаётся только для EXCHANGED
+	ExchangedFor *string `json:"exchangedFor,omitempty" db:"exchanged_for"`
+	// BookingReference — код бронирования, если билет куплен в нём
+	BookingReference *string   `json:"bookingReference,omitempty" db:"booking_reference"`
+	Passenger        Passenger `json:"passenger" gorm:"embedded;embeddedPrefix:passenger_"`
+	// ItineraryUID — маршрут, в котором билет куплен как один из перелётов
+	ItineraryUID *string `json:"itineraryUid,omitempty" db:"itinerary_uid"`
+}
+
+// FlightTicketDTO — билет рейса вместе с владельцем; отдаётся только
+// администратору.
+type FlightTicketDTO struct {
+	Username string `json:"username"`
+	*TicketDTO
 }
 
 type TicketDTO struct {
-	TicketUID    string `json:"ticketUid"`
-	FlightNumber string `json:"flightNumber"`
-	Price        int    `json:"price"`
-	Status       string `json:"status"`
+	TicketUID     string     `json:"ticketUid"`
+	FlightNumber  string     `json:"flightNumber"`
+	Price         int        `json:"price"`
+	Status        string     `json:"status"`
+	Seat          string     `json:"seat,omitempty"`
+	FareClass     string     `json:"fareClass"`
+	HoldExpiresAt *time.Time `json:"holdExpiresAt,omitempty"`
+	ExchangedFor  *string    `json:"exchangedFor,omitempty"`
+	// BookingReference и Passenger есть только у билетов из бронирования
+	BookingReference string     `json:"bookingReference,omitempty"`
+	Passenger        *Passenger `json:"passenger,omitempty"`
+	ItineraryUID     *string    `json:"itineraryUid,omitempty"`
 }
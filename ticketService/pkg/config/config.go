@@ -0,0 +1,73 @@
+package config
+
+import "time"
+
+type ServerConfig struct {
+	Addr              string        `yaml:"addr" env:"SERVER_ADDR" required:"true"`
+	ReadTimeout       time.Duration `yaml:"readTimeout" env:"SERVER_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"writeTimeout" env:"SERVER_WRITE_TIMEOUT"`
+	// ShutdownTimeout ограничивает ожидание текущих запросов при остановке.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay — сколько сервер отвечает not ready перед остановкой.
+	ShutdownDelay time.Duration `yaml:"shutdownDelay" env:"SERVER_SHUTDOWN_DELAY"`
+}
+
+type PostgresConfig struct {
+	DSN string `yaml:"dsn" env:"POSTGRES_DSN" required:"true"`
+}
+
+type SessionConfig struct {
+	TokenKey string `yaml:"tokenKey" env:"TOKEN_KEY" required:"true"`
+	// RequireIdentity отклоняет запросы к API без identity-токена gateway.
+	RequireIdentity bool `yaml:"requireIdentity" env:"REQUIRE_IDENTITY"`
+}
+
+type ServicesConfig struct {
+	FlightHost string `yaml:"flightHost" env:"FLIGHT_HOST" required:"true"`
+}
+
+type HoldConfig struct {
+	// TTL — сколько бронь ждёт оплаты.
+	TTL time.Duration `yaml:"ttl" env:"HOLD_TTL"`
+	// SweepInterval — как часто снимаются просроченные брони.
+	SweepInterval time.Duration `yaml:"sweepInterval" env:"HOLD_SWEEP_INTERVAL"`
+	BatchSize     int           `yaml:"batchSize" env:"HOLD_BATCH_SIZE"`
+}
+
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Postgres PostgresConfig `yaml:"postgres"`
+	Session  SessionConfig  `yaml:"session"`
+	Services ServicesConfig `yaml:"services"`
+	Hold     HoldConfig     `yaml:"hold"`
+}
+
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:              ":8070",
+			ReadTimeout:       10 * time.Second,
+			ReadHeaderTimeout: 10 * time.Second,
+			WriteTimeout:      10 * time.Second,
+			ShutdownTimeout:   15 * time.Second,
+		},
+		Hold: HoldConfig{
+			TTL:           15 * time.Minute,
+			SweepInterval: 30 * time.Second,
+			BatchSize:     100,
+		},
+	}
+}
+
+// Load читает конфигурацию сервиса. Незаданные в файле поля берутся из Default.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	err := load(path, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,62 @@
+package flightclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+// identityHeader — токен, по которому Flight Service пускает к резервированиям.
+const identityHeader = "X-User-Identity"
+
+// IdentitySource выпускает токен, которым Ticket Service подписывает запросы.
+type IdentitySource func() (string, error)
+
+// Client — клиент Flight Service для фоновых задач Ticket Service.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	identity   IdentitySource
+}
+
+func New(baseURL string, httpClient *http.Client, identity IdentitySource) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+		identity:   identity,
+	}
+}
+
+// ReleaseSeat освобождает место билета. Повторное освобождение не ошибка.
+func (c *Client) ReleaseSeat(ctx context.Context, ticketUID string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.baseURL+"/api/v1/reservations/"+url.PathEscape(ticketUID), nil)
+	if err != nil {
+		return errors.Wrap(err, "flightclient.ReleaseSeat error")
+	}
+
+	token, err := c.identity()
+	if err != nil {
+		return errors.Wrap(err, "flightclient.ReleaseSeat error: can`t create identity")
+	}
+	req.Header.Set(identityHeader, token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "flightclient.ReleaseSeat error")
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return errors.Errorf("flightclient.ReleaseSeat error: status %d: %s", resp.StatusCode, body)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"flight_booking_system/ticketService/models"
+	appContext "flight_booking_system/ticketService/pkg/context"
+	"flight_booking_system/ticketService/pkg/session"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/ozontech/allure-go/pkg/framework/provider"
+	"github.com/ozontech/allure-go/pkg/framework/suite"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testTokenKey = "test-key"
+
+type AuthTestSuite struct {
+	suite.Suite
+	handler http.Handler
+}
+
+func TestAuthSuite(t *testing.T) {
+	suite.RunSuite(t, new(AuthTestSuite))
+}
+
+func (s *AuthTestSuite) BeforeEach(t provider.T) {
+	authManager := &AuthManager{
+		SessionManager: session.New(testTokenKey),
+		Logger:         zap.NewNop().Sugar(),
+		ContextManager: appContext.Manager{},
+	}
+
+	// Так закрыт откат статуса билета
+	s.handler = authManager.Auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), models.RoleService)
+}
+
+// identity подписывает identity-токен, как это делает gateway.
+func identity(t provider.T, role string) string {
+	claims := session.Claims{
+		User:      session.UserClaims{Username: "username", Role: role},
+		TokenType: session.TokenTypeIdentity,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testTokenKey))
+	t.Require().NoError(err)
+
+	return token
+}
+
+func (s *AuthTestSuite) TestServiceRole(t provider.T) {
+	cases := map[string]struct {
+		Identity   string
+		StatusCode int
+	}{
+		"service": {
+			Identity:   identity(t, models.RoleService),
+			StatusCode: http.StatusOK,
+		},
+		"user": {
+			Identity:   identity(t, "USER"),
+			StatusCode: http.StatusForbidden,
+		},
+		"admin": {
+			Identity:   identity(t, models.RoleAdmin),
+			StatusCode: http.StatusForbidden,
+		},
+		"no identity": {
+			StatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for name, test := range cases {
+		t.Run(name, func(t provider.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/tickets/uid/revert", nil)
+			if test.Identity != "" {
+				req.Header.Set(sessionHeader, test.Identity)
+			}
+			rec := httptest.NewRecorder()
+
+			s.handler.ServeHTTP(rec, req)
+
+			t.Assert().Equal(test.StatusCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"flight_booking_system/ticketService/pkg/logger"
+)
+
+const (
+	identityHeader = "X-User-Identity"
+	userNameHeader = "X-User-Name"
+)
+
+type IdentitySessionsManager interface {
+	GetIdentity(string) (string, error)
+}
+
+// Identity подставляет в X-User-Name имя пользователя из identity-токена,
+// подписанного gateway. Если required, запросы к /api/ без токена отклоняются.
+func Identity(logger logger.Logger, sessions IdentitySessionsManager, required bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(identityHeader)
+		if token == "" {
+			if required && strings.HasPrefix(r.URL.Path, "/api/") {
+				logger.Infow("identity",
+					"url", r.URL.Path,
+					"method", r.Method,
+					"remote_addr", r.RemoteAddr,
+					"result", "identity header not found")
+				http.Error(w, "no identity", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userName, err := sessions.GetIdentity(token)
+		if err != nil {
+			logger.Infow("identity",
+				"url", r.URL.Path,
+				"method", r.Method,
+				"remote_addr", r.RemoteAddr,
+				"result", "invalid identity token",
+				"err:", err.Error())
+			http.Error(w, "invalid identity", http.StatusUnauthorized)
+			return
+		}
+
+		r.Header.Set(userNameHeader, userName)
+		next.ServeHTTP(w, r)
+	})
+}